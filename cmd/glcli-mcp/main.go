@@ -2,15 +2,15 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
 
-	"github.com/bearlogin/gitlab-awesome-cli/internal/application/service"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/bootstrap"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/infrastructure/config"
-	gitlabinfra "github.com/bearlogin/gitlab-awesome-cli/internal/infrastructure/gitlab"
 	mcpserver "github.com/bearlogin/gitlab-awesome-cli/internal/presentation/mcp"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -38,37 +38,31 @@ func setupLog() *os.File {
 }
 
 func main() {
+	var contextName string
+	flag.StringVar(&contextName, "context", "", "default config context for tool calls (default: current_context)")
+	flag.Parse()
+
 	logFile := setupLog()
 	if logFile != nil {
 		defer logFile.Close()
 	}
 	log.Printf("glcli-mcp %s starting", version)
 
-	cfg, err := config.Load(config.DefaultPath())
+	cfg, err := config.Load(config.DefaultPath(), contextName)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "glcli-mcp: failed to load config: %v\n", err)
 		os.Exit(1)
 	}
 	log.Printf("config loaded: url=%s projects=%v", cfg.GitLabURL, cfg.Projects)
 
-	client, err := gitlabinfra.NewClient(cfg.GitLabURL, cfg.Token)
+	env, err := bootstrap.New(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "glcli-mcp: gitlab client error: %v\n", err)
 		os.Exit(1)
 	}
-	log.Print("gitlab client created")
-
-	projectRepo := gitlabinfra.NewProjectRepo(client)
-	pipelineRepo := gitlabinfra.NewPipelineRepo(client)
-	jobRepo := gitlabinfra.NewJobRepo(client)
-	mrRepo := gitlabinfra.NewMergeRequestRepo(client)
-	commitRepo := gitlabinfra.NewCommitRepo(client)
-
-	pipelineSvc := service.NewPipelineService(projectRepo, pipelineRepo)
-	jobSvc := service.NewJobService(jobRepo)
-	mrSvc := service.NewMergeRequestService(mrRepo, commitRepo)
+	log.Printf("gitlab client created: context=%q", cfg.ActiveContext())
 
-	server := mcpserver.NewServer(cfg, pipelineSvc, jobSvc, mrSvc, version)
+	server := mcpserver.NewServer(bootstrap.NewPool(cfg, env), version)
 	log.Print("mcp server created, starting stdio transport")
 
 	if err := server.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
@@ -9,13 +11,24 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 
-	"github.com/bearlogin/gitlab-awesome-cli/internal/application/service"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/bootstrap"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/infrastructure/config"
-	gitlabinfra "github.com/bearlogin/gitlab-awesome-cli/internal/infrastructure/gitlab"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/presentation/cli"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/presentation/tui"
 )
 
 func main() {
+	var contextName string
+	flag.StringVar(&contextName, "context", "", "config context to use (default: current_context)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: glcli [--context NAME] [command]\n\nWithout a command glcli starts the interactive UI.\n\nFlags:\n")
+		flag.PrintDefaults()
+		fmt.Fprintln(flag.CommandLine.Output())
+		cli.Usage(flag.CommandLine.Output())
+	}
+	flag.Parse()
+	headless := cli.IsCommand(flag.Args())
+
 	// Log to file if GLCLI_LOG is set, otherwise discard
 	if logPath := os.Getenv("GLCLI_LOG"); logPath != "" {
 		_ = os.MkdirAll(filepath.Dir(logPath), 0755)
@@ -29,7 +42,15 @@ func main() {
 	}
 
 	cfgPath := config.DefaultPath()
-	cfg, err := config.Load(cfgPath)
+	cfg, err := config.Load(cfgPath, contextName)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "glcli: %s: %v\n", cfgPath, err)
+		os.Exit(1)
+	}
+	if err != nil && headless {
+		fmt.Fprintf(os.Stderr, "glcli: no config at %s; run glcli without arguments to set it up\n", cfgPath)
+		os.Exit(cli.ExitError)
+	}
 	if err != nil {
 		fmt.Println("No config found. Let's set up glcli!")
 		cfg, err = config.RunSetupWizard()
@@ -39,23 +60,17 @@ func main() {
 		}
 	}
 
-	client, err := gitlabinfra.NewClient(cfg.GitLabURL, cfg.Token)
+	env, err := bootstrap.New(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "GitLab client error: %v\n", err)
 		os.Exit(1)
 	}
 
-	projectRepo := gitlabinfra.NewProjectRepo(client)
-	pipelineRepo := gitlabinfra.NewPipelineRepo(client)
-	jobRepo := gitlabinfra.NewJobRepo(client)
-	mrRepo := gitlabinfra.NewMergeRequestRepo(client)
-	commitRepo := gitlabinfra.NewCommitRepo(client)
-
-	pipelineSvc := service.NewPipelineService(projectRepo, pipelineRepo)
-	jobSvc := service.NewJobService(jobRepo)
-	mrSvc := service.NewMergeRequestService(mrRepo, commitRepo)
+	if headless {
+		os.Exit(cli.Run(env, flag.Args(), os.Stdout, os.Stderr))
+	}
 
-	app := tui.NewApp(cfg, pipelineSvc, jobSvc, mrSvc)
+	app := tui.NewApp(env)
 	p := tea.NewProgram(app, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
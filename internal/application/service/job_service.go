@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"io"
+
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/repository"
 )
@@ -30,3 +31,21 @@ func (s *JobService) CancelJob(ctx context.Context, projectID, jobID int) (*enti
 func (s *JobService) GetJobLog(ctx context.Context, projectID, jobID int) (io.ReadCloser, error) {
 	return s.jobRepo.GetLog(ctx, projectID, jobID)
 }
+
+// TailJobLog returns the log bytes appended since offset together with the
+// current job status, so callers know when to stop polling.
+func (s *JobService) TailJobLog(ctx context.Context, projectID, jobID int, offset int64) (*entity.LogChunk, error) {
+	job, err := s.jobRepo.Get(ctx, projectID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.jobRepo.GetLogFrom(ctx, projectID, jobID, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.LogChunk{
+		Data:      string(data),
+		Offset:    offset + int64(len(data)),
+		JobStatus: job.Status,
+	}, nil
+}
@@ -5,19 +5,47 @@ import (
 
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/repository"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/pkg/fanout"
 )
 
 type MergeRequestService struct {
-	mrRepo     repository.MergeRequestRepository
-	commitRepo repository.CommitRepository
+	mrRepo      repository.MergeRequestRepository
+	commitRepo  repository.CommitRepository
+	concurrency int
 }
 
 func NewMergeRequestService(mr repository.MergeRequestRepository, cr repository.CommitRepository) *MergeRequestService {
-	return &MergeRequestService{mrRepo: mr, commitRepo: cr}
+	return &MergeRequestService{mrRepo: mr, commitRepo: cr, concurrency: fanout.DefaultLimit}
 }
 
-func (s *MergeRequestService) ListMRs(ctx context.Context, projectID int, state string) ([]entity.MergeRequest, error) {
-	return s.mrRepo.List(ctx, projectID, state)
+// SetConcurrency limits how many projects are queried in parallel.
+func (s *MergeRequestService) SetConcurrency(n int) { s.concurrency = n }
+
+// ListMRs lists up to limit merge requests of a project; 0 means all.
+func (s *MergeRequestService) ListMRs(ctx context.Context, projectID int, state string, limit int) ([]entity.MergeRequest, error) {
+	return s.mrRepo.List(ctx, projectID, state, limit)
+}
+
+// ListProjectsMRs lists merge requests of several projects in parallel, up
+// to limit per project. Projects that fail are reported through an
+// *entity.PartialError.
+func (s *MergeRequestService) ListProjectsMRs(ctx context.Context, projects []entity.Project, state string, limit int) ([]entity.MergeRequest, error) {
+	results, errs := fanout.Run(ctx, projects, s.concurrency, func(ctx context.Context, p entity.Project) ([]entity.MergeRequest, error) {
+		return s.mrRepo.List(ctx, p.ID, state, limit)
+	})
+	var all []entity.MergeRequest
+	var failed []entity.ProjectError
+	for i, mrs := range results {
+		if errs[i] != nil {
+			failed = append(failed, entity.ProjectError{Path: projects[i].PathWithNS, Err: errs[i]})
+			continue
+		}
+		for j := range mrs {
+			mrs[j].ProjectPath = projects[i].PathWithNS
+		}
+		all = append(all, mrs...)
+	}
+	return all, entity.NewProjectLoadError(failed, len(projects))
 }
 
 func (s *MergeRequestService) GetMR(ctx context.Context, projectID, mrIID int) (*entity.MergeRequest, error) {
@@ -28,6 +56,28 @@ func (s *MergeRequestService) ListNotes(ctx context.Context, projectID, mrIID in
 	return s.mrRepo.ListNotes(ctx, projectID, mrIID)
 }
 
+func (s *MergeRequestService) ListDiscussions(ctx context.Context, projectID, mrIID int) ([]entity.Discussion, error) {
+	return s.mrRepo.ListDiscussions(ctx, projectID, mrIID)
+}
+
+// ResolveDiscussion resolves a thread, or reopens it when resolved is false.
+func (s *MergeRequestService) ResolveDiscussion(ctx context.Context, projectID, mrIID int, discussionID string, resolved bool) (*entity.Discussion, error) {
+	return s.mrRepo.ResolveDiscussion(ctx, projectID, mrIID, discussionID, resolved)
+}
+
+func (s *MergeRequestService) AddNote(ctx context.Context, projectID, mrIID int, body string) (*entity.MRNote, error) {
+	return s.mrRepo.AddNote(ctx, projectID, mrIID, body)
+}
+
+// AddDiffNote starts a thread on one line of the diff.
+func (s *MergeRequestService) AddDiffNote(ctx context.Context, projectID, mrIID int, body string, pos entity.DiffPosition) (*entity.MRNote, error) {
+	return s.mrRepo.AddDiffNote(ctx, projectID, mrIID, body, pos)
+}
+
+func (s *MergeRequestService) ReplyToDiscussion(ctx context.Context, projectID, mrIID int, discussionID, body string) (*entity.MRNote, error) {
+	return s.mrRepo.ReplyToDiscussion(ctx, projectID, mrIID, discussionID, body)
+}
+
 func (s *MergeRequestService) GetDiffs(ctx context.Context, projectID, mrIID int) ([]entity.MRDiff, error) {
 	return s.mrRepo.GetDiffs(ctx, projectID, mrIID)
 }
@@ -40,10 +90,10 @@ func (s *MergeRequestService) ApproveMR(ctx context.Context, projectID, mrIID in
 	return s.mrRepo.Approve(ctx, projectID, mrIID)
 }
 
-func (s *MergeRequestService) MergeMR(ctx context.Context, projectID, mrIID int) (*entity.MergeRequest, error) {
-	return s.mrRepo.Merge(ctx, projectID, mrIID)
+func (s *MergeRequestService) MergeMR(ctx context.Context, projectID, mrIID int, opts entity.MergeOptions) (*entity.MergeRequest, error) {
+	return s.mrRepo.Merge(ctx, projectID, mrIID, opts)
 }
 
-func (s *MergeRequestService) ListCommits(ctx context.Context, projectID int, ref string) ([]entity.Commit, error) {
-	return s.commitRepo.ListByRef(ctx, projectID, ref)
+func (s *MergeRequestService) ListCommits(ctx context.Context, projectID int, ref string, limit int) ([]entity.Commit, error) {
+	return s.commitRepo.ListByRef(ctx, projectID, ref, limit)
 }
@@ -5,20 +5,34 @@ import (
 
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/repository"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/pkg/fanout"
 )
 
 type PipelineService struct {
 	projectRepo  repository.ProjectRepository
 	pipelineRepo repository.PipelineRepository
+	concurrency  int
 }
 
 func NewPipelineService(pr repository.ProjectRepository, plr repository.PipelineRepository) *PipelineService {
-	return &PipelineService{projectRepo: pr, pipelineRepo: plr}
+	return &PipelineService{projectRepo: pr, pipelineRepo: plr, concurrency: fanout.DefaultLimit}
 }
 
+// SetConcurrency limits how many projects are loaded in parallel.
+func (s *PipelineService) SetConcurrency(n int) { s.concurrency = n }
+
+// ResolveProjects looks up projects by path. Projects that fail to resolve
+// are reported through an *entity.PartialError while the rest are returned.
+func (s *PipelineService) ResolveProjects(ctx context.Context, paths []string) ([]entity.Project, error) {
+	return s.loadEach(ctx, paths, func(ctx context.Context, path string) (*entity.Project, error) {
+		return s.projectRepo.GetByPath(ctx, path)
+	})
+}
+
+// LoadProjects resolves projects and counts their recent and active pipelines.
+// Like ResolveProjects, a failing project doesn't prevent the others from loading.
 func (s *PipelineService) LoadProjects(ctx context.Context, paths []string) ([]entity.Project, error) {
-	projects := make([]entity.Project, 0, len(paths))
-	for _, path := range paths {
+	return s.loadEach(ctx, paths, func(ctx context.Context, path string) (*entity.Project, error) {
 		p, err := s.projectRepo.GetByPath(ctx, path)
 		if err != nil {
 			return nil, err
@@ -27,14 +41,33 @@ func (s *PipelineService) LoadProjects(ctx context.Context, paths []string) ([]e
 		if err != nil {
 			return nil, err
 		}
-		p.PipelineCount = len(pipelines)
+		p.PipelineCount, p.ActiveCount = len(pipelines), 0
 		for _, pl := range pipelines {
 			if pl.Status.IsActive() {
 				p.ActiveCount++
 			}
 		}
+		return p, nil
+	})
+}
+
+func (s *PipelineService) loadEach(ctx context.Context, paths []string, load func(context.Context, string) (*entity.Project, error)) ([]entity.Project, error) {
+	results, errs := fanout.Run(ctx, paths, s.concurrency, load)
+	projects := make([]entity.Project, 0, len(paths))
+	var failed []entity.ProjectError
+	for i, p := range results {
+		if errs[i] != nil {
+			failed = append(failed, entity.ProjectError{Path: paths[i], Err: errs[i]})
+			continue
+		}
 		projects = append(projects, *p)
 	}
+	if err := entity.NewProjectLoadError(failed, len(paths)); err != nil {
+		if _, fatal := entity.AsPartial(err); fatal != nil {
+			return nil, fatal
+		}
+		return projects, err
+	}
 	return projects, nil
 }
 
@@ -43,6 +76,8 @@ func (s *PipelineService) ListPipelines(ctx context.Context, projectID int) ([]e
 }
 
 // LoadAllPipelines loads pipelines for all projects via the pipeline repository.
+// If only some projects fail, their pipelines are omitted and an
+// *entity.PartialError is returned together with the rest.
 func (s *PipelineService) LoadAllPipelines(ctx context.Context, paths []string, limit int) ([]entity.Pipeline, error) {
 	perProject := 20
 	if limit > 0 && len(paths) > 0 {
@@ -56,24 +91,54 @@ func (s *PipelineService) LoadAllPipelines(ctx context.Context, paths []string,
 	}
 
 	all, err := s.pipelineRepo.LoadAllPipelines(ctx, paths, perProject)
-	if err != nil {
-		return nil, err
+	if _, fatal := entity.AsPartial(err); fatal != nil {
+		return nil, fatal
 	}
 
 	if limit > 0 && len(all) > limit {
 		all = all[:limit]
 	}
-	return all, nil
+	return all, err
 }
 
-func (s *PipelineService) SearchProjects(ctx context.Context, query string) ([]entity.Project, error) {
-	return s.projectRepo.Search(ctx, query)
+func (s *PipelineService) SearchProjects(ctx context.Context, query string, limit int) ([]entity.Project, error) {
+	return s.projectRepo.Search(ctx, query, limit)
 }
 
-func (s *PipelineService) ListBranches(ctx context.Context, projectID int, search string) ([]string, error) {
-	return s.projectRepo.ListBranches(ctx, projectID, search)
+func (s *PipelineService) ListBranches(ctx context.Context, projectID int, search string, limit int) ([]string, error) {
+	return s.projectRepo.ListBranches(ctx, projectID, search, limit)
 }
 
 func (s *PipelineService) ListJobs(ctx context.Context, projectID, pipelineID int) ([]entity.Job, error) {
 	return s.pipelineRepo.ListJobs(ctx, projectID, pipelineID)
 }
+
+func (s *PipelineService) GetPipeline(ctx context.Context, projectID, pipelineID int) (*entity.Pipeline, error) {
+	return s.pipelineRepo.Get(ctx, projectID, pipelineID)
+}
+
+// LatestPipeline returns the newest pipeline for ref and/or sha, or nil if
+// GitLab has not created one yet.
+func (s *PipelineService) LatestPipeline(ctx context.Context, projectID int, ref, sha string) (*entity.Pipeline, error) {
+	return s.pipelineRepo.Latest(ctx, projectID, ref, sha)
+}
+
+// CreatePipeline starts a new pipeline for ref with the given variables.
+func (s *PipelineService) CreatePipeline(ctx context.Context, projectID int, ref string, variables []entity.PipelineVariable) (*entity.Pipeline, error) {
+	return s.pipelineRepo.Create(ctx, projectID, ref, variables)
+}
+
+// RetryPipeline reruns all failed and canceled jobs of a pipeline.
+func (s *PipelineService) RetryPipeline(ctx context.Context, projectID, pipelineID int) (*entity.Pipeline, error) {
+	return s.pipelineRepo.Retry(ctx, projectID, pipelineID)
+}
+
+// CancelPipeline cancels all running and pending jobs of a pipeline.
+func (s *PipelineService) CancelPipeline(ctx context.Context, projectID, pipelineID int) (*entity.Pipeline, error) {
+	return s.pipelineRepo.Cancel(ctx, projectID, pipelineID)
+}
+
+// JobNeeds returns the needs: edges of a pipeline's jobs, keyed by job name.
+func (s *PipelineService) JobNeeds(ctx context.Context, projectPath string, pipelineIID int) (map[string][]string, error) {
+	return s.pipelineRepo.JobNeeds(ctx, projectPath, pipelineIID)
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/repository"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/valueobject"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/infrastructure/cache"
+)
+
+// fakeProjects serves one project with a fixed list of pipelines.
+type fakeProjects struct {
+	repository.ProjectRepository
+	project   entity.Project
+	pipelines []entity.Pipeline
+}
+
+func (f fakeProjects) GetByPath(context.Context, string) (*entity.Project, error) {
+	p := f.project
+	return &p, nil
+}
+
+func (f fakeProjects) ListPipelines(context.Context, int) ([]entity.Pipeline, error) {
+	return f.pipelines, nil
+}
+
+func TestLoadProjectsRecountsSeededProjects(t *testing.T) {
+	inner := fakeProjects{
+		project: entity.Project{ID: 1, Name: "app", PathWithNS: "group/app"},
+		pipelines: []entity.Pipeline{
+			{ID: 1, Status: valueobject.PipelineRunning},
+			{ID: 2, Status: valueobject.PipelineSuccess},
+			{ID: 3, Status: valueobject.PipelinePending},
+		},
+	}
+	repo := cache.NewProjectRepo(inner, time.Minute)
+	// Counts from an old snapshot
+	repo.Seed([]entity.Project{{ID: 1, Name: "app", PathWithNS: "group/app", PipelineCount: 20, ActiveCount: 5}})
+	svc := NewPipelineService(repo, nil)
+
+	// Twice: the second load hits the entry cached by the first
+	for i := range 2 {
+		projects, err := svc.LoadProjects(context.Background(), []string{"group/app"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(projects) != 1 || projects[0].PipelineCount != 3 || projects[0].ActiveCount != 2 {
+			t.Fatalf("load %d: got %+v, want 3 pipelines with 2 active", i+1, projects)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Package bootstrap wires GitLab clients, caches and application services
+// for one config context. It is shared by glcli and glcli-mcp.
+package bootstrap
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/bearlogin/gitlab-awesome-cli/internal/application/service"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/repository"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/infrastructure/cache"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/infrastructure/config"
+	gitlabinfra "github.com/bearlogin/gitlab-awesome-cli/internal/infrastructure/gitlab"
+)
+
+// Env is everything needed to talk to one GitLab instance.
+type Env struct {
+	Config    *config.Config
+	Pipelines *service.PipelineService
+	Jobs      *service.JobService
+	MRs       *service.MergeRequestService
+	Limiter   *gitlabinfra.RateLimiter
+	Snapshots *cache.SnapshotStore
+	Snapshot  *cache.Snapshot // last known state from disk, nil if none
+	Viewed    *cache.ViewedStore
+}
+
+// New builds an Env for the active values of cfg.
+func New(cfg *config.Config) (*Env, error) {
+	limiter := gitlabinfra.NewRateLimiter()
+	client, err := gitlabinfra.NewClient(cfg.GitLabURL, cfg.AccessToken(), limiter, cache.NewETagCache())
+	if err != nil {
+		return nil, err
+	}
+
+	projectRepo := cache.NewProjectRepo(gitlabinfra.NewProjectRepo(client), cache.ProjectTTL)
+	snapshots := cache.NewSnapshotStore(cache.SnapshotPath(cfg.GitLabURL))
+	snapshot, err := snapshots.Load()
+	if err != nil {
+		log.Printf("snapshot ignored: %v", err)
+	}
+	if snapshot != nil {
+		projectRepo.Seed(snapshot.Projects)
+	}
+
+	gqlClient := gitlabinfra.NewGraphQLClient(cfg.GitLabURL, cfg.AccessToken(), limiter)
+	restPipelineRepo := gitlabinfra.NewPipelineRepo(client)
+	restPipelineRepo.SetConcurrency(cfg.Concurrency)
+	restPipelineRepo.SetGraphQL(gqlClient)
+	// the REST list lacks authors; this also covers the GraphQL fallback
+	restPipelineRepo.SetAuthors(cfg.Notifications.UsesAuthors())
+	var pipelineRepo repository.PipelineRepository = restPipelineRepo
+	if cfg.PipelineBackend == config.BackendGraphQL {
+		pipelineRepo = gitlabinfra.NewGraphQLPipelineRepo(gqlClient, restPipelineRepo)
+	}
+	jobRepo := gitlabinfra.NewJobRepo(client)
+	mrRepo := gitlabinfra.NewMergeRequestRepo(client)
+	mrRepo.SetGraphQL(gqlClient)
+	commitRepo := gitlabinfra.NewCommitRepo(client)
+
+	pipelineSvc := service.NewPipelineService(projectRepo, pipelineRepo)
+	mrSvc := service.NewMergeRequestService(mrRepo, commitRepo)
+	pipelineSvc.SetConcurrency(cfg.Concurrency)
+	mrSvc.SetConcurrency(cfg.Concurrency)
+
+	return &Env{
+		Config:    cfg,
+		Pipelines: pipelineSvc,
+		Jobs:      service.NewJobService(jobRepo),
+		MRs:       mrSvc,
+		Limiter:   limiter,
+		Snapshots: snapshots,
+		Snapshot:  snapshot,
+		Viewed:    cache.NewViewedStore(cache.ViewedPath(cfg.GitLabURL)),
+	}, nil
+}
+
+// Pool lazily builds and caches one Env per context, so a long-running
+// process can serve several GitLab instances.
+type Pool struct {
+	cfg *config.Config
+
+	mu   sync.Mutex
+	envs map[string]*Env
+}
+
+// NewPool creates a pool; current is the already built Env for the
+// active context of cfg.
+func NewPool(cfg *config.Config, current *Env) *Pool {
+	return &Pool{
+		cfg:  cfg,
+		envs: map[string]*Env{cfg.ActiveContext(): current},
+	}
+}
+
+// Config returns the config the pool was created with.
+func (p *Pool) Config() *config.Config { return p.cfg }
+
+// Get returns the Env for a context; an empty name means the active one.
+func (p *Pool) Get(name string) (*Env, error) {
+	if name == "" {
+		name = p.cfg.ActiveContext()
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if env, ok := p.envs[name]; ok {
+		return env, nil
+	}
+	cfg, err := p.cfg.ForContext(name)
+	if err != nil {
+		return nil, err
+	}
+	env, err := New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("context %q: %w", name, err)
+	}
+	log.Printf("[bootstrap] built env for context %q: url=%s", name, cfg.GitLabURL)
+	p.envs[name] = env
+	return env, nil
+}
@@ -0,0 +1,26 @@
+package entity
+
+// Discussion is a thread of notes on a merge request. A comment nobody
+// replied to is a discussion with one note.
+type Discussion struct {
+	ID         string
+	Individual bool // a standalone comment rather than a thread
+	Notes      []MRNote
+	Resolvable bool
+	Resolved   bool
+	Position   *DiffPosition // set for threads on a diff line
+}
+
+// Unresolved reports whether the thread still needs attention.
+func (d Discussion) Unresolved() bool { return d.Resolvable && !d.Resolved }
+
+// CountUnresolved returns how many threads still need attention.
+func CountUnresolved(discussions []Discussion) int {
+	n := 0
+	for _, d := range discussions {
+		if d.Unresolved() {
+			n++
+		}
+	}
+	return n
+}
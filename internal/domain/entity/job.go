@@ -17,4 +17,22 @@ type Job struct {
 	StartedAt  *time.Time
 	FinishedAt *time.Time
 	WebURL     string
+	// Bridge marks trigger jobs, which start a downstream pipeline instead
+	// of running a script and so have no log.
+	Bridge bool
+	// Downstream is the pipeline a bridge job started, nil for regular jobs
+	// and for bridges that haven't run yet.
+	Downstream *DownstreamPipeline
+}
+
+// DownstreamPipeline is a child or multi-project pipeline triggered by a
+// bridge job.
+type DownstreamPipeline struct {
+	ID          int
+	IID         int
+	ProjectID   int
+	ProjectPath string
+	Ref         string
+	Status      valueobject.PipelineStatus
+	WebURL      string
 }
@@ -0,0 +1,10 @@
+package entity
+
+import "github.com/bearlogin/gitlab-awesome-cli/internal/domain/valueobject"
+
+// LogChunk is a slice of a job trace starting at a byte offset.
+type LogChunk struct {
+	Data      string
+	Offset    int64 // offset of the end of Data, i.e. where the next read starts
+	JobStatus valueobject.JobStatus
+}
@@ -10,20 +10,41 @@ type CreateMROptions struct {
 	Draft        bool
 }
 
+// MergeOptions control how a merge request is merged. Empty commit
+// messages leave GitLab's defaults.
+type MergeOptions struct {
+	WhenPipelineSucceeds bool // set auto-merge instead of merging now
+	Squash               bool
+	SquashCommitMessage  string
+	MergeCommitMessage   string
+	RemoveSourceBranch   bool
+	SHA                  string // refuse to merge if the source branch moved past this commit
+}
+
 type MergeRequest struct {
-	ID           int
-	IID          int
-	ProjectID    int
-	ProjectPath  string
-	Title        string
-	Description  string
-	State        string
-	Author       string
-	SourceBranch string
-	TargetBranch string
-	MergeStatus  string
-	Draft        bool
-	WebURL       string
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	ID             int
+	IID            int
+	ProjectID      int
+	ProjectPath    string
+	Title          string
+	Description    string
+	State          string
+	Author         string
+	SourceBranch   string
+	TargetBranch   string
+	MergeStatus    string
+	Draft          bool
+	WebURL         string
+	SHA            string // head commit of the source branch
+	Squash         bool   // the author asked to squash on merge
+	RemoveSource   bool   // the author asked to delete the source branch on merge
+	AutoMerge      bool   // set to merge when the pipeline succeeds
+	PipelineStatus string // status of the head pipeline, "" if there is none
+	DiffRefs       DiffRefs
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	// UnresolvedThreads counts open review threads; only known for open
+	// MRs in lists, and only when GraphQL is reachable
+	UnresolvedThreads int
 }
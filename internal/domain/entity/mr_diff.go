@@ -8,3 +8,20 @@ type MRDiff struct {
 	DeletedFile bool
 	RenamedFile bool
 }
+
+// DiffRefs identify the diff version a position refers to.
+type DiffRefs struct {
+	BaseSHA  string
+	StartSHA string
+	HeadSHA  string
+}
+
+// DiffPosition anchors a comment to a line of a diff. OldLine is 0 for an
+// added line and NewLine is 0 for a removed one; context lines have both.
+type DiffPosition struct {
+	DiffRefs
+	OldPath string
+	NewPath string
+	OldLine int
+	NewLine int
+}
@@ -3,9 +3,11 @@ package entity
 import "time"
 
 type MRNote struct {
-	ID        int
-	Author    string
-	Body      string
-	CreatedAt time.Time
-	System    bool
+	ID           int
+	DiscussionID string // thread the note belongs to; replies go here
+	Author       string
+	Body         string
+	CreatedAt    time.Time
+	System       bool
+	Position     *DiffPosition // set for comments on a diff line
 }
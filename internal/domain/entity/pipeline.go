@@ -8,11 +8,22 @@ import (
 
 type Pipeline struct {
 	ID          int
+	IID         int
 	ProjectID   int
 	ProjectPath string
 	Ref         string
+	SHA         string
+	Source      string
+	Author      string
 	Status      valueobject.PipelineStatus
 	CreatedAt   time.Time
 	Duration    int
 	JobCount    int
+	WebURL      string
+}
+
+// PipelineVariable is a CI/CD variable passed to a newly created pipeline.
+type PipelineVariable struct {
+	Key   string
+	Value string
 }
@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ProjectError records a failure for one project during a multi-project load.
+type ProjectError struct {
+	Path string
+	Err  error
+}
+
+func (e ProjectError) Error() string { return e.Path + ": " + e.Err.Error() }
+
+func (e ProjectError) Unwrap() error { return e.Err }
+
+// PartialError is returned alongside results when some projects failed to
+// load but others succeeded, so callers can render what they have.
+type PartialError struct {
+	Failed []ProjectError
+	Total  int
+}
+
+func (e *PartialError) Error() string {
+	msgs := make([]string, len(e.Failed))
+	for i, f := range e.Failed {
+		msgs[i] = f.Error()
+	}
+	return fmt.Sprintf("%d of %d projects failed: %s", len(e.Failed), e.Total, strings.Join(msgs, "; "))
+}
+
+// AsPartial separates a partial multi-project failure, whose results are still
+// worth showing, from a fatal error. It returns the *PartialError found in err,
+// if any, and otherwise err itself.
+func AsPartial(err error) (*PartialError, error) {
+	var partial *PartialError
+	if errors.As(err, &partial) {
+		return partial, nil
+	}
+	return nil, err
+}
+
+// NewProjectLoadError summarises per-project failures out of total: nil when
+// nothing failed, a *PartialError when only some did, and a plain error when
+// every project failed.
+func NewProjectLoadError(failed []ProjectError, total int) error {
+	switch {
+	case len(failed) == 0:
+		return nil
+	case len(failed) < total:
+		return &PartialError{Failed: failed, Total: total}
+	case total == 1:
+		return failed[0]
+	default:
+		return fmt.Errorf("all %d projects failed, last error: %w", total, failed[len(failed)-1])
+	}
+}
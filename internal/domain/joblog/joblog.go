@@ -0,0 +1,256 @@
+// Package joblog parses GitLab CI job traces into lines and collapsible sections.
+package joblog
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Log is a parsed job trace. Lines are kept flat in display order; each line
+// points at its innermost section so callers can fold sections without a tree walk.
+type Log struct {
+	Lines    []Line
+	Sections []Section
+}
+
+type Line struct {
+	Text    string // ANSI colour codes preserved
+	Section int    // index into Log.Sections, -1 when outside any section
+	Header  bool   // true for the first line of a section
+}
+
+type Section struct {
+	Name      string
+	Header    string
+	Start     time.Time
+	End       time.Time // zero while the section is still open
+	Parent    int       // index of the enclosing section, -1 at top level
+	Depth     int
+	Collapsed bool // requested via [collapsed=true] in the trace
+}
+
+// Duration returns how long the section ran, or zero if it has not ended yet.
+func (s Section) Duration() time.Duration {
+	if s.End.IsZero() || s.Start.IsZero() {
+		return 0
+	}
+	return s.End.Sub(s.Start)
+}
+
+// sectionMarker matches GitLab section markers, e.g.
+// "section_start:1700000000:build_script[collapsed=true]\r\x1b[0K".
+var sectionMarker = regexp.MustCompile(`section_(start|end):(\d+):([A-Za-z0-9_.\-]+)(\[[^\]]*\])?\r?(\x1b\[0?K)?`)
+
+// eraseLine matches the erase-in-line escape GitLab sprinkles around markers;
+// it would wipe the rest of the terminal row if passed through.
+var eraseLine = regexp.MustCompile(`\x1b\[0?K`)
+
+// ansiEscape matches SGR colour sequences.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// Parse converts a raw trace into a Log.
+func Parse(trace string) *Log {
+	var p Parser
+	p.Write(trace)
+	return p.Log()
+}
+
+// Parser parses a trace that arrives in chunks, as when tailing a running
+// job. Each chunk only costs the lines it touches: the open sections carry
+// over to the next one. A trailing line without a newline is parsed so it
+// shows up, then undone and parsed again once the rest of it arrives.
+type Parser struct {
+	log     Log
+	stack   []int  // open sections, innermost last
+	pending string // text after the last newline
+	undo    *undo  // how to take back the parsed pending line
+}
+
+type undo struct {
+	lines, sections int
+	stack           []int
+	ends            map[int]time.Time // End of sections the pending line closed
+}
+
+// Log returns the trace parsed so far. It keeps changing with Write.
+func (p *Parser) Log() *Log { return &p.log }
+
+// Write parses the next chunk of the trace and returns the index of the
+// first line that changed; lines before it are as they were.
+func (p *Parser) Write(chunk string) int {
+	p.rollback()
+	from := len(p.log.Lines)
+	lines := strings.Split(p.pending+chunk, "\n")
+	p.pending = lines[len(lines)-1]
+	for _, raw := range lines[:len(lines)-1] {
+		p.line(raw)
+	}
+	if p.pending != "" {
+		p.undo = &undo{
+			lines:    len(p.log.Lines),
+			sections: len(p.log.Sections),
+			stack:    slices.Clone(p.stack),
+			ends:     make(map[int]time.Time),
+		}
+		p.line(p.pending)
+		// an unfinished line that is still blank isn't worth a row
+		if n := len(p.log.Lines); n > p.undo.lines && p.log.Lines[n-1].Text == "" && !p.log.Lines[n-1].Header {
+			p.log.Lines = p.log.Lines[:n-1]
+		}
+	}
+	return from
+}
+
+func (p *Parser) rollback() {
+	u := p.undo
+	if u == nil {
+		return
+	}
+	p.log.Lines = p.log.Lines[:u.lines]
+	p.log.Sections = p.log.Sections[:u.sections]
+	p.stack = u.stack
+	for i, end := range u.ends {
+		if i < len(p.log.Sections) {
+			p.log.Sections[i].End = end
+		}
+	}
+	p.undo = nil
+}
+
+func (p *Parser) current() int {
+	if len(p.stack) == 0 {
+		return -1
+	}
+	return p.stack[len(p.stack)-1]
+}
+
+// line parses one line of the trace, which may carry section markers.
+func (p *Parser) line(raw string) {
+	l := &p.log
+	rest := raw
+	header := -1
+	// emit adds text as a line: the header of a section started earlier on
+	// this line, or a plain line of the current section
+	emit := func(text string) {
+		if header >= 0 {
+			l.Sections[header].Header = text
+			l.Lines = append(l.Lines, Line{Text: text, Section: header, Header: true})
+			header = -1
+			return
+		}
+		l.Lines = append(l.Lines, Line{Text: text, Section: p.current()})
+	}
+
+	for {
+		loc := sectionMarker.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			break
+		}
+		kind := rest[loc[2]:loc[3]]
+		ts, _ := strconv.ParseInt(rest[loc[4]:loc[5]], 10, 64)
+		name := rest[loc[6]:loc[7]]
+		opts := ""
+		if loc[8] >= 0 {
+			opts = rest[loc[8]:loc[9]]
+		}
+		// Output without a trailing newline gets the marker appended to it
+		if text := clean(rest[:loc[0]]); text != "" {
+			emit(text)
+		}
+		rest = rest[loc[1]:]
+
+		switch kind {
+		case "start":
+			if header >= 0 {
+				emit("")
+			}
+			idx := len(l.Sections)
+			l.Sections = append(l.Sections, Section{
+				Name:      name,
+				Start:     time.Unix(ts, 0),
+				Parent:    p.current(),
+				Depth:     len(p.stack),
+				Collapsed: strings.Contains(opts, "collapsed=true"),
+			})
+			p.stack = append(p.stack, idx)
+			header = idx
+		case "end":
+			// Close the matching section and anything left open inside it
+			for i := len(p.stack) - 1; i >= 0; i-- {
+				s := p.stack[i]
+				if l.Sections[s].Name == name {
+					if p.undo != nil {
+						if _, ok := p.undo.ends[s]; !ok {
+							p.undo.ends[s] = l.Sections[s].End
+						}
+					}
+					l.Sections[s].End = time.Unix(ts, 0)
+					p.stack = p.stack[:i]
+					break
+				}
+			}
+		}
+	}
+
+	text := clean(rest)
+	if header < 0 && text == "" && rest != raw {
+		// nothing left but section markers
+		return
+	}
+	emit(text)
+}
+
+// clean drops erase-line escapes and applies carriage returns.
+func clean(s string) string {
+	return overwrite(eraseLine.ReplaceAllString(s, ""))
+}
+
+// overwrite applies carriage returns the way the GitLab web UI does: only
+// the text after the last \r survives, so progress bars show their final state.
+func overwrite(s string) string {
+	if !strings.Contains(s, "\r") {
+		return s
+	}
+	parts := strings.Split(s, "\r")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if parts[i] != "" {
+			return parts[i]
+		}
+	}
+	return ""
+}
+
+// Hidden reports whether line i is folded away by a collapsed ancestor.
+// collapsed is indexed by section.
+func (l *Log) Hidden(i int, collapsed []bool) bool {
+	ln := l.Lines[i]
+	s := ln.Section
+	if ln.Header && s >= 0 {
+		s = l.Sections[s].Parent
+	}
+	for s >= 0 {
+		if s < len(collapsed) && collapsed[s] {
+			return true
+		}
+		s = l.Sections[s].Parent
+	}
+	return false
+}
+
+// StripANSI removes colour escape sequences, for consumers that want plain text.
+func StripANSI(s string) string {
+	return ansiEscape.ReplaceAllString(s, "")
+}
+
+// FormatDuration renders a section duration the way GitLab does: "1m 05s", "12s".
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Minute {
+		return strconv.Itoa(int(d.Seconds())) + "s"
+	}
+	return fmt.Sprintf("%dm %02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
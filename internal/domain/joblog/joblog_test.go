@@ -0,0 +1,168 @@
+package joblog
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func texts(l *Log) []string {
+	out := make([]string, len(l.Lines))
+	for i, ln := range l.Lines {
+		out[i] = ln.Text
+	}
+	return out
+}
+
+func TestParseLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		trace string
+		want  []string
+	}{
+		{"plain", "one\ntwo\n", []string{"one", "two"}},
+		{"no trailing newline", "one\ntwo", []string{"one", "two"}},
+		{"blank lines kept", "one\n\ntwo\n", []string{"one", "", "two"}},
+		{"carriage return keeps the last write", "10%\r50%\r100%\n", []string{"100%"}},
+		{"trailing carriage return", "done\r\n", []string{"done"}},
+		{"erase line dropped", "\x1b[0Kstep\n", []string{"step"}},
+		{"colours preserved", "\x1b[32;1mok\x1b[0;m\n", []string{"\x1b[32;1mok\x1b[0;m"}},
+		{
+			"marker-only lines dropped",
+			"section_start:1:a\r\x1b[0Khead\nbody\nsection_end:2:a\r\x1b[0K\nafter\n",
+			[]string{"head", "body", "after"},
+		},
+		{
+			"output before a marker on the same line",
+			"section_start:1:a\r\x1b[0Khead\nno newline\r\x1b[0Ksection_end:2:a\r\x1b[0K\n",
+			[]string{"head", "no newline"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := texts(Parse(tt.trace)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSections(t *testing.T) {
+	trace := "before\n" +
+		"section_start:100:outer[collapsed=true]\r\x1b[0KOuter\n" +
+		"in outer\n" +
+		"section_start:101:inner\r\x1b[0KInner\n" +
+		"in inner\n" +
+		"section_end:105:inner\r\x1b[0K\n" +
+		"back in outer\n" +
+		"section_end:110:outer\r\x1b[0K\n" +
+		"section_start:120:open\r\x1b[0KStill running\n" +
+		"tail\n"
+	l := Parse(trace)
+
+	wantLines := []Line{
+		{Text: "before", Section: -1},
+		{Text: "Outer", Section: 0, Header: true},
+		{Text: "in outer", Section: 0},
+		{Text: "Inner", Section: 1, Header: true},
+		{Text: "in inner", Section: 1},
+		{Text: "back in outer", Section: 0},
+		{Text: "Still running", Section: 2, Header: true},
+		{Text: "tail", Section: 2},
+	}
+	if !reflect.DeepEqual(l.Lines, wantLines) {
+		t.Errorf("lines:\n got %+v\nwant %+v", l.Lines, wantLines)
+	}
+
+	if len(l.Sections) != 3 {
+		t.Fatalf("got %d sections, want 3", len(l.Sections))
+	}
+	outer, inner, open := l.Sections[0], l.Sections[1], l.Sections[2]
+	if !outer.Collapsed || inner.Collapsed {
+		t.Errorf("collapsed: outer %v, inner %v", outer.Collapsed, inner.Collapsed)
+	}
+	if inner.Parent != 0 || inner.Depth != 1 || outer.Parent != -1 || open.Parent != -1 {
+		t.Errorf("nesting: inner parent %d depth %d, outer parent %d, open parent %d",
+			inner.Parent, inner.Depth, outer.Parent, open.Parent)
+	}
+	if outer.Duration() != 10*time.Second || inner.Duration() != 4*time.Second {
+		t.Errorf("durations: outer %v, inner %v", outer.Duration(), inner.Duration())
+	}
+	if !open.End.IsZero() || open.Duration() != 0 {
+		t.Errorf("open section ended at %v", open.End)
+	}
+
+	collapsed := []bool{true, false, false}
+	for i, want := range []bool{false, false, true, true, true, true, false, false} {
+		if got := l.Hidden(i, collapsed); got != want {
+			t.Errorf("Hidden(%d) = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestParseEndClosesInnerSections(t *testing.T) {
+	trace := "section_start:1:outer\r\x1b[0KOuter\n" +
+		"section_start:2:inner\r\x1b[0KInner\n" +
+		"section_end:3:outer\r\x1b[0K\n" +
+		"after\n"
+	l := Parse(trace)
+	if got := l.Lines[len(l.Lines)-1]; got.Section != -1 {
+		t.Errorf("line after the outer end is in section %d", got.Section)
+	}
+	if !l.Sections[1].End.IsZero() {
+		t.Errorf("inner section was given an end")
+	}
+}
+
+// Feeding a trace in chunks, split anywhere, must give what parsing it at
+// once gives.
+func TestParserChunks(t *testing.T) {
+	trace := "start\n" +
+		"section_start:100:build[collapsed=true]\r\x1b[0KBuild\n" +
+		"10%\r50%\r100%\n" +
+		"partial output\r\x1b[0Ksection_end:130:build\r\x1b[0K\n" +
+		"section_start:140:test\r\x1b[0KTest\n" +
+		"ok\n" +
+		"section_end:150:test\r\x1b[0K\n" +
+		"done"
+	want := Parse(trace)
+	for size := 1; size <= len(trace); size++ {
+		var p Parser
+		for i := 0; i < len(trace); i += size {
+			from := p.Write(trace[i:min(i+size, len(trace))])
+			if from > len(p.Log().Lines) {
+				t.Fatalf("size %d: Write returned %d past %d lines", size, from, len(p.Log().Lines))
+			}
+		}
+		if got := p.Log(); !reflect.DeepEqual(got.Lines, want.Lines) || !reflect.DeepEqual(got.Sections, want.Sections) {
+			t.Fatalf("chunks of %d:\n got %+v %+v\nwant %+v %+v", size, got.Lines, got.Sections, want.Lines, want.Sections)
+		}
+	}
+}
+
+func TestParserWriteReportsFirstChangedLine(t *testing.T) {
+	var p Parser
+	if from := p.Write("one\ntw"); from != 0 {
+		t.Errorf("first write: from %d, want 0", from)
+	}
+	// "tw" was shown unfinished at line 1 and is replaced now
+	if from := p.Write("o\nthree\n"); from != 1 {
+		t.Errorf("second write: from %d, want 1", from)
+	}
+	if got := texts(p.Log()); !reflect.DeepEqual(got, []string{"one", "two", "three"}) {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		12 * time.Second:                      "12s",
+		65 * time.Second:                      "1m 05s",
+		59*time.Second + 600*time.Millisecond: "1m 00s",
+	}
+	for d, want := range tests {
+		if got := FormatDuration(d); got != want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
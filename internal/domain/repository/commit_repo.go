@@ -7,5 +7,5 @@ import (
 )
 
 type CommitRepository interface {
-	ListByRef(ctx context.Context, projectID int, ref string) ([]entity.Commit, error)
+	ListByRef(ctx context.Context, projectID int, ref string, limit int) ([]entity.Commit, error) // limit 0 means all
 }
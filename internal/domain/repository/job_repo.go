@@ -11,5 +11,7 @@ type JobRepository interface {
 	Play(ctx context.Context, projectID, jobID int) (*entity.Job, error)
 	Retry(ctx context.Context, projectID, jobID int) (*entity.Job, error)
 	Cancel(ctx context.Context, projectID, jobID int) (*entity.Job, error)
+	Get(ctx context.Context, projectID, jobID int) (*entity.Job, error)
 	GetLog(ctx context.Context, projectID, jobID int) (io.ReadCloser, error)
+	GetLogFrom(ctx context.Context, projectID, jobID int, offset int64) ([]byte, error)
 }
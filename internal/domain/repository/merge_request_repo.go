@@ -6,12 +6,18 @@ import (
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
 )
 
+// List calls take a limit on the number of items; 0 means all of them.
 type MergeRequestRepository interface {
-	List(ctx context.Context, projectID int, state string) ([]entity.MergeRequest, error)
+	List(ctx context.Context, projectID int, state string, limit int) ([]entity.MergeRequest, error)
 	Get(ctx context.Context, projectID, mrIID int) (*entity.MergeRequest, error)
 	ListNotes(ctx context.Context, projectID, mrIID int) ([]entity.MRNote, error)
+	ListDiscussions(ctx context.Context, projectID, mrIID int) ([]entity.Discussion, error)
+	ResolveDiscussion(ctx context.Context, projectID, mrIID int, discussionID string, resolved bool) (*entity.Discussion, error)
+	AddNote(ctx context.Context, projectID, mrIID int, body string) (*entity.MRNote, error)
+	AddDiffNote(ctx context.Context, projectID, mrIID int, body string, pos entity.DiffPosition) (*entity.MRNote, error)
+	ReplyToDiscussion(ctx context.Context, projectID, mrIID int, discussionID, body string) (*entity.MRNote, error)
 	GetDiffs(ctx context.Context, projectID, mrIID int) ([]entity.MRDiff, error)
 	Create(ctx context.Context, projectID int, opts entity.CreateMROptions) (*entity.MergeRequest, error)
 	Approve(ctx context.Context, projectID, mrIID int) error
-	Merge(ctx context.Context, projectID, mrIID int) (*entity.MergeRequest, error)
+	Merge(ctx context.Context, projectID, mrIID int, opts entity.MergeOptions) (*entity.MergeRequest, error)
 }
@@ -9,4 +9,15 @@ import (
 type PipelineRepository interface {
 	ListJobs(ctx context.Context, projectID, pipelineID int) ([]entity.Job, error)
 	LoadAllPipelines(ctx context.Context, projectPaths []string, perProject int) ([]entity.Pipeline, error)
+	Get(ctx context.Context, projectID, pipelineID int) (*entity.Pipeline, error)
+	// Latest returns the newest pipeline matching ref and/or sha, or nil if
+	// there is none yet.
+	Latest(ctx context.Context, projectID int, ref, sha string) (*entity.Pipeline, error)
+	Create(ctx context.Context, projectID int, ref string, variables []entity.PipelineVariable) (*entity.Pipeline, error)
+	// Retry reruns the failed and canceled jobs of a pipeline.
+	Retry(ctx context.Context, projectID, pipelineID int) (*entity.Pipeline, error)
+	Cancel(ctx context.Context, projectID, pipelineID int) (*entity.Pipeline, error)
+	// JobNeeds maps job names to the names of the jobs they list under
+	// needs:. Pipelines without needs yield an empty map.
+	JobNeeds(ctx context.Context, projectPath string, pipelineIID int) (map[string][]string, error)
 }
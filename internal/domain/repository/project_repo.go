@@ -6,9 +6,11 @@ import (
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
 )
 
+// Search and ListBranches take a limit on the number of items; 0 means all
+// of them.
 type ProjectRepository interface {
 	GetByPath(ctx context.Context, pathWithNS string) (*entity.Project, error)
-	Search(ctx context.Context, query string) ([]entity.Project, error)
+	Search(ctx context.Context, query string, limit int) ([]entity.Project, error)
 	ListPipelines(ctx context.Context, projectID int) ([]entity.Pipeline, error)
-	ListBranches(ctx context.Context, projectID int, search string) ([]string, error)
+	ListBranches(ctx context.Context, projectID int, search string, limit int) ([]string, error)
 }
@@ -38,17 +38,38 @@ func (s PipelineStatus) IsActive() bool {
 	return s == PipelineRunning || s == PipelinePending
 }
 
+// CanRetry reports whether retrying the pipeline would rerun any jobs.
+func (s PipelineStatus) CanRetry() bool {
+	return s == PipelineFailed || s == PipelineCanceled
+}
+
+func (s PipelineStatus) CanCancel() bool {
+	return s == PipelineRunning || s == PipelinePending || s == PipelineCreated
+}
+
+// IsFinished reports whether the pipeline has stopped running. A pipeline
+// blocked on a manual job counts as finished: nothing happens until
+// someone plays it.
+func (s PipelineStatus) IsFinished() bool {
+	switch s {
+	case PipelineSuccess, PipelineFailed, PipelineCanceled, PipelineSkipped, PipelineManual:
+		return true
+	}
+	return false
+}
+
 type JobStatus string
 
 const (
-	JobRunning  JobStatus = "running"
-	JobPending  JobStatus = "pending"
-	JobSuccess  JobStatus = "success"
-	JobFailed   JobStatus = "failed"
-	JobCanceled JobStatus = "canceled"
-	JobSkipped  JobStatus = "skipped"
-	JobManual   JobStatus = "manual"
-	JobCreated  JobStatus = "created"
+	JobRunning   JobStatus = "running"
+	JobPending   JobStatus = "pending"
+	JobSuccess   JobStatus = "success"
+	JobFailed    JobStatus = "failed"
+	JobCanceled  JobStatus = "canceled"
+	JobSkipped   JobStatus = "skipped"
+	JobManual    JobStatus = "manual"
+	JobScheduled JobStatus = "scheduled"
+	JobCreated   JobStatus = "created"
 )
 
 func (s JobStatus) Symbol() string {
@@ -65,7 +86,7 @@ func (s JobStatus) Symbol() string {
 		return "⊘"
 	case JobSkipped:
 		return "»"
-	case JobManual:
+	case JobManual, JobScheduled:
 		return "⏸"
 	default:
 		return "?"
@@ -79,3 +100,14 @@ func (s JobStatus) IsActionable() bool {
 func (s JobStatus) CanCancel() bool {
 	return s == JobRunning || s == JobPending
 }
+
+// IsTerminal reports whether the job has finished and its log will not grow.
+func (s JobStatus) IsTerminal() bool {
+	return s == JobSuccess || s == JobFailed || s == JobCanceled || s == JobSkipped
+}
+
+// IsWaiting reports whether the job waits for someone to start it or for
+// its scheduled time; its log won't grow until then.
+func (s JobStatus) IsWaiting() bool {
+	return s == JobManual || s == JobScheduled
+}
@@ -0,0 +1,29 @@
+package valueobject
+
+import "testing"
+
+func TestJobStatusLogGrowth(t *testing.T) {
+	tests := []struct {
+		status   JobStatus
+		terminal bool
+		waiting  bool
+	}{
+		{JobRunning, false, false},
+		{JobPending, false, false},
+		{JobCreated, false, false},
+		{JobManual, false, true},
+		{JobScheduled, false, true},
+		{JobSuccess, true, false},
+		{JobFailed, true, false},
+		{JobCanceled, true, false},
+		{JobSkipped, true, false},
+	}
+	for _, tt := range tests {
+		if got := tt.status.IsTerminal(); got != tt.terminal {
+			t.Errorf("%s.IsTerminal() = %v, want %v", tt.status, got, tt.terminal)
+		}
+		if got := tt.status.IsWaiting(); got != tt.waiting {
+			t.Errorf("%s.IsWaiting() = %v, want %v", tt.status, got, tt.waiting)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Package cache provides response caching shared by the TUI and the MCP
+// server: an HTTP transport that revalidates GET requests with ETags, and
+// repository decorators that memoise slow-changing lookups.
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// maxETagEntries bounds the number of cached responses; the oldest
+	// entry is dropped when the cache is full.
+	maxETagEntries = 512
+	// maxETagBody skips caching large bodies such as full job traces.
+	maxETagBody = 2 << 20
+)
+
+// ETagCache remembers GET responses that carried an ETag and replays them
+// when GitLab answers a conditional request with 304 Not Modified. Polling
+// an unchanged list then costs a round-trip but no payload.
+type ETagCache struct {
+	mu      sync.Mutex
+	entries map[string]*etagEntry
+	order   []string // insertion order, for eviction
+
+	hits, misses atomic.Int64
+}
+
+type etagEntry struct {
+	etag   string
+	status int
+	header http.Header
+	body   []byte
+}
+
+func NewETagCache() *ETagCache {
+	return &ETagCache{entries: make(map[string]*etagEntry)}
+}
+
+// Transport wraps base so that cacheable requests are revalidated with If-None-Match.
+func (c *ETagCache) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &etagTransport{base: base, cache: c}
+}
+
+// Stats returns the number of requests answered from the cache and the
+// number that had to download a body.
+func (c *ETagCache) Stats() (hits, misses int64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+func (c *ETagCache) get(key string) *etagEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries[key]
+}
+
+func (c *ETagCache) put(key string, e *etagEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.entries[key] = e
+	for len(c.order) > maxETagEntries {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+type etagTransport struct {
+	base  http.RoundTripper
+	cache *ETagCache
+}
+
+func (t *etagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Ranged reads (log tailing) and anything but GET go straight through
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	cached := t.cache.get(key)
+	if cached != nil {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		hits := t.cache.hits.Add(1)
+		log.Printf("[cache] etag hit: %s (%d bytes, hits=%d misses=%d)",
+			req.URL.Path, len(cached.body), hits, t.cache.misses.Load())
+		return replay(req, resp, cached), nil
+	}
+
+	misses := t.cache.misses.Add(1)
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxETagBody+1))
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if len(body) <= maxETagBody {
+		t.cache.put(key, &etagEntry{
+			etag:   etag,
+			status: resp.StatusCode,
+			header: resp.Header.Clone(),
+			body:   body,
+		})
+		log.Printf("[cache] etag stored: %s (%d bytes, hits=%d misses=%d)",
+			req.URL.Path, len(body), t.cache.hits.Load(), misses)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// replay builds a 200 response from a cached entry. Pagination headers come
+// from the cache; everything the server sent with the 304 (rate limit
+// counters, dates) takes precedence.
+func replay(req *http.Request, notModified *http.Response, e *etagEntry) *http.Response {
+	header := e.header.Clone()
+	for k, v := range notModified.Header {
+		switch k {
+		case "Content-Length", "Content-Type", "Content-Encoding":
+			continue
+		}
+		header[k] = v
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.status, http.StatusText(e.status)),
+		StatusCode:    e.status,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+// cacheKey separates entries per URL and per credential, so two tokens
+// with different permissions never see each other's responses.
+func cacheKey(req *http.Request) string {
+	return req.URL.String() + "\x00" + req.Header.Get("PRIVATE-TOKEN") + req.Header.Get("Authorization")
+}
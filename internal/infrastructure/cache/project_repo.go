@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/repository"
+)
+
+// ProjectTTL is how long a resolved project path is trusted. Paths only
+// change on rename or transfer, so a few minutes is plenty.
+const ProjectTTL = 5 * time.Minute
+
+// ProjectRepo memoises GetByPath so that resolving project IDs on every
+// refresh doesn't cost a request per project. Other methods pass through.
+type ProjectRepo struct {
+	repository.ProjectRepository
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]projectEntry
+}
+
+type projectEntry struct {
+	project entity.Project
+	expires time.Time
+}
+
+func NewProjectRepo(inner repository.ProjectRepository, ttl time.Duration) *ProjectRepo {
+	return &ProjectRepo{
+		ProjectRepository: inner,
+		ttl:               ttl,
+		entries:           make(map[string]projectEntry),
+	}
+}
+
+func (r *ProjectRepo) GetByPath(ctx context.Context, pathWithNS string) (*entity.Project, error) {
+	r.mu.Lock()
+	e, ok := r.entries[pathWithNS]
+	r.mu.Unlock()
+	if ok && time.Now().Before(e.expires) {
+		log.Printf("[cache] project hit: %s -> %d", pathWithNS, e.project.ID)
+		p := e.project
+		return &p, nil
+	}
+
+	log.Printf("[cache] project miss: %s", pathWithNS)
+	p, err := r.ProjectRepository.GetByPath(ctx, pathWithNS)
+	if err != nil {
+		// Errors are not cached; a missing project may be created or
+		// access granted in the meantime
+		return nil, err
+	}
+	r.mu.Lock()
+	r.entries[pathWithNS] = projectEntry{project: *p, expires: time.Now().Add(r.ttl)}
+	r.mu.Unlock()
+	return p, nil
+}
+
+// Seed preloads resolved projects, e.g. from an on-disk snapshot, so the
+// first refresh doesn't have to look them up again. Only the identity of a
+// project is kept; its pipeline counts are stale and get recounted.
+func (r *ProjectRepo) Seed(projects []entity.Project) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	expires := time.Now().Add(r.ttl)
+	for _, p := range projects {
+		if p.ID == 0 {
+			continue
+		}
+		p.PipelineCount, p.ActiveCount = 0, 0
+		r.entries[p.PathWithNS] = projectEntry{project: p, expires: expires}
+	}
+	log.Printf("[cache] seeded %d projects", len(projects))
+}
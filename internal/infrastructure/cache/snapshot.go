@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+)
+
+// Snapshot is the last known state of the configured projects, kept on disk
+// so the TUI has something to show before the first request completes and
+// while GitLab is unreachable.
+type Snapshot struct {
+	SavedAt   time.Time             `json:"saved_at"`
+	Projects  []entity.Project      `json:"projects,omitempty"`
+	Pipelines []entity.Pipeline     `json:"pipelines,omitempty"`
+	MRs       []entity.MergeRequest `json:"merge_requests,omitempty"`
+}
+
+// SnapshotStore reads and writes a snapshot file.
+type SnapshotStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// SnapshotPath returns the snapshot file for a GitLab instance under the
+// user cache dir ($XDG_CACHE_HOME/glcli on Linux), one file per instance.
+func SnapshotPath(gitlabURL string) string {
+	return cachePath(gitlabURL, ".json")
+}
+
+func cachePath(gitlabURL, suffix string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".cache")
+	}
+	host := strings.TrimPrefix(strings.TrimPrefix(gitlabURL, "https://"), "http://")
+	name := strings.Trim(unsafePathChars.ReplaceAllString(host, "_"), "_")
+	return filepath.Join(dir, "glcli", name+suffix)
+}
+
+func NewSnapshotStore(path string) *SnapshotStore {
+	return &SnapshotStore{path: path}
+}
+
+// Load reads the snapshot. A missing file is not an error: it returns nil.
+func (s *SnapshotStore) Load() (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("parsing snapshot %s: %w", s.path, err)
+	}
+	log.Printf("[cache] snapshot loaded: %s (saved %s, %d pipelines, %d MRs, %d projects)",
+		s.path, snap.SavedAt.Format(time.RFC3339), len(snap.Pipelines), len(snap.MRs), len(snap.Projects))
+	return &snap, nil
+}
+
+// Save writes the snapshot atomically so a crash never leaves a torn file.
+func (s *SnapshotStore) Save(snap Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return err
+	}
+	log.Printf("[cache] snapshot saved: %s (%d bytes)", s.path, len(data))
+	return nil
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// viewedTTL is how long the viewed files of an MR nobody opens any more
+// are kept.
+const viewedTTL = 90 * 24 * time.Hour
+
+// ViewedStore remembers which files of an MR diff were marked as viewed.
+// Marks belong to one version of the MR, identified by its head commit:
+// after a push the files are up for review again.
+type ViewedStore struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]viewedEntry // loaded on first use
+}
+
+type viewedEntry struct {
+	Version   string    `json:"version"`
+	Files     []string  `json:"files"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ViewedPath returns the viewed files store for a GitLab instance, next
+// to its snapshot.
+func ViewedPath(gitlabURL string) string {
+	return cachePath(gitlabURL, ".viewed.json")
+}
+
+func NewViewedStore(path string) *ViewedStore {
+	return &ViewedStore{path: path}
+}
+
+func viewedKey(projectID, mrIID int) string { return fmt.Sprintf("%d!%d", projectID, mrIID) }
+
+// Load returns the MR version the marks were made on and the viewed file
+// paths. Nothing stored, or an unreadable file, gives empty values.
+func (s *ViewedStore) Load(projectID, mrIID int) (version string, files []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load()
+	e := s.entries[viewedKey(projectID, mrIID)]
+	return e.Version, e.Files
+}
+
+// Save replaces the viewed files of an MR, dropping marks made on an
+// earlier version, and writes the store atomically.
+func (s *ViewedStore) Save(projectID, mrIID int, version string, files []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load()
+	key := viewedKey(projectID, mrIID)
+	if len(files) == 0 {
+		delete(s.entries, key)
+	} else {
+		s.entries[key] = viewedEntry{Version: version, Files: files, UpdatedAt: time.Now()}
+	}
+	for k, e := range s.entries {
+		if time.Since(e.UpdatedAt) > viewedTTL {
+			delete(s.entries, k)
+		}
+	}
+	data, err := json.Marshal(s.entries)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, data)
+}
+
+func (s *ViewedStore) load() {
+	if s.entries != nil {
+		return
+	}
+	s.entries = make(map[string]viewedEntry)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &s.entries)
+	}
+	if err != nil {
+		log.Printf("[cache] viewed files ignored: %s: %v", s.path, err)
+		s.entries = make(map[string]viewedEntry)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -13,19 +15,76 @@ import (
 )
 
 type Config struct {
-	GitLabURL       string        `yaml:"gitlab_url"`
-	Token           string        `yaml:"token"`
-	Projects        []string      `yaml:"projects"`
-	RefreshInterval time.Duration `yaml:"refresh_interval"`
-	PipelineLimit   int           `yaml:"pipeline_limit"`
+	GitLabURL          string        `yaml:"gitlab_url,omitempty"`
+	Token              string        `yaml:"token,omitempty"`
+	TokenCommand       string        `yaml:"token_command,omitempty"`
+	Projects           []string      `yaml:"projects,omitempty"`
+	RefreshInterval    time.Duration `yaml:"refresh_interval"`
+	MaxRefreshInterval time.Duration `yaml:"max_refresh_interval,omitempty"`
+	PipelineLimit      int           `yaml:"pipeline_limit"`
+	PipelineBackend    string        `yaml:"pipeline_backend,omitempty"`
+	Concurrency        int           `yaml:"concurrency,omitempty"`
+	CurrentContext     string        `yaml:"current_context,omitempty"`
+	Contexts           []Context     `yaml:"contexts,omitempty"`
+	Notifications      Notifications `yaml:"notifications,omitempty"`
+	CollapsedFiles     []string      `yaml:"collapsed_files,omitempty"`
+
+	active      string // context whose values are in GitLabURL/Token/Projects
+	accessToken string // resolved from the credential sources
+	tokenSource string
+	detached    bool // token_command runs without the terminal
+}
+
+// Context is a named GitLab instance with its own credentials and
+// projects, like a kubectl context. When contexts are configured the
+// top-level gitlab_url/token/projects are ignored.
+type Context struct {
+	Name         string   `yaml:"name"`
+	GitLabURL    string   `yaml:"gitlab_url"`
+	Token        string   `yaml:"token,omitempty"`
+	TokenCommand string   `yaml:"token_command,omitempty"`
+	Projects     []string `yaml:"projects"`
+}
+
+// Notifications configures alerts the TUI sends when a watched pipeline
+// changes status. They are off while no backend is listed.
+type Notifications struct {
+	Backends []string     `yaml:"backends,omitempty"` // see NotifyBackends
+	Hook     string       `yaml:"hook,omitempty"`     // shell command for the hook backend
+	Rules    []NotifyRule `yaml:"rules,omitempty"`    // none means every pipeline
 }
 
+// NotifyRule selects pipelines to notify about. Empty lists match
+// everything; projects and refs accept globs such as "group/*".
+type NotifyRule struct {
+	Projects []string `yaml:"projects,omitempty"`
+	Refs     []string `yaml:"refs,omitempty"`
+	Authors  []string `yaml:"authors,omitempty"`
+	Sources  []string `yaml:"sources,omitempty"`  // e.g. merge_request_event
+	Statuses []string `yaml:"statuses,omitempty"` // statuses to notify on; default failed
+}
+
+// NotifyBackends are the supported notification backends: OSC 9 and OSC
+// 777 terminal notifications, desktop notifications (notify-send or
+// osascript), the terminal bell and a shell hook.
+var NotifyBackends = []string{"osc9", "osc777", "desktop", "bell", "hook"}
+
+// Pipeline backends. GraphQL fetches all projects in one request and
+// falls back to REST on error; REST issues requests per project.
+const (
+	BackendGraphQL = "graphql"
+	BackendREST    = "rest"
+)
+
 func DefaultPath() string {
 	home, _ := os.UserHomeDir()
 	return filepath.Join(home, ".glcli.yaml")
 }
 
-func Load(path string) (*Config, error) {
+// Load reads the config and switches to the named context, or to
+// current_context when name is empty. Only the token of that context is
+// resolved, so a broken token_command elsewhere doesn't get in the way.
+func Load(path, name string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -37,14 +96,224 @@ func Load(path string) (*Config, error) {
 	if cfg.RefreshInterval == 0 {
 		cfg.RefreshInterval = 10 * time.Second
 	}
+	if cfg.MaxRefreshInterval == 0 {
+		cfg.MaxRefreshInterval = 2 * time.Minute
+	}
+	if cfg.MaxRefreshInterval < cfg.RefreshInterval {
+		cfg.MaxRefreshInterval = cfg.RefreshInterval
+	}
 	if cfg.PipelineLimit == 0 {
 		cfg.PipelineLimit = 50
 	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 4
+	}
+	if cfg.PipelineBackend == "" {
+		cfg.PipelineBackend = BackendGraphQL
+	}
+	if cfg.PipelineBackend != BackendGraphQL && cfg.PipelineBackend != BackendREST {
+		return nil, fmt.Errorf("invalid pipeline_backend %q (want %q or %q)", cfg.PipelineBackend, BackendGraphQL, BackendREST)
+	}
+	if err := cfg.Notifications.validate(); err != nil {
+		return nil, fmt.Errorf("notifications: %w", err)
+	}
+	if err := validPatterns(cfg.CollapsedFiles); err != nil {
+		return nil, fmt.Errorf("collapsed_files: %w", err)
+	}
+	seen := make(map[string]bool, len(cfg.Contexts))
+	for _, ctx := range cfg.Contexts {
+		if ctx.Name == "" {
+			return nil, fmt.Errorf("context without a name")
+		}
+		if seen[ctx.Name] {
+			return nil, fmt.Errorf("duplicate context %q", ctx.Name)
+		}
+		seen[ctx.Name] = true
+	}
+	switch {
+	case name != "":
+		if err := cfg.UseContext(name); err != nil {
+			return nil, err
+		}
+	case len(cfg.Contexts) > 0:
+		current := cfg.CurrentContext
+		if current == "" {
+			current = cfg.Contexts[0].Name
+		}
+		if err := cfg.UseContext(current); err != nil {
+			return nil, fmt.Errorf("current_context: %w", err)
+		}
+	default:
+		if err := cfg.resolveToken(); err != nil {
+			return nil, err
+		}
+	}
 	return &cfg, nil
 }
 
+func (n Notifications) validate() error {
+	for _, b := range n.Backends {
+		if !slices.Contains(NotifyBackends, b) {
+			return fmt.Errorf("unknown backend %q (want one of %s)", b, strings.Join(NotifyBackends, ", "))
+		}
+		if b == "hook" && n.Hook == "" {
+			return fmt.Errorf("the hook backend needs a hook command")
+		}
+	}
+	for _, r := range n.Rules {
+		for _, pattern := range append(r.Projects, r.Refs...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid pattern %q", pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// UsesAuthors reports whether a rule filters on who started the pipeline.
+func (n Notifications) UsesAuthors() bool {
+	for _, r := range n.Rules {
+		if len(r.Authors) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Collapsed reports whether a changed file matches collapsed_files. A
+// pattern without a slash matches the file name or any directory on its
+// path, e.g. "vendor" or "*.pb.go"; one with a slash matches the path or a
+// leading part of it, e.g. "web/dist" or "api/*/gen".
+func (c *Config) Collapsed(file string) bool {
+	parts := strings.Split(file, "/")
+	for _, pattern := range c.CollapsedFiles {
+		for i := range parts {
+			name := parts[i]
+			if strings.Contains(pattern, "/") {
+				name = strings.Join(parts[:i+1], "/")
+			}
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func validPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// ActiveContext returns the name of the context in use, or "" when the
+// config has no contexts.
+func (c *Config) ActiveContext() string { return c.active }
+
+// ContextNames lists the configured contexts in file order.
+func (c *Config) ContextNames() []string {
+	names := make([]string, len(c.Contexts))
+	for i, ctx := range c.Contexts {
+		names[i] = ctx.Name
+	}
+	return names
+}
+
+// UseContext switches GitLabURL, Token and Projects to the named context.
+// Changes made to the previous context (e.g. added projects) are kept.
+func (c *Config) UseContext(name string) error {
+	i := c.contextIndex(name)
+	if i < 0 {
+		return fmt.Errorf("unknown context %q (available: %s)", name, strings.Join(c.ContextNames(), ", "))
+	}
+	ctx := c.Contexts[i]
+	next := *c
+	next.GitLabURL = ctx.GitLabURL
+	next.Token = ctx.Token
+	next.TokenCommand = ctx.TokenCommand
+	next.Projects = ctx.Projects
+	next.active = name
+	if err := next.resolveToken(); err != nil {
+		return fmt.Errorf("context %q: %w", name, err)
+	}
+	c.AdoptContext(&next)
+	return nil
+}
+
+// AdoptContext makes the active context of other, typically a Clone that
+// was switched with UseContext off the UI goroutine, the active one of c.
+// Settings outside the context keep the values of c.
+func (c *Config) AdoptContext(other *Config) {
+	c.syncContext()
+	c.GitLabURL = other.GitLabURL
+	c.Token = other.Token
+	c.TokenCommand = other.TokenCommand
+	c.Projects = append([]string(nil), other.Projects...)
+	c.accessToken = other.accessToken
+	c.tokenSource = other.tokenSource
+	c.active = other.active
+}
+
+// Clone returns a copy of c that can be changed, e.g. by UseContext,
+// without touching c.
+func (c *Config) Clone() *Config {
+	cp := *c
+	cp.Projects = slices.Clone(c.Projects)
+	cp.Contexts = slices.Clone(c.Contexts)
+	cp.CollapsedFiles = slices.Clone(c.CollapsedFiles)
+	return &cp
+}
+
+// Detach keeps token_command helpers off the terminal from now on, for
+// while a full-screen UI reads it.
+func (c *Config) Detach() { c.detached = true }
+
+// ForContext returns a copy of the config switched to the named context,
+// leaving c untouched. An empty name means the active context.
+func (c *Config) ForContext(name string) (*Config, error) {
+	cp := *c
+	cp.Projects = append([]string(nil), c.Projects...)
+	cp.Contexts = append([]Context(nil), c.Contexts...)
+	cp.syncContext()
+	if name == "" || name == c.active {
+		return &cp, nil
+	}
+	if err := cp.UseContext(name); err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+func (c *Config) contextIndex(name string) int {
+	for i, ctx := range c.Contexts {
+		if ctx.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// syncContext writes the active values back into their context entry.
+func (c *Config) syncContext() {
+	if i := c.contextIndex(c.active); i >= 0 {
+		c.Contexts[i].GitLabURL = c.GitLabURL
+		c.Contexts[i].Token = c.Token
+		c.Contexts[i].TokenCommand = c.TokenCommand
+		c.Contexts[i].Projects = append([]string(nil), c.Projects...)
+	}
+}
+
 func (c *Config) Save(path string) error {
-	data, err := yaml.Marshal(c)
+	c.syncContext()
+	out := *c
+	if len(out.Contexts) > 0 {
+		// the active values live in their context entry
+		out.GitLabURL, out.Token, out.TokenCommand, out.Projects = "", "", "", nil
+	}
+	data, err := yaml.Marshal(&out)
 	if err != nil {
 		return err
 	}
@@ -54,16 +323,32 @@ func (c *Config) Save(path string) error {
 func RunSetupWizard() (*Config, error) {
 	reader := bufio.NewReader(os.Stdin)
 	cfg := &Config{
-		RefreshInterval: 10 * time.Second,
+		RefreshInterval:    10 * time.Second,
+		MaxRefreshInterval: 2 * time.Minute,
+		PipelineBackend:    BackendGraphQL,
 	}
 
 	fmt.Print("GitLab URL (e.g. https://gitlab.example.com): ")
 	url, _ := reader.ReadString('\n')
 	cfg.GitLabURL = strings.TrimSpace(url)
 
-	fmt.Print("Personal Access Token: ")
-	token, _ := reader.ReadString('\n')
-	cfg.Token = strings.TrimSpace(token)
+	// Prefer a token that already lives outside the config file
+	if err := cfg.resolveToken(); err == nil {
+		fmt.Printf("Using token from %s\n", cfg.TokenSource())
+	} else {
+		// A typed token would have to be stored somewhere; leave that to a
+		// password manager or the environment
+		fmt.Println("No token found in GITLAB_TOKEN or glab's config.")
+		fmt.Print("Command that prints the token (e.g. pass show gitlab/token): ")
+		command, _ := reader.ReadString('\n')
+		cfg.TokenCommand = strings.TrimSpace(command)
+		if cfg.TokenCommand == "" {
+			return nil, fmt.Errorf("no GitLab token: export GITLAB_TOKEN, log in with glab, or enter a token command")
+		}
+		if err := cfg.resolveToken(); err != nil {
+			return nil, err
+		}
+	}
 
 	fmt.Print("Projects (comma-separated, e.g. group/project1,group/project2): ")
 	projects, _ := reader.ReadString('\n')
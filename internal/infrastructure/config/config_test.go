@@ -0,0 +1,119 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadResolvesOnlySelectedContext(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := `current_context: broken
+contexts:
+  - name: broken
+    gitlab_url: https://gitlab.example.com
+    token_command: "false"
+  - name: other
+    gitlab_url: https://gitlab.com
+    token: other-token
+`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path, ""); err == nil {
+		t.Fatal("expected current_context's token_command to fail")
+	}
+	cfg, err := Load(path, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ActiveContext() != "other" || cfg.AccessToken() != "other-token" {
+		t.Errorf("got context %q with token %q", cfg.ActiveContext(), cfg.AccessToken())
+	}
+	if _, err := Load(path, "missing"); err == nil {
+		t.Fatal("expected an unknown context to fail")
+	}
+}
+
+// withStdin runs f with os.Stdin reading input.
+func withStdin(t *testing.T, input string, f func()) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteString(input); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	orig := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = orig; r.Close() }()
+	f()
+}
+
+func TestSetupWizardNeverStoresToken(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GLAB_CONFIG_DIR", t.TempDir())
+	t.Setenv("GITLAB_TOKEN", "")
+
+	withStdin(t, "https://gitlab.example.com\n\n", func() {
+		if _, err := RunSetupWizard(); err == nil {
+			t.Error("expected the wizard to fail without any token source")
+		}
+	})
+
+	var cfg *Config
+	withStdin(t, "https://gitlab.example.com\necho from-command\ngroup/app\n", func() {
+		var err error
+		if cfg, err = RunSetupWizard(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if cfg.Token != "" || cfg.TokenCommand != "echo from-command" || cfg.AccessToken() != "from-command" {
+		t.Errorf("got token %q, command %q, resolved %q", cfg.Token, cfg.TokenCommand, cfg.AccessToken())
+	}
+	entries, err := os.ReadDir(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != ".glcli.yaml" {
+		t.Errorf("wizard wrote %v, want only the config", entries)
+	}
+}
+
+func TestUseContextOnCloneThenAdopt(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "")
+	cfg := &Config{
+		CurrentContext: "a",
+		Contexts: []Context{
+			{Name: "a", GitLabURL: "https://a.example.com", Token: "token-a", Projects: []string{"a/app"}},
+			{Name: "b", GitLabURL: "https://b.example.com", TokenCommand: "echo token-b", Projects: []string{"b/app"}},
+		},
+	}
+	if err := cfg.UseContext("a"); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Detach()
+
+	next := cfg.Clone()
+	if err := next.UseContext("b"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ActiveContext() != "a" || cfg.AccessToken() != "token-a" || cfg.Projects[0] != "a/app" {
+		t.Fatalf("switching the clone changed the original to %q with %v", cfg.ActiveContext(), cfg.Projects)
+	}
+
+	// settings changed meanwhile survive the switch
+	cfg.PipelineLimit = 42
+	cfg.AdoptContext(next)
+	if cfg.ActiveContext() != "b" || cfg.GitLabURL != "https://b.example.com" || cfg.AccessToken() != "token-b" || cfg.Projects[0] != "b/app" {
+		t.Errorf("adopted %q at %s with token %q and %v", cfg.ActiveContext(), cfg.GitLabURL, cfg.AccessToken(), cfg.Projects)
+	}
+	if cfg.PipelineLimit != 42 {
+		t.Errorf("pipeline limit reset to %d", cfg.PipelineLimit)
+	}
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+// CredentialSource looks up a GitLab token. Token returns "" without an
+// error when the source simply has nothing for the instance.
+type CredentialSource interface {
+	Name() string
+	Token(gitlabURL string) (string, error)
+}
+
+// tokenCommandTimeout bounds how long a token_command may run; helpers
+// like pass may wait for a GPG pinentry.
+const tokenCommandTimeout = time.Minute
+
+// EnvSource reads the GITLAB_TOKEN environment variable.
+type EnvSource struct{}
+
+func (EnvSource) Name() string { return "GITLAB_TOKEN" }
+
+func (EnvSource) Token(string) (string, error) {
+	return strings.TrimSpace(os.Getenv("GITLAB_TOKEN")), nil
+}
+
+// CommandSource runs a shell command and uses the first line of its output,
+// e.g. "pass show gitlab/token".
+type CommandSource struct {
+	Command string
+	// Detached keeps the command off the terminal while a full-screen UI
+	// owns it: it gets no stdin and its stderr ends up in the error.
+	Detached bool
+}
+
+func (s CommandSource) Name() string { return "token_command" }
+
+func (s CommandSource) Token(string) (string, error) {
+	if s.Command == "" {
+		return "", nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), tokenCommandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", s.Command)
+	// Let helpers prompt on the terminal, but never hand them stdin when it
+	// carries something else (the MCP server speaks its protocol over it)
+	if !s.Detached {
+		if term.IsTerminal(os.Stdin.Fd()) {
+			cmd.Stdin = os.Stdin
+		}
+		cmd.Stderr = os.Stderr
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) && len(exit.Stderr) > 0 {
+			return "", fmt.Errorf("token_command %q: %w: %s", s.Command, err, strings.TrimSpace(string(exit.Stderr)))
+		}
+		return "", fmt.Errorf("token_command %q: %w", s.Command, err)
+	}
+	line, _, _ := strings.Cut(string(out), "\n")
+	token := strings.TrimSpace(line)
+	if token == "" {
+		return "", fmt.Errorf("token_command %q printed nothing", s.Command)
+	}
+	return token, nil
+}
+
+// StaticSource is a token written directly in the config file.
+type StaticSource struct {
+	Value string
+}
+
+func (s StaticSource) Name() string { return "token" }
+
+func (s StaticSource) Token(string) (string, error) { return s.Value, nil }
+
+// GlabSource reads the token the glab CLI stored for the instance's host.
+// Tokens glab keeps in the system keyring are not visible here.
+type GlabSource struct{}
+
+func (GlabSource) Name() string { return "glab config" }
+
+func (GlabSource) Token(gitlabURL string) (string, error) {
+	u, err := url.Parse(gitlabURL)
+	if err != nil || u.Host == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(glabConfigPath())
+	if err != nil {
+		return "", nil
+	}
+	var cfg struct {
+		Hosts map[string]struct {
+			Token string `yaml:"token"`
+		} `yaml:"hosts"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return "", fmt.Errorf("parsing glab config: %w", err)
+	}
+	return cfg.Hosts[u.Host].Token, nil
+}
+
+func glabConfigPath() string {
+	if dir := os.Getenv("GLAB_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "config.yml")
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "glab-cli", "config.yml")
+}
+
+// credentialSources returns the sources in order of precedence: what the
+// config (or the context) sets explicitly, token_command then token, comes
+// before the environment, so that a GITLAB_TOKEN exported for one instance
+// is never sent to another that has its own credentials. glab's config is
+// the last resort.
+func credentialSources(token, command string, detached bool) []CredentialSource {
+	return []CredentialSource{
+		CommandSource{Command: command, Detached: detached},
+		StaticSource{Value: token},
+		EnvSource{},
+		GlabSource{},
+	}
+}
+
+// resolveToken looks up the token for the active values of c.
+func (c *Config) resolveToken() error {
+	for _, src := range credentialSources(c.Token, c.TokenCommand, c.detached) {
+		token, err := src.Token(c.GitLabURL)
+		if err != nil {
+			return err
+		}
+		if token != "" {
+			c.accessToken = token
+			c.tokenSource = src.Name()
+			return nil
+		}
+	}
+	return fmt.Errorf("no GitLab token for %s: set GITLAB_TOKEN, token_command in the config, or log in with glab", c.GitLabURL)
+}
+
+// AccessToken returns the resolved token for the active GitLab instance.
+func (c *Config) AccessToken() string { return c.accessToken }
+
+// TokenSource names where the active token came from, for diagnostics.
+func (c *Config) TokenSource() string { return c.tokenSource }
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveTokenPrecedence(t *testing.T) {
+	glabDir := t.TempDir()
+	glabConfig := "hosts:\n  gitlab.example.com:\n    token: from-glab\n"
+	if err := os.WriteFile(filepath.Join(glabDir, "config.yml"), []byte(glabConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GLAB_CONFIG_DIR", glabDir)
+
+	tests := []struct {
+		name       string
+		command    string
+		token      string
+		env        string
+		wantToken  string
+		wantSource string
+	}{
+		{"command wins over everything", "echo from-command", "from-file", "from-env", "from-command", "token_command"},
+		{"file token wins over the environment", "", "from-file", "from-env", "from-file", "token"},
+		{"environment when nothing is configured", "", "", "from-env", "from-env", "GITLAB_TOKEN"},
+		{"glab as the last resort", "", "", "", "from-glab", "glab config"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITLAB_TOKEN", tt.env)
+			cfg := Config{GitLabURL: "https://gitlab.example.com", Token: tt.token, TokenCommand: tt.command}
+			if err := cfg.resolveToken(); err != nil {
+				t.Fatal(err)
+			}
+			if cfg.AccessToken() != tt.wantToken || cfg.TokenSource() != tt.wantSource {
+				t.Errorf("got %q from %s, want %q from %s", cfg.AccessToken(), cfg.TokenSource(), tt.wantToken, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestResolveTokenMissing(t *testing.T) {
+	t.Setenv("GLAB_CONFIG_DIR", t.TempDir())
+	t.Setenv("GITLAB_TOKEN", "")
+	cfg := Config{GitLabURL: "https://gitlab.example.com"}
+	if err := cfg.resolveToken(); err == nil {
+		t.Fatal("expected an error without any token")
+	}
+}
+
+func TestDetachedCommandReportsStderr(t *testing.T) {
+	_, err := CommandSource{Command: "echo locked >&2; exit 1", Detached: true}.Token("")
+	if err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Errorf("got %v, want the command's stderr in the error", err)
+	}
+}
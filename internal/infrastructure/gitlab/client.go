@@ -6,16 +6,24 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/bearlogin/gitlab-awesome-cli/internal/infrastructure/cache"
 	gogitlab "github.com/xanzy/go-gitlab"
 )
 
-func NewClient(baseURL, token string) (*gogitlab.Client, error) {
+// NewClient creates a REST client. The limiter is shared with the GraphQL
+// client so both stay within the same GitLab rate limit budget; GET
+// requests are revalidated against etags instead of re-downloaded.
+func NewClient(baseURL, token string, limiter *RateLimiter, etags *cache.ETagCache) (*gogitlab.Client, error) {
 	log.Printf("[gitlab] creating client: url=%s", baseURL)
-	httpClient := &http.Client{Timeout: 15 * time.Second}
+	httpClient := &http.Client{
+		Timeout:   15 * time.Second,
+		Transport: etags.Transport(limiter.Transport(nil)),
+	}
 	client, err := gogitlab.NewClient(token,
 		gogitlab.WithBaseURL(baseURL+"/api/v4"),
 		gogitlab.WithHTTPClient(httpClient),
 		gogitlab.WithCustomRetryMax(2),
+		gogitlab.WithCustomLimiter(limiter),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("creating gitlab client: %w", err)
@@ -16,13 +16,15 @@ func NewCommitRepo(client *gogitlab.Client) *CommitRepo {
 	return &CommitRepo{client: client}
 }
 
-func (r *CommitRepo) ListByRef(ctx context.Context, projectID int, ref string) ([]entity.Commit, error) {
-	log.Printf("[gitlab] ListCommits: project=%d ref=%s", projectID, ref)
-	opts := &gogitlab.ListCommitsOptions{
-		ListOptions: gogitlab.ListOptions{PerPage: 50},
-		RefName:     gogitlab.Ptr(ref),
-	}
-	commits, _, err := r.client.Commits.ListCommits(projectID, opts, gogitlab.WithContext(ctx))
+// ListByRef returns the newest commits on ref, at most limit of them; 0
+// means all.
+func (r *CommitRepo) ListByRef(ctx context.Context, projectID int, ref string, limit int) ([]entity.Commit, error) {
+	log.Printf("[gitlab] ListCommits: project=%d ref=%s limit=%d", projectID, ref, limit)
+	opts := &gogitlab.ListCommitsOptions{RefName: gogitlab.Ptr(ref)}
+	commits, err := paginate(paging{max: limit}, func(lo gogitlab.ListOptions, extra ...gogitlab.RequestOptionFunc) ([]*gogitlab.Commit, *gogitlab.Response, error) {
+		opts.ListOptions = lo
+		return r.client.Commits.ListCommits(projectID, opts, append(extra, gogitlab.WithContext(ctx))...)
+	})
 	if err != nil {
 		log.Printf("[gitlab] ListCommits: error: %v", err)
 		return nil, err
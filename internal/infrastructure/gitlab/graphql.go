@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,19 +18,31 @@ import (
 )
 
 type GraphQLClient struct {
-	url    string
-	token  string
-	client *http.Client
+	baseURL string
+	url     string
+	token   string
+	client  *http.Client
+	limiter *RateLimiter
 }
 
-func NewGraphQLClient(baseURL, token string) *GraphQLClient {
+func NewGraphQLClient(baseURL, token string, limiter *RateLimiter) *GraphQLClient {
+	baseURL = strings.TrimRight(baseURL, "/")
 	return &GraphQLClient{
-		url:    strings.TrimRight(baseURL, "/") + "/api/graphql",
-		token:  token,
-		client: &http.Client{Timeout: 30 * time.Second},
+		baseURL: baseURL,
+		url:     baseURL + "/api/graphql",
+		token:   token,
+		client: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: limiter.Transport(nil),
+		},
+		limiter: limiter,
 	}
 }
 
+// errProjectNotFound is reported for projects GraphQL resolves to null,
+// which GitLab does both for missing projects and for ones the token can't see.
+var errProjectNotFound = errors.New("project not found or access denied")
+
 type gqlRequest struct {
 	Query     string         `json:"query"`
 	Variables map[string]any `json:"variables,omitempty"`
@@ -45,21 +59,36 @@ type gqlPipelineNode struct {
 	IID       string `json:"iid"`
 	ID        string `json:"id"`
 	Ref       string `json:"ref"`
+	SHA       string `json:"sha"`
+	Source    string `json:"source"`
+	Path      string `json:"path"`
 	Status    string `json:"status"`
 	CreatedAt string `json:"createdAt"`
 	Duration  *int   `json:"duration"`
+	User      *struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Jobs struct {
+		Count int `json:"count"`
+	} `json:"jobs"`
 }
 
 type gqlPipelineEdges struct {
 	Nodes []gqlPipelineNode `json:"nodes"`
 }
 
+// gqlPageInfo is the cursor of a paginated GraphQL connection.
+type gqlPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
 type gqlProjectResult struct {
-	ID            string           `json:"id"`
-	Name          string           `json:"name"`
-	FullPath      string           `json:"fullPath"`
-	WebURL        string           `json:"webUrl"`
-	Pipelines     gqlPipelineEdges `json:"pipelines"`
+	ID        string           `json:"id"`
+	Name      string           `json:"name"`
+	FullPath  string           `json:"fullPath"`
+	WebURL    string           `json:"webUrl"`
+	Pipelines gqlPipelineEdges `json:"pipelines"`
 }
 
 func (c *GraphQLClient) do(ctx context.Context, req gqlRequest) (*gqlResponse, error) {
@@ -75,6 +104,9 @@ func (c *GraphQLClient) do(ctx context.Context, req gqlRequest) (*gqlResponse, e
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Authorization", "Bearer "+c.token)
 
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
 	resp, err := c.client.Do(httpReq)
 	if err != nil {
 		return nil, err
@@ -107,6 +139,7 @@ func (c *GraphQLClient) do(ctx context.Context, req gqlRequest) (*gqlResponse, e
 }
 
 // LoadAllPipelines fetches pipelines for all projects in a single GraphQL query.
+// Projects that resolve to null are reported through an *entity.PartialError.
 func (c *GraphQLClient) LoadAllPipelines(ctx context.Context, projectPaths []string, perProject int) ([]entity.Pipeline, error) {
 	if len(projectPaths) == 0 {
 		return nil, nil
@@ -119,7 +152,11 @@ func (c *GraphQLClient) LoadAllPipelines(ctx context.Context, projectPaths []str
 			`p%d: project(fullPath: %q) {
 				id name fullPath webUrl
 				pipelines(first: %d, sort: CREATED_DESC) {
-					nodes { iid id ref status createdAt duration }
+					nodes {
+						iid id ref sha source path status createdAt duration
+						user { username }
+						jobs { count }
+					}
 				}
 			}`, i, path, perProject))
 	}
@@ -137,10 +174,12 @@ func (c *GraphQLClient) LoadAllPipelines(ctx context.Context, projectPaths []str
 	}
 
 	var all []entity.Pipeline
+	var failed []entity.ProjectError
 	for i, path := range projectPaths {
 		key := fmt.Sprintf("p%d", i)
 		raw, ok := data[key]
 		if !ok || string(raw) == "null" {
+			failed = append(failed, entity.ProjectError{Path: path, Err: errProjectNotFound})
 			continue
 		}
 
@@ -157,16 +196,28 @@ func (c *GraphQLClient) LoadAllPipelines(ctx context.Context, projectPaths []str
 			if node.Duration != nil {
 				dur = *node.Duration
 			}
+			iid, _ := strconv.Atoi(node.IID)
 
-			all = append(all, entity.Pipeline{
+			pl := entity.Pipeline{
 				ID:          extractNumericID(node.ID),
+				IID:         iid,
 				ProjectID:   projectID,
 				ProjectPath: proj.FullPath,
 				Ref:         node.Ref,
+				SHA:         node.SHA,
+				Source:      strings.ToLower(node.Source),
 				Status:      mapGQLStatus(node.Status),
 				CreatedAt:   createdAt,
 				Duration:    dur,
-			})
+				JobCount:    node.Jobs.Count,
+			}
+			if node.User != nil {
+				pl.Author = node.User.Username
+			}
+			if node.Path != "" {
+				pl.WebURL = c.baseURL + node.Path
+			}
+			all = append(all, pl)
 		}
 	}
 
@@ -174,7 +225,135 @@ func (c *GraphQLClient) LoadAllPipelines(ctx context.Context, projectPaths []str
 		return all[i].CreatedAt.After(all[j].CreatedAt)
 	})
 
-	return all, nil
+	return all, entity.NewProjectLoadError(failed, len(projectPaths))
+}
+
+// jobNeedsQuery lists the jobs of one pipeline with their needs: entries,
+// which the REST jobs API doesn't return.
+const jobNeedsQuery = `query($path: ID!, $iid: ID!, $after: String) {
+	project(fullPath: $path) {
+		pipeline(iid: $iid) {
+			jobs(first: 100, after: $after) {
+				nodes {
+					name
+					needs { nodes { name } }
+				}
+				pageInfo { hasNextPage endCursor }
+			}
+		}
+	}
+}`
+
+type gqlJobNeedsResult struct {
+	Project *struct {
+		Pipeline *struct {
+			Jobs struct {
+				Nodes []struct {
+					Name  string `json:"name"`
+					Needs struct {
+						Nodes []struct {
+							Name string `json:"name"`
+						} `json:"nodes"`
+					} `json:"needs"`
+				} `json:"nodes"`
+				PageInfo gqlPageInfo `json:"pageInfo"`
+			} `json:"jobs"`
+		} `json:"pipeline"`
+	} `json:"project"`
+}
+
+// JobNeeds maps each job of a pipeline that uses needs: to the names of the
+// jobs it needs.
+func (c *GraphQLClient) JobNeeds(ctx context.Context, projectPath string, pipelineIID int) (map[string][]string, error) {
+	needs := make(map[string][]string)
+	vars := map[string]any{"path": projectPath, "iid": strconv.Itoa(pipelineIID)}
+	for {
+		resp, err := c.do(ctx, gqlRequest{Query: jobNeedsQuery, Variables: vars})
+		if err != nil {
+			return nil, err
+		}
+		var data gqlJobNeedsResult
+		if err := json.Unmarshal(resp.Data, &data); err != nil {
+			return nil, fmt.Errorf("graphql: unmarshal: %w", err)
+		}
+		if data.Project == nil {
+			return nil, errProjectNotFound
+		}
+		if data.Project.Pipeline == nil {
+			return needs, nil
+		}
+		jobs := data.Project.Pipeline.Jobs
+		for _, job := range jobs.Nodes {
+			for _, n := range job.Needs.Nodes {
+				needs[job.Name] = append(needs[job.Name], n.Name)
+			}
+		}
+		if !jobs.PageInfo.HasNextPage || jobs.PageInfo.EndCursor == "" {
+			return needs, nil
+		}
+		vars["after"] = jobs.PageInfo.EndCursor
+	}
+}
+
+// unresolvedThreadsQuery counts review threads of a set of merge requests;
+// the REST list doesn't say how many are still open.
+const unresolvedThreadsQuery = `query($path: ID!, $iids: [String!], $after: String) {
+	project(fullPath: $path) {
+		mergeRequests(iids: $iids, first: 100, after: $after) {
+			nodes {
+				iid
+				resolvableDiscussionsCount
+				resolvedDiscussionsCount
+			}
+			pageInfo { hasNextPage endCursor }
+		}
+	}
+}`
+
+type gqlUnresolvedThreadsResult struct {
+	Project *struct {
+		MergeRequests struct {
+			Nodes []struct {
+				IID        string `json:"iid"`
+				Resolvable int    `json:"resolvableDiscussionsCount"`
+				Resolved   int    `json:"resolvedDiscussionsCount"`
+			} `json:"nodes"`
+			PageInfo gqlPageInfo `json:"pageInfo"`
+		} `json:"mergeRequests"`
+	} `json:"project"`
+}
+
+// UnresolvedThreads maps merge request IIDs of a project to the number of
+// their threads that still need resolving.
+func (c *GraphQLClient) UnresolvedThreads(ctx context.Context, projectPath string, iids []int) (map[int]int, error) {
+	ids := make([]string, len(iids))
+	for i, iid := range iids {
+		ids[i] = strconv.Itoa(iid)
+	}
+	counts := make(map[int]int, len(iids))
+	vars := map[string]any{"path": projectPath, "iids": ids}
+	for {
+		resp, err := c.do(ctx, gqlRequest{Query: unresolvedThreadsQuery, Variables: vars})
+		if err != nil {
+			return nil, err
+		}
+		var data gqlUnresolvedThreadsResult
+		if err := json.Unmarshal(resp.Data, &data); err != nil {
+			return nil, fmt.Errorf("graphql: unmarshal: %w", err)
+		}
+		if data.Project == nil {
+			return nil, errProjectNotFound
+		}
+		mrs := data.Project.MergeRequests
+		for _, mr := range mrs.Nodes {
+			iid, _ := strconv.Atoi(mr.IID)
+			counts[iid] = mr.Resolvable - mr.Resolved
+		}
+		if !mrs.PageInfo.HasNextPage || mrs.PageInfo.EndCursor == "" {
+			return counts, nil
+		}
+		vars["after"] = mrs.PageInfo.EndCursor
+	}
 }
 
 // mapGQLStatus converts GraphQL pipeline status (UPPERCASE) to our domain status (lowercase).
@@ -0,0 +1,59 @@
+package gitlab
+
+import (
+	"context"
+	"log"
+
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+)
+
+// GraphQLPipelineRepo loads pipelines for all projects in one GraphQL query
+// and falls back to the REST repository when the query fails (old GitLab
+// versions, disabled GraphQL, etc.). Jobs are always listed via REST.
+type GraphQLPipelineRepo struct {
+	gql  *GraphQLClient
+	rest *PipelineRepo
+}
+
+func NewGraphQLPipelineRepo(gql *GraphQLClient, rest *PipelineRepo) *GraphQLPipelineRepo {
+	return &GraphQLPipelineRepo{gql: gql, rest: rest}
+}
+
+func (r *GraphQLPipelineRepo) ListJobs(ctx context.Context, projectID, pipelineID int) ([]entity.Job, error) {
+	return r.rest.ListJobs(ctx, projectID, pipelineID)
+}
+
+func (r *GraphQLPipelineRepo) Get(ctx context.Context, projectID, pipelineID int) (*entity.Pipeline, error) {
+	return r.rest.Get(ctx, projectID, pipelineID)
+}
+
+func (r *GraphQLPipelineRepo) Latest(ctx context.Context, projectID int, ref, sha string) (*entity.Pipeline, error) {
+	return r.rest.Latest(ctx, projectID, ref, sha)
+}
+
+func (r *GraphQLPipelineRepo) Create(ctx context.Context, projectID int, ref string, variables []entity.PipelineVariable) (*entity.Pipeline, error) {
+	return r.rest.Create(ctx, projectID, ref, variables)
+}
+
+func (r *GraphQLPipelineRepo) Retry(ctx context.Context, projectID, pipelineID int) (*entity.Pipeline, error) {
+	return r.rest.Retry(ctx, projectID, pipelineID)
+}
+
+func (r *GraphQLPipelineRepo) Cancel(ctx context.Context, projectID, pipelineID int) (*entity.Pipeline, error) {
+	return r.rest.Cancel(ctx, projectID, pipelineID)
+}
+
+func (r *GraphQLPipelineRepo) JobNeeds(ctx context.Context, projectPath string, pipelineIID int) (map[string][]string, error) {
+	return r.gql.JobNeeds(ctx, projectPath, pipelineIID)
+}
+
+func (r *GraphQLPipelineRepo) LoadAllPipelines(ctx context.Context, projectPaths []string, perProject int) ([]entity.Pipeline, error) {
+	log.Printf("[graphql] LoadAllPipelines: %d projects perProject=%d", len(projectPaths), perProject)
+	pls, err := r.gql.LoadAllPipelines(ctx, projectPaths, perProject)
+	if _, fatal := entity.AsPartial(err); fatal != nil {
+		log.Printf("[graphql] LoadAllPipelines: error, falling back to REST: %v", err)
+		return r.rest.LoadAllPipelines(ctx, projectPaths, perProject)
+	}
+	log.Printf("[graphql] LoadAllPipelines: got %d pipelines", len(pls))
+	return pls, err
+}
@@ -0,0 +1,79 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// graphQLPages serves one canned data payload per request and records the
+// variables of every request.
+func graphQLPages(t *testing.T, pages []string) (*GraphQLClient, *[]map[string]any) {
+	t.Helper()
+	var seen []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if len(seen) >= len(pages) {
+			t.Errorf("unexpected request %d with %v", len(seen)+1, req.Variables)
+			http.Error(w, "no more pages", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"data":` + pages[len(seen)] + `}`))
+		seen = append(seen, req.Variables)
+	}))
+	t.Cleanup(srv.Close)
+	return NewGraphQLClient(srv.URL, "token", NewRateLimiter()), &seen
+}
+
+func TestJobNeedsFollowsCursor(t *testing.T) {
+	c, seen := graphQLPages(t, []string{
+		`{"project":{"pipeline":{"jobs":{
+			"nodes":[{"name":"test","needs":{"nodes":[{"name":"build"}]}}],
+			"pageInfo":{"hasNextPage":true,"endCursor":"c1"}}}}}`,
+		`{"project":{"pipeline":{"jobs":{
+			"nodes":[{"name":"deploy","needs":{"nodes":[{"name":"test"},{"name":"lint"}]}}],
+			"pageInfo":{"hasNextPage":false,"endCursor":"c2"}}}}}`,
+	})
+
+	needs, err := c.JobNeeds(context.Background(), "group/project", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{"test": {"build"}, "deploy": {"test", "lint"}}
+	if !reflect.DeepEqual(needs, want) {
+		t.Errorf("got %v, want %v", needs, want)
+	}
+	if len(*seen) != 2 || (*seen)[0]["after"] != nil || (*seen)[1]["after"] != "c1" {
+		t.Errorf("requests: %v", *seen)
+	}
+}
+
+func TestUnresolvedThreadsFollowsCursor(t *testing.T) {
+	c, seen := graphQLPages(t, []string{
+		`{"project":{"mergeRequests":{
+			"nodes":[{"iid":"1","resolvableDiscussionsCount":3,"resolvedDiscussionsCount":1}],
+			"pageInfo":{"hasNextPage":true,"endCursor":"c1"}}}}`,
+		`{"project":{"mergeRequests":{
+			"nodes":[{"iid":"150","resolvableDiscussionsCount":2,"resolvedDiscussionsCount":2}],
+			"pageInfo":{"hasNextPage":false,"endCursor":""}}}}`,
+	})
+
+	counts, err := c.UnresolvedThreads(context.Background(), "group/project", []int{1, 150})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[int]int{1: 2, 150: 0}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("got %v, want %v", counts, want)
+	}
+	if len(*seen) != 2 || (*seen)[1]["after"] != "c1" {
+		t.Errorf("requests: %v", *seen)
+	}
+}
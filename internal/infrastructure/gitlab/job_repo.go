@@ -3,8 +3,10 @@ package gitlab
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"log"
+	"net/http"
 
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/valueobject"
@@ -52,6 +54,16 @@ func (r *JobRepo) Cancel(ctx context.Context, projectID, jobID int) (*entity.Job
 	return mapJob(j, projectID), nil
 }
 
+func (r *JobRepo) Get(ctx context.Context, projectID, jobID int) (*entity.Job, error) {
+	log.Printf("[gitlab] GetJob: project=%d job=%d", projectID, jobID)
+	j, _, err := r.client.Jobs.GetJob(projectID, jobID, gogitlab.WithContext(ctx))
+	if err != nil {
+		log.Printf("[gitlab] GetJob: error: %v", err)
+		return nil, err
+	}
+	return mapJob(j, projectID), nil
+}
+
 func (r *JobRepo) GetLog(ctx context.Context, projectID, jobID int) (io.ReadCloser, error) {
 	log.Printf("[gitlab] GetTraceFile: project=%d job=%d", projectID, jobID)
 	trace, _, err := r.client.Jobs.GetTraceFile(projectID, jobID, gogitlab.WithContext(ctx))
@@ -66,6 +78,38 @@ func (r *JobRepo) GetLog(ctx context.Context, projectID, jobID int) (io.ReadClos
 	return io.NopCloser(bytes.NewReader(data)), nil
 }
 
+// GetLogFrom returns the part of the job trace starting at offset.
+// A Range header is sent so that GitLab can answer with only the new bytes;
+// if the server ignores it and returns the whole trace, the prefix is cut locally.
+func (r *JobRepo) GetLogFrom(ctx context.Context, projectID, jobID int, offset int64) ([]byte, error) {
+	log.Printf("[gitlab] GetTraceFile: project=%d job=%d offset=%d", projectID, jobID, offset)
+	opts := []gogitlab.RequestOptionFunc{gogitlab.WithContext(ctx)}
+	if offset > 0 {
+		opts = append(opts, gogitlab.WithHeader("Range", fmt.Sprintf("bytes=%d-", offset)))
+	}
+	trace, resp, err := r.client.Jobs.GetTraceFile(projectID, jobID, opts...)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			return nil, nil
+		}
+		log.Printf("[gitlab] GetTraceFile: error: %v", err)
+		return nil, err
+	}
+	data, err := io.ReadAll(trace)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusPartialContent {
+		log.Printf("[gitlab] GetTraceFile: partial, %d bytes", len(data))
+		return data, nil
+	}
+	if int64(len(data)) <= offset {
+		return nil, nil
+	}
+	log.Printf("[gitlab] GetTraceFile: full trace, %d new bytes", int64(len(data))-offset)
+	return data[offset:], nil
+}
+
 func mapJob(j *gogitlab.Job, projectID int) *entity.Job {
 	job := &entity.Job{
 		ID:        j.ID,
@@ -2,7 +2,9 @@ package gitlab
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"sort"
 
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
 	gogitlab "github.com/xanzy/go-gitlab"
@@ -10,23 +12,32 @@ import (
 
 type MergeRequestRepo struct {
 	client *gogitlab.Client
+	gql    *GraphQLClient // nil: unresolved thread counts are left at 0
 }
 
 func NewMergeRequestRepo(client *gogitlab.Client) *MergeRequestRepo {
 	return &MergeRequestRepo{client: client}
 }
 
-func (r *MergeRequestRepo) List(ctx context.Context, projectID int, state string) ([]entity.MergeRequest, error) {
-	log.Printf("[gitlab] ListMergeRequests: project=%d state=%s", projectID, state)
+// SetGraphQL sets the client used for what the REST API doesn't expose,
+// such as unresolved thread counts.
+func (r *MergeRequestRepo) SetGraphQL(gql *GraphQLClient) { r.gql = gql }
+
+// List returns the most recently updated merge requests, at most limit of
+// them; 0 means all.
+func (r *MergeRequestRepo) List(ctx context.Context, projectID int, state string, limit int) ([]entity.MergeRequest, error) {
+	log.Printf("[gitlab] ListMergeRequests: project=%d state=%s limit=%d", projectID, state, limit)
 	opts := &gogitlab.ListProjectMergeRequestsOptions{
-		ListOptions: gogitlab.ListOptions{PerPage: 50},
-		OrderBy:     gogitlab.Ptr("updated_at"),
-		Sort:        gogitlab.Ptr("desc"),
+		OrderBy: gogitlab.Ptr("updated_at"),
+		Sort:    gogitlab.Ptr("desc"),
 	}
 	if state != "" {
 		opts.State = gogitlab.Ptr(state)
 	}
-	mrs, _, err := r.client.MergeRequests.ListProjectMergeRequests(projectID, opts, gogitlab.WithContext(ctx))
+	mrs, err := paginate(paging{max: limit}, func(lo gogitlab.ListOptions, extra ...gogitlab.RequestOptionFunc) ([]*gogitlab.MergeRequest, *gogitlab.Response, error) {
+		opts.ListOptions = lo
+		return r.client.MergeRequests.ListProjectMergeRequests(projectID, opts, append(extra, gogitlab.WithContext(ctx))...)
+	})
 	if err != nil {
 		log.Printf("[gitlab] ListMergeRequests: error: %v", err)
 		return nil, err
@@ -36,9 +47,38 @@ func (r *MergeRequestRepo) List(ctx context.Context, projectID int, state string
 	for i, mr := range mrs {
 		result[i] = mapMergeRequest(mr, projectID)
 	}
+	r.countUnresolved(ctx, result)
 	return result, nil
 }
 
+// countUnresolved fills in UnresolvedThreads of the open MRs in mrs, which
+// all belong to one project. It is best effort: without the counts the
+// list is still useful, so failures are only logged.
+func (r *MergeRequestRepo) countUnresolved(ctx context.Context, mrs []entity.MergeRequest) {
+	if r.gql == nil {
+		return
+	}
+	var iids []int
+	projectPath := ""
+	for _, mr := range mrs {
+		if mr.State == "opened" {
+			iids = append(iids, mr.IID)
+			projectPath = projectPathFromWebURL(mr.WebURL)
+		}
+	}
+	if len(iids) == 0 || projectPath == "" {
+		return
+	}
+	counts, err := r.gql.UnresolvedThreads(ctx, projectPath, iids)
+	if err != nil {
+		log.Printf("[gitlab] UnresolvedThreads: %s: %v", projectPath, err)
+		return
+	}
+	for i := range mrs {
+		mrs[i].UnresolvedThreads = counts[mrs[i].IID]
+	}
+}
+
 func (r *MergeRequestRepo) Get(ctx context.Context, projectID, mrIID int) (*entity.MergeRequest, error) {
 	log.Printf("[gitlab] GetMergeRequest: project=%d mr=!%d", projectID, mrIID)
 	mr, _, err := r.client.MergeRequests.GetMergeRequest(projectID, mrIID, nil, gogitlab.WithContext(ctx))
@@ -50,38 +90,155 @@ func (r *MergeRequestRepo) Get(ctx context.Context, projectID, mrIID int) (*enti
 	return &result, nil
 }
 
+// ListNotes returns the notes of every discussion in chronological order,
+// each carrying the thread it belongs to.
 func (r *MergeRequestRepo) ListNotes(ctx context.Context, projectID, mrIID int) ([]entity.MRNote, error) {
-	log.Printf("[gitlab] ListMRNotes: project=%d mr=!%d", projectID, mrIID)
-	opts := &gogitlab.ListMergeRequestNotesOptions{
-		ListOptions: gogitlab.ListOptions{PerPage: 100},
-		OrderBy:     gogitlab.Ptr("created_at"),
-		Sort:        gogitlab.Ptr("asc"),
+	discussions, err := r.ListDiscussions(ctx, projectID, mrIID)
+	if err != nil {
+		return nil, err
 	}
-	notes, _, err := r.client.Notes.ListMergeRequestNotes(projectID, mrIID, opts, gogitlab.WithContext(ctx))
+	var result []entity.MRNote
+	for _, d := range discussions {
+		result = append(result, d.Notes...)
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
+func (r *MergeRequestRepo) ListDiscussions(ctx context.Context, projectID, mrIID int) ([]entity.Discussion, error) {
+	log.Printf("[gitlab] ListMRDiscussions: project=%d mr=!%d", projectID, mrIID)
+	discussions, err := paginate(paging{}, func(lo gogitlab.ListOptions, extra ...gogitlab.RequestOptionFunc) ([]*gogitlab.Discussion, *gogitlab.Response, error) {
+		opts := gogitlab.ListMergeRequestDiscussionsOptions(lo)
+		return r.client.Discussions.ListMergeRequestDiscussions(projectID, mrIID, &opts, append(extra, gogitlab.WithContext(ctx))...)
+	})
 	if err != nil {
-		log.Printf("[gitlab] ListMRNotes: error: %v", err)
+		log.Printf("[gitlab] ListMRDiscussions: error: %v", err)
 		return nil, err
 	}
-	log.Printf("[gitlab] ListMRNotes: got %d notes", len(notes))
-	result := make([]entity.MRNote, len(notes))
-	for i, n := range notes {
-		result[i] = entity.MRNote{
-			ID:        n.ID,
-			Author:    n.Author.Username,
-			Body:      n.Body,
-			CreatedAt: *n.CreatedAt,
-			System:    n.System,
-		}
+	log.Printf("[gitlab] ListMRDiscussions: got %d discussions", len(discussions))
+	result := make([]entity.Discussion, len(discussions))
+	for i, d := range discussions {
+		result[i] = mapDiscussion(d)
 	}
 	return result, nil
 }
 
+func (r *MergeRequestRepo) ResolveDiscussion(ctx context.Context, projectID, mrIID int, discussionID string, resolved bool) (*entity.Discussion, error) {
+	log.Printf("[gitlab] ResolveMRDiscussion: project=%d mr=!%d discussion=%s resolved=%v", projectID, mrIID, discussionID, resolved)
+	opts := &gogitlab.ResolveMergeRequestDiscussionOptions{Resolved: gogitlab.Ptr(resolved)}
+	d, _, err := r.client.Discussions.ResolveMergeRequestDiscussion(projectID, mrIID, discussionID, opts, gogitlab.WithContext(ctx))
+	if err != nil {
+		log.Printf("[gitlab] ResolveMRDiscussion: error: %v", err)
+		return nil, err
+	}
+	result := mapDiscussion(d)
+	return &result, nil
+}
+
+func (r *MergeRequestRepo) AddNote(ctx context.Context, projectID, mrIID int, body string) (*entity.MRNote, error) {
+	log.Printf("[gitlab] AddMRNote: project=%d mr=!%d", projectID, mrIID)
+	opts := &gogitlab.CreateMergeRequestDiscussionOptions{Body: gogitlab.Ptr(body)}
+	d, _, err := r.client.Discussions.CreateMergeRequestDiscussion(projectID, mrIID, opts, gogitlab.WithContext(ctx))
+	if err != nil {
+		log.Printf("[gitlab] AddMRNote: error: %v", err)
+		return nil, err
+	}
+	return firstNote(d)
+}
+
+func (r *MergeRequestRepo) AddDiffNote(ctx context.Context, projectID, mrIID int, body string, pos entity.DiffPosition) (*entity.MRNote, error) {
+	log.Printf("[gitlab] AddMRDiffNote: project=%d mr=!%d %s old=%d new=%d", projectID, mrIID, pos.NewPath, pos.OldLine, pos.NewLine)
+	position := &gogitlab.PositionOptions{
+		BaseSHA:      gogitlab.Ptr(pos.BaseSHA),
+		StartSHA:     gogitlab.Ptr(pos.StartSHA),
+		HeadSHA:      gogitlab.Ptr(pos.HeadSHA),
+		PositionType: gogitlab.Ptr("text"),
+		OldPath:      gogitlab.Ptr(pos.OldPath),
+		NewPath:      gogitlab.Ptr(pos.NewPath),
+	}
+	if pos.OldLine > 0 {
+		position.OldLine = gogitlab.Ptr(pos.OldLine)
+	}
+	if pos.NewLine > 0 {
+		position.NewLine = gogitlab.Ptr(pos.NewLine)
+	}
+	opts := &gogitlab.CreateMergeRequestDiscussionOptions{Body: gogitlab.Ptr(body), Position: position}
+	d, _, err := r.client.Discussions.CreateMergeRequestDiscussion(projectID, mrIID, opts, gogitlab.WithContext(ctx))
+	if err != nil {
+		log.Printf("[gitlab] AddMRDiffNote: error: %v", err)
+		return nil, err
+	}
+	return firstNote(d)
+}
+
+func (r *MergeRequestRepo) ReplyToDiscussion(ctx context.Context, projectID, mrIID int, discussionID, body string) (*entity.MRNote, error) {
+	log.Printf("[gitlab] ReplyToMRDiscussion: project=%d mr=!%d discussion=%s", projectID, mrIID, discussionID)
+	opts := &gogitlab.AddMergeRequestDiscussionNoteOptions{Body: gogitlab.Ptr(body)}
+	n, _, err := r.client.Discussions.AddMergeRequestDiscussionNote(projectID, mrIID, discussionID, opts, gogitlab.WithContext(ctx))
+	if err != nil {
+		log.Printf("[gitlab] ReplyToMRDiscussion: error: %v", err)
+		return nil, err
+	}
+	note := mapNote(n, discussionID)
+	return &note, nil
+}
+
+// mapDiscussion treats a thread as resolvable if any note is, and resolved
+// once all of those are.
+func mapDiscussion(d *gogitlab.Discussion) entity.Discussion {
+	result := entity.Discussion{ID: d.ID, Individual: d.IndividualNote, Resolved: true}
+	for _, n := range d.Notes {
+		note := mapNote(n, d.ID)
+		result.Notes = append(result.Notes, note)
+		if result.Position == nil {
+			result.Position = note.Position
+		}
+		if n.Resolvable {
+			result.Resolvable = true
+			result.Resolved = result.Resolved && n.Resolved
+		}
+	}
+	result.Resolved = result.Resolvable && result.Resolved
+	return result
+}
+
+func firstNote(d *gogitlab.Discussion) (*entity.MRNote, error) {
+	if len(d.Notes) == 0 {
+		return nil, fmt.Errorf("discussion %s came back without notes", d.ID)
+	}
+	note := mapNote(d.Notes[0], d.ID)
+	return &note, nil
+}
+
+func mapNote(n *gogitlab.Note, discussionID string) entity.MRNote {
+	note := entity.MRNote{
+		ID:           n.ID,
+		DiscussionID: discussionID,
+		Author:       n.Author.Username,
+		Body:         n.Body,
+		System:       n.System,
+	}
+	if n.CreatedAt != nil {
+		note.CreatedAt = *n.CreatedAt
+	}
+	if p := n.Position; p != nil && p.PositionType == "text" {
+		note.Position = &entity.DiffPosition{
+			DiffRefs: entity.DiffRefs{BaseSHA: p.BaseSHA, StartSHA: p.StartSHA, HeadSHA: p.HeadSHA},
+			OldPath:  p.OldPath,
+			NewPath:  p.NewPath,
+			OldLine:  p.OldLine,
+			NewLine:  p.NewLine,
+		}
+	}
+	return note
+}
+
 func (r *MergeRequestRepo) GetDiffs(ctx context.Context, projectID, mrIID int) ([]entity.MRDiff, error) {
 	log.Printf("[gitlab] GetMRDiffs: project=%d mr=!%d", projectID, mrIID)
-	opts := &gogitlab.ListMergeRequestDiffsOptions{
-		ListOptions: gogitlab.ListOptions{PerPage: 100},
-	}
-	diffs, _, err := r.client.MergeRequests.ListMergeRequestDiffs(projectID, mrIID, opts, gogitlab.WithContext(ctx))
+	diffs, err := paginate(paging{}, func(lo gogitlab.ListOptions, extra ...gogitlab.RequestOptionFunc) ([]*gogitlab.MergeRequestDiff, *gogitlab.Response, error) {
+		opts := &gogitlab.ListMergeRequestDiffsOptions{ListOptions: lo}
+		return r.client.MergeRequests.ListMergeRequestDiffs(projectID, mrIID, opts, append(extra, gogitlab.WithContext(ctx))...)
+	})
 	if err != nil {
 		log.Printf("[gitlab] GetMRDiffs: error: %v", err)
 		return nil, err
@@ -136,14 +293,32 @@ func (r *MergeRequestRepo) Approve(ctx context.Context, projectID, mrIID int) er
 	return nil
 }
 
-func (r *MergeRequestRepo) Merge(ctx context.Context, projectID, mrIID int) (*entity.MergeRequest, error) {
-	log.Printf("[gitlab] MergeMR: project=%d mr=!%d", projectID, mrIID)
-	mr, _, err := r.client.MergeRequests.AcceptMergeRequest(projectID, mrIID, nil, gogitlab.WithContext(ctx))
+func (r *MergeRequestRepo) Merge(ctx context.Context, projectID, mrIID int, opts entity.MergeOptions) (*entity.MergeRequest, error) {
+	log.Printf("[gitlab] MergeMR: project=%d mr=!%d opts=%+v", projectID, mrIID, opts)
+	// Squash and branch removal are always sent so that unticking them
+	// overrides what the author chose on the MR
+	req := &gogitlab.AcceptMergeRequestOptions{
+		Squash:                   gogitlab.Ptr(opts.Squash),
+		ShouldRemoveSourceBranch: gogitlab.Ptr(opts.RemoveSourceBranch),
+	}
+	if opts.WhenPipelineSucceeds {
+		req.MergeWhenPipelineSucceeds = gogitlab.Ptr(true)
+	}
+	if opts.SquashCommitMessage != "" {
+		req.SquashCommitMessage = gogitlab.Ptr(opts.SquashCommitMessage)
+	}
+	if opts.MergeCommitMessage != "" {
+		req.MergeCommitMessage = gogitlab.Ptr(opts.MergeCommitMessage)
+	}
+	if opts.SHA != "" {
+		req.SHA = gogitlab.Ptr(opts.SHA)
+	}
+	mr, _, err := r.client.MergeRequests.AcceptMergeRequest(projectID, mrIID, req, gogitlab.WithContext(ctx))
 	if err != nil {
 		log.Printf("[gitlab] MergeMR: error: %v", err)
 		return nil, err
 	}
-	log.Printf("[gitlab] MergeMR: ok, state=%s", mr.State)
+	log.Printf("[gitlab] MergeMR: ok, state=%s auto_merge=%v", mr.State, mr.MergeWhenPipelineSucceeds)
 	result := mapMergeRequest(mr, projectID)
 	return &result, nil
 }
@@ -161,6 +336,21 @@ func mapMergeRequest(mr *gogitlab.MergeRequest, projectID int) entity.MergeReque
 		MergeStatus:  mr.MergeStatus,
 		Draft:        mr.Draft,
 		WebURL:       mr.WebURL,
+		SHA:          mr.SHA,
+		Squash:       mr.Squash,
+		RemoveSource: mr.ForceRemoveSourceBranch,
+		AutoMerge:    mr.MergeWhenPipelineSucceeds,
+		DiffRefs: entity.DiffRefs{
+			BaseSHA:  mr.DiffRefs.BaseSha,
+			StartSHA: mr.DiffRefs.StartSha,
+			HeadSHA:  mr.DiffRefs.HeadSha,
+		},
+	}
+	switch {
+	case mr.HeadPipeline != nil:
+		m.PipelineStatus = mr.HeadPipeline.Status
+	case mr.Pipeline != nil:
+		m.PipelineStatus = mr.Pipeline.Status
 	}
 	if mr.Author != nil {
 		m.Author = mr.Author.Username
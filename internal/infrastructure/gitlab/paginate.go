@@ -0,0 +1,54 @@
+package gitlab
+
+import gogitlab "github.com/xanzy/go-gitlab"
+
+// maxPerPage is the largest page GitLab serves.
+const maxPerPage = 100
+
+// paging says how a list call walks its pages.
+type paging struct {
+	max     int    // stop after this many items; 0 fetches every page
+	keyset  bool   // follow keyset links instead of page numbers
+	orderBy string // keyset pagination needs an order, e.g. "id"
+	sort    string
+}
+
+// fetchPage gets one page of a list call. It must send opts and the extra
+// request options, which carry the keyset cursor.
+type fetchPage[T any] func(opts gogitlab.ListOptions, extra ...gogitlab.RequestOptionFunc) ([]T, *gogitlab.Response, error)
+
+// paginate collects the pages of a list call until the last one or until
+// p.max items. Offset pagination works on every endpoint; keyset
+// pagination, which only some endpoints offer, stays fast deep into large
+// lists and doesn't skip or repeat items when the list changes meanwhile.
+func paginate[T any](p paging, fetch fetchPage[T]) ([]T, error) {
+	opts := gogitlab.ListOptions{PerPage: maxPerPage, Page: 1}
+	if p.max > 0 {
+		opts.PerPage = min(p.max, maxPerPage)
+	}
+	if p.keyset {
+		opts = gogitlab.ListOptions{Pagination: "keyset", PerPage: opts.PerPage, OrderBy: p.orderBy, Sort: p.sort}
+	}
+	var all []T
+	var extra []gogitlab.RequestOptionFunc
+	for {
+		items, resp, err := fetch(opts, extra...)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if p.max > 0 && len(all) >= p.max {
+			return all[:p.max], nil
+		}
+		switch {
+		case resp == nil || len(items) == 0:
+			return all, nil
+		case p.keyset && resp.NextLink != "":
+			extra = []gogitlab.RequestOptionFunc{gogitlab.WithKeysetPaginationParameters(resp.NextLink)}
+		case !p.keyset && resp.NextPage != 0:
+			opts.Page = resp.NextPage
+		default:
+			return all, nil
+		}
+	}
+}
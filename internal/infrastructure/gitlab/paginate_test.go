@@ -0,0 +1,146 @@
+package gitlab
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	gogitlab "github.com/xanzy/go-gitlab"
+)
+
+// offsetList serves items by page number, like most GitLab list endpoints.
+type offsetList struct {
+	items []int
+	pages []gogitlab.ListOptions // options of every request made
+}
+
+func (l *offsetList) fetch(opts gogitlab.ListOptions, _ ...gogitlab.RequestOptionFunc) ([]int, *gogitlab.Response, error) {
+	l.pages = append(l.pages, opts)
+	start := min((opts.Page-1)*opts.PerPage, len(l.items))
+	end := min(start+opts.PerPage, len(l.items))
+	resp := &gogitlab.Response{}
+	if end < len(l.items) {
+		resp.NextPage = opts.Page + 1
+	}
+	return l.items[start:end], resp, nil
+}
+
+func numbers(n int) []int {
+	out := make([]int, n)
+	for i := range out {
+		out[i] = i
+	}
+	return out
+}
+
+func TestPaginateOffset(t *testing.T) {
+	tests := []struct {
+		name      string
+		items     int
+		max       int
+		wantLen   int
+		wantPages int
+		wantSize  int
+	}{
+		{"all pages", 250, 0, 250, 3, maxPerPage},
+		{"empty list", 0, 0, 0, 1, maxPerPage},
+		{"max within the first page", 250, 30, 30, 1, 30},
+		{"max across pages is trimmed", 250, 120, 120, 2, maxPerPage},
+		{"max above the total", 40, 50, 40, 1, 50},
+		{"max on a page boundary", 250, 200, 200, 2, maxPerPage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &offsetList{items: numbers(tt.items)}
+			got, err := paginate(paging{max: tt.max}, l.fetch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.wantLen || (len(got) > 0 && !reflect.DeepEqual(got, numbers(tt.wantLen))) {
+				t.Errorf("got %d items, want the first %d", len(got), tt.wantLen)
+			}
+			if len(l.pages) != tt.wantPages {
+				t.Errorf("made %d requests, want %d", len(l.pages), tt.wantPages)
+			}
+			if l.pages[0].PerPage != tt.wantSize || l.pages[0].Page != 1 {
+				t.Errorf("first request asked for page %d of %d", l.pages[0].Page, l.pages[0].PerPage)
+			}
+		})
+	}
+}
+
+func TestPaginateKeyset(t *testing.T) {
+	pages := [][]int{{1, 2}, {3, 4}, {5}}
+	calls := 0
+	fetch := func(opts gogitlab.ListOptions, extra ...gogitlab.RequestOptionFunc) ([]int, *gogitlab.Response, error) {
+		if opts.Pagination != "keyset" || opts.OrderBy != "id" || opts.Sort != "asc" || opts.Page != 0 {
+			t.Fatalf("unexpected options %+v", opts)
+		}
+		// Every page but the first carries the cursor of the previous one
+		if want := min(calls, 1); len(extra) != want {
+			t.Fatalf("request %d has %d extra options, want %d", calls, len(extra), want)
+		}
+		resp := &gogitlab.Response{}
+		if calls < len(pages)-1 {
+			resp.NextLink = fmt.Sprintf("https://gitlab.example.com/api/v4/projects?id_after=%d&pagination=keyset", pages[calls][1])
+		}
+		calls++
+		return pages[calls-1], resp, nil
+	}
+
+	got, err := paginate(paging{keyset: true, orderBy: "id", sort: "asc"}, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []int{1, 2, 3, 4, 5}) || calls != 3 {
+		t.Errorf("got %v in %d requests", got, calls)
+	}
+
+	calls = 0
+	got, err = paginate(paging{max: 3, keyset: true, orderBy: "id", sort: "asc"}, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []int{1, 2, 3}) || calls != 2 {
+		t.Errorf("with max 3: got %v in %d requests", got, calls)
+	}
+}
+
+func TestPaginateStops(t *testing.T) {
+	t.Run("on an empty page", func(t *testing.T) {
+		calls := 0
+		fetch := func(gogitlab.ListOptions, ...gogitlab.RequestOptionFunc) ([]int, *gogitlab.Response, error) {
+			calls++
+			// a broken next page that never ends
+			return nil, &gogitlab.Response{NextPage: calls + 1}, nil
+		}
+		if _, err := paginate(paging{}, fetch); err != nil || calls != 1 {
+			t.Errorf("got %d calls, err %v", calls, err)
+		}
+	})
+	t.Run("without a next link", func(t *testing.T) {
+		calls := 0
+		fetch := func(gogitlab.ListOptions, ...gogitlab.RequestOptionFunc) ([]int, *gogitlab.Response, error) {
+			calls++
+			// keyset mode ignores page numbers
+			return []int{calls}, &gogitlab.Response{NextPage: calls + 1}, nil
+		}
+		if _, err := paginate(paging{keyset: true, orderBy: "id"}, fetch); err != nil || calls != 1 {
+			t.Errorf("got %d calls, err %v", calls, err)
+		}
+	})
+	t.Run("on an error", func(t *testing.T) {
+		boom := errors.New("boom")
+		l := &offsetList{items: numbers(250)}
+		fetch := func(opts gogitlab.ListOptions, extra ...gogitlab.RequestOptionFunc) ([]int, *gogitlab.Response, error) {
+			if opts.Page == 2 {
+				return nil, nil, boom
+			}
+			return l.fetch(opts, extra...)
+		}
+		if got, err := paginate(paging{}, fetch); !errors.Is(err, boom) || got != nil {
+			t.Errorf("got %d items, err %v", len(got), err)
+		}
+	})
+}
@@ -2,30 +2,54 @@ package gitlab
 
 import (
 	"context"
-	"fmt"
 	"log"
+	"net/url"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/valueobject"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/pkg/fanout"
 	gogitlab "github.com/xanzy/go-gitlab"
 )
 
 type PipelineRepo struct {
-	client *gogitlab.Client
+	client      *gogitlab.Client
+	gql         *GraphQLClient
+	concurrency int
+	authors     bool
+
+	mu          sync.Mutex
+	authorCache map[int]string // pipeline ID -> username; it never changes
 }
 
 func NewPipelineRepo(client *gogitlab.Client) *PipelineRepo {
-	return &PipelineRepo{client: client}
+	return &PipelineRepo{client: client, concurrency: fanout.DefaultLimit}
 }
 
+// SetConcurrency limits how many projects LoadAllPipelines queries in parallel.
+func (r *PipelineRepo) SetConcurrency(n int) { r.concurrency = n }
+
+// SetAuthors makes LoadAllPipelines fill in Author, which the REST list
+// doesn't return, at the cost of a request per pipeline it hasn't seen
+// before. Notification rules on authors need it.
+func (r *PipelineRepo) SetAuthors(on bool) { r.authors = on }
+
+// SetGraphQL sets the client used for what the REST API doesn't expose,
+// such as job needs.
+func (r *PipelineRepo) SetGraphQL(gql *GraphQLClient) { r.gql = gql }
+
 func (r *PipelineRepo) ListJobs(ctx context.Context, projectID, pipelineID int) ([]entity.Job, error) {
 	log.Printf("[gitlab] ListJobs: project=%d pipeline=%d", projectID, pipelineID)
-	opts := &gogitlab.ListJobsOptions{ListOptions: gogitlab.ListOptions{PerPage: 100}}
+	opts := &gogitlab.ListJobsOptions{}
 
 	// Regular jobs
-	jobs, _, err := r.client.Jobs.ListPipelineJobs(projectID, pipelineID, opts, gogitlab.WithContext(ctx))
+	jobs, err := paginate(paging{}, func(lo gogitlab.ListOptions, extra ...gogitlab.RequestOptionFunc) ([]*gogitlab.Job, *gogitlab.Response, error) {
+		opts.ListOptions = lo
+		return r.client.Jobs.ListPipelineJobs(projectID, pipelineID, opts, append(extra, gogitlab.WithContext(ctx))...)
+	})
 	if err != nil {
 		log.Printf("[gitlab] ListJobs: error: %v", err)
 		return nil, err
@@ -54,7 +78,10 @@ func (r *PipelineRepo) ListJobs(ctx context.Context, projectID, pipelineID int)
 	}
 
 	// Bridge/trigger jobs
-	bridges, _, err := r.client.Jobs.ListPipelineBridges(projectID, pipelineID, opts, gogitlab.WithContext(ctx))
+	bridges, err := paginate(paging{}, func(lo gogitlab.ListOptions, extra ...gogitlab.RequestOptionFunc) ([]*gogitlab.Bridge, *gogitlab.Response, error) {
+		opts.ListOptions = lo
+		return r.client.Jobs.ListPipelineBridges(projectID, pipelineID, opts, append(extra, gogitlab.WithContext(ctx))...)
+	})
 	if err != nil {
 		log.Printf("[gitlab] ListJobs: bridges error (non-fatal): %v", err)
 	} else {
@@ -69,6 +96,7 @@ func (r *PipelineRepo) ListJobs(ctx context.Context, projectID, pipelineID int)
 				Status:     valueobject.JobStatus(b.Status),
 				Duration:   b.Duration,
 				WebURL:     b.WebURL,
+				Bridge:     true,
 			}
 			if b.StartedAt != nil {
 				job.StartedAt = b.StartedAt
@@ -76,6 +104,17 @@ func (r *PipelineRepo) ListJobs(ctx context.Context, projectID, pipelineID int)
 			if b.FinishedAt != nil {
 				job.FinishedAt = b.FinishedAt
 			}
+			if d := b.DownstreamPipeline; d != nil {
+				job.Downstream = &entity.DownstreamPipeline{
+					ID:          d.ID,
+					IID:         d.IID,
+					ProjectID:   d.ProjectID,
+					ProjectPath: projectPathFromWebURL(d.WebURL),
+					Ref:         d.Ref,
+					Status:      valueobject.PipelineStatus(d.Status),
+					WebURL:      d.WebURL,
+				}
+			}
 			result = append(result, job)
 		}
 	}
@@ -85,50 +124,213 @@ func (r *PipelineRepo) ListJobs(ctx context.Context, projectID, pipelineID int)
 }
 
 func (r *PipelineRepo) LoadAllPipelines(ctx context.Context, projectPaths []string, perProject int) ([]entity.Pipeline, error) {
-	log.Printf("[gitlab] LoadAllPipelines: paths=%v perProject=%d", projectPaths, perProject)
+	log.Printf("[gitlab] LoadAllPipelines: paths=%v perProject=%d concurrency=%d", projectPaths, perProject, r.concurrency)
+	results, errs := fanout.Run(ctx, projectPaths, r.concurrency, func(ctx context.Context, path string) ([]entity.Pipeline, error) {
+		return r.loadProjectPipelines(ctx, path, perProject)
+	})
+
 	var all []entity.Pipeline
-	var lastErr error
-	for _, path := range projectPaths {
-		p, _, err := r.client.Projects.GetProject(path, nil, gogitlab.WithContext(ctx))
-		if err != nil {
-			log.Printf("[gitlab] LoadAllPipelines: skip %s: %v", path, err)
-			lastErr = err
+	var failed []entity.ProjectError
+	for i, pls := range results {
+		if errs[i] != nil {
+			log.Printf("[gitlab] LoadAllPipelines: skip %s: %v", projectPaths[i], errs[i])
+			failed = append(failed, entity.ProjectError{Path: projectPaths[i], Err: errs[i]})
 			continue
 		}
+		all = append(all, pls...)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].CreatedAt.After(all[j].CreatedAt)
+	})
+	return all, entity.NewProjectLoadError(failed, len(projectPaths))
+}
+
+func (r *PipelineRepo) loadProjectPipelines(ctx context.Context, path string, perProject int) ([]entity.Pipeline, error) {
+	p, _, err := r.client.Projects.GetProject(path, nil, gogitlab.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &gogitlab.ListProjectPipelinesOptions{
+		OrderBy: gogitlab.Ptr("id"),
+		Sort:    gogitlab.Ptr("desc"),
+	}
+	pls, err := paginate(paging{max: perProject}, func(lo gogitlab.ListOptions, extra ...gogitlab.RequestOptionFunc) ([]*gogitlab.PipelineInfo, *gogitlab.Response, error) {
+		opts.ListOptions = lo
+		return r.client.Pipelines.ListProjectPipelines(p.ID, opts, append(extra, gogitlab.WithContext(ctx))...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[gitlab] LoadAllPipelines: %s got %d pipelines", path, len(pls))
 
-		opts := &gogitlab.ListProjectPipelinesOptions{
-			ListOptions: gogitlab.ListOptions{PerPage: perProject},
-			OrderBy:     gogitlab.Ptr("id"),
-			Sort:        gogitlab.Ptr("desc"),
+	result := make([]entity.Pipeline, 0, len(pls))
+	for _, pl := range pls {
+		createdAt := time.Time{}
+		if pl.CreatedAt != nil {
+			createdAt = *pl.CreatedAt
 		}
-		pls, _, err := r.client.Pipelines.ListProjectPipelines(p.ID, opts, gogitlab.WithContext(ctx))
-		if err != nil {
-			log.Printf("[gitlab] LoadAllPipelines: skip pipelines for %s: %v", path, err)
-			continue
+		result = append(result, entity.Pipeline{
+			ID:          pl.ID,
+			IID:         pl.IID,
+			ProjectID:   p.ID,
+			ProjectPath: p.PathWithNamespace,
+			Ref:         pl.Ref,
+			SHA:         pl.SHA,
+			Source:      pl.Source,
+			Status:      valueobject.PipelineStatus(pl.Status),
+			CreatedAt:   createdAt,
+			WebURL:      pl.WebURL,
+		})
+	}
+	if r.authors {
+		r.fillAuthors(ctx, result)
+	}
+	return result, nil
+}
+
+// fillAuthors looks up who started each pipeline. A failed lookup leaves
+// Author empty and is tried again on the next load.
+func (r *PipelineRepo) fillAuthors(ctx context.Context, pls []entity.Pipeline) {
+	for i := range pls {
+		pl := &pls[i]
+		r.mu.Lock()
+		author, ok := r.authorCache[pl.ID]
+		r.mu.Unlock()
+		if !ok {
+			full, _, err := r.client.Pipelines.GetPipeline(pl.ProjectID, pl.ID, gogitlab.WithContext(ctx))
+			if err != nil {
+				log.Printf("[gitlab] LoadAllPipelines: author of pipeline %d: %v", pl.ID, err)
+				continue
+			}
+			if full.User != nil {
+				author = full.User.Username
+			}
+			r.mu.Lock()
+			if r.authorCache == nil {
+				r.authorCache = make(map[int]string)
+			}
+			r.authorCache[pl.ID] = author
+			r.mu.Unlock()
 		}
-		log.Printf("[gitlab] LoadAllPipelines: %s got %d pipelines", path, len(pls))
+		pl.Author = author
+	}
+}
 
-		for _, pl := range pls {
-			createdAt := time.Time{}
-			if pl.CreatedAt != nil {
-				createdAt = *pl.CreatedAt
+func (r *PipelineRepo) Get(ctx context.Context, projectID, pipelineID int) (*entity.Pipeline, error) {
+	log.Printf("[gitlab] GetPipeline: project=%d pipeline=%d", projectID, pipelineID)
+	pl, _, err := r.client.Pipelines.GetPipeline(projectID, pipelineID, gogitlab.WithContext(ctx))
+	if err != nil {
+		log.Printf("[gitlab] GetPipeline: error: %v", err)
+		return nil, err
+	}
+	return toPipeline(pl), nil
+}
+
+func (r *PipelineRepo) Latest(ctx context.Context, projectID int, ref, sha string) (*entity.Pipeline, error) {
+	log.Printf("[gitlab] LatestPipeline: project=%d ref=%q sha=%q", projectID, ref, sha)
+	opts := &gogitlab.ListProjectPipelinesOptions{
+		ListOptions: gogitlab.ListOptions{PerPage: 1},
+		OrderBy:     gogitlab.Ptr("id"),
+		Sort:        gogitlab.Ptr("desc"),
+	}
+	if ref != "" {
+		opts.Ref = gogitlab.Ptr(ref)
+	}
+	if sha != "" {
+		opts.SHA = gogitlab.Ptr(sha)
+	}
+	pls, _, err := r.client.Pipelines.ListProjectPipelines(projectID, opts, gogitlab.WithContext(ctx))
+	if err != nil {
+		log.Printf("[gitlab] LatestPipeline: error: %v", err)
+		return nil, err
+	}
+	if len(pls) == 0 {
+		return nil, nil
+	}
+	// The list endpoint omits duration and author, so fetch the full pipeline
+	return r.Get(ctx, projectID, pls[0].ID)
+}
+
+func (r *PipelineRepo) Create(ctx context.Context, projectID int, ref string, variables []entity.PipelineVariable) (*entity.Pipeline, error) {
+	log.Printf("[gitlab] CreatePipeline: project=%d ref=%s vars=%d", projectID, ref, len(variables))
+	opts := &gogitlab.CreatePipelineOptions{Ref: gogitlab.Ptr(ref)}
+	if len(variables) > 0 {
+		vars := make([]*gogitlab.PipelineVariableOptions, len(variables))
+		for i, v := range variables {
+			vars[i] = &gogitlab.PipelineVariableOptions{
+				Key:          gogitlab.Ptr(v.Key),
+				Value:        gogitlab.Ptr(v.Value),
+				VariableType: gogitlab.Ptr(gogitlab.EnvVariableType),
 			}
-			all = append(all, entity.Pipeline{
-				ID:          pl.ID,
-				ProjectID:   p.ID,
-				ProjectPath: p.PathWithNamespace,
-				Ref:         pl.Ref,
-				Status:      valueobject.PipelineStatus(pl.Status),
-				CreatedAt:   createdAt,
-			})
 		}
+		opts.Variables = &vars
 	}
+	pl, _, err := r.client.Pipelines.CreatePipeline(projectID, opts, gogitlab.WithContext(ctx))
+	if err != nil {
+		log.Printf("[gitlab] CreatePipeline: error: %v", err)
+		return nil, err
+	}
+	log.Printf("[gitlab] CreatePipeline: ok, id=%d status=%s", pl.ID, pl.Status)
+	return toPipeline(pl), nil
+}
 
-	if len(all) == 0 && lastErr != nil {
-		return nil, fmt.Errorf("all projects failed, last error: %w", lastErr)
+func (r *PipelineRepo) Retry(ctx context.Context, projectID, pipelineID int) (*entity.Pipeline, error) {
+	log.Printf("[gitlab] RetryPipeline: project=%d pipeline=%d", projectID, pipelineID)
+	pl, _, err := r.client.Pipelines.RetryPipelineBuild(projectID, pipelineID, gogitlab.WithContext(ctx))
+	if err != nil {
+		log.Printf("[gitlab] RetryPipeline: error: %v", err)
+		return nil, err
 	}
-	sort.Slice(all, func(i, j int) bool {
-		return all[i].CreatedAt.After(all[j].CreatedAt)
-	})
-	return all, nil
+	return toPipeline(pl), nil
+}
+
+func (r *PipelineRepo) Cancel(ctx context.Context, projectID, pipelineID int) (*entity.Pipeline, error) {
+	log.Printf("[gitlab] CancelPipeline: project=%d pipeline=%d", projectID, pipelineID)
+	pl, _, err := r.client.Pipelines.CancelPipelineBuild(projectID, pipelineID, gogitlab.WithContext(ctx))
+	if err != nil {
+		log.Printf("[gitlab] CancelPipeline: error: %v", err)
+		return nil, err
+	}
+	return toPipeline(pl), nil
+}
+
+func (r *PipelineRepo) JobNeeds(ctx context.Context, projectPath string, pipelineIID int) (map[string][]string, error) {
+	if r.gql == nil {
+		return nil, nil
+	}
+	return r.gql.JobNeeds(ctx, projectPath, pipelineIID)
+}
+
+// projectPathFromWebURL extracts "group/app" from a project page URL such
+// as https://gitlab.com/group/app/-/pipelines/42. The bridges API only
+// returns the numeric ID of the downstream project.
+func projectPathFromWebURL(webURL string) string {
+	u, err := url.Parse(webURL)
+	if err != nil {
+		return ""
+	}
+	path, _, _ := strings.Cut(u.Path, "/-/")
+	return strings.Trim(path, "/")
+}
+
+func toPipeline(pl *gogitlab.Pipeline) *entity.Pipeline {
+	p := &entity.Pipeline{
+		ID:        pl.ID,
+		IID:       pl.IID,
+		ProjectID: pl.ProjectID,
+		Ref:       pl.Ref,
+		SHA:       pl.SHA,
+		Source:    string(pl.Source),
+		Status:    valueobject.PipelineStatus(pl.Status),
+		Duration:  pl.Duration,
+		WebURL:    pl.WebURL,
+	}
+	if pl.CreatedAt != nil {
+		p.CreatedAt = *pl.CreatedAt
+	}
+	if pl.User != nil {
+		p.Author = pl.User.Username
+	}
+	return p
 }
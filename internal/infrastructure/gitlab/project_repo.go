@@ -3,6 +3,8 @@ package gitlab
 import (
 	"context"
 	"log"
+	"sort"
+	"strings"
 
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/valueobject"
@@ -32,14 +34,24 @@ func (r *ProjectRepo) GetByPath(ctx context.Context, pathWithNS string) (*entity
 	}, nil
 }
 
-func (r *ProjectRepo) Search(ctx context.Context, query string) ([]entity.Project, error) {
-	log.Printf("[gitlab] Search: query=%q", query)
-	opts := &gogitlab.ListProjectsOptions{
-		ListOptions: gogitlab.ListOptions{PerPage: 10},
-		Search:      gogitlab.Ptr(query),
-		OrderBy:     gogitlab.Ptr("name"),
+// Search returns up to limit projects matching query, sorted by name; 0
+// means all. A bounded search pages by offset so GitLab sorts by name and
+// returns the first matches; fetching all of them uses keyset pagination,
+// which GitLab offers for projects in id order only, and sorts here.
+func (r *ProjectRepo) Search(ctx context.Context, query string, limit int) ([]entity.Project, error) {
+	log.Printf("[gitlab] Search: query=%q limit=%d", query, limit)
+	opts := &gogitlab.ListProjectsOptions{Search: gogitlab.Ptr(query)}
+	p := paging{max: limit}
+	if limit > 0 {
+		opts.OrderBy = gogitlab.Ptr("name")
+		opts.Sort = gogitlab.Ptr("asc")
+	} else {
+		p = paging{keyset: true, orderBy: "id", sort: "asc"}
 	}
-	projects, _, err := r.client.Projects.ListProjects(opts, gogitlab.WithContext(ctx))
+	projects, err := paginate(p, func(lo gogitlab.ListOptions, extra ...gogitlab.RequestOptionFunc) ([]*gogitlab.Project, *gogitlab.Response, error) {
+		opts.ListOptions = lo
+		return r.client.Projects.ListProjects(opts, append(extra, gogitlab.WithContext(ctx))...)
+	})
 	if err != nil {
 		log.Printf("[gitlab] Search: error: %v", err)
 		return nil, err
@@ -54,18 +66,23 @@ func (r *ProjectRepo) Search(ctx context.Context, query string) ([]entity.Projec
 			WebURL:     p.WebURL,
 		}
 	}
+	if limit <= 0 {
+		sort.SliceStable(result, func(i, j int) bool { return strings.ToLower(result[i].Name) < strings.ToLower(result[j].Name) })
+	}
 	return result, nil
 }
 
-func (r *ProjectRepo) ListBranches(ctx context.Context, projectID int, search string) ([]string, error) {
-	log.Printf("[gitlab] ListBranches: project=%d search=%q", projectID, search)
-	opts := &gogitlab.ListBranchesOptions{
-		ListOptions: gogitlab.ListOptions{PerPage: 20},
-	}
+// ListBranches returns up to limit branches matching search; 0 means all.
+func (r *ProjectRepo) ListBranches(ctx context.Context, projectID int, search string, limit int) ([]string, error) {
+	log.Printf("[gitlab] ListBranches: project=%d search=%q limit=%d", projectID, search, limit)
+	opts := &gogitlab.ListBranchesOptions{}
 	if search != "" {
 		opts.Search = gogitlab.Ptr(search)
 	}
-	branches, _, err := r.client.Branches.ListBranches(projectID, opts, gogitlab.WithContext(ctx))
+	branches, err := paginate(paging{max: limit}, func(lo gogitlab.ListOptions, extra ...gogitlab.RequestOptionFunc) ([]*gogitlab.Branch, *gogitlab.Response, error) {
+		opts.ListOptions = lo
+		return r.client.Branches.ListBranches(projectID, opts, append(extra, gogitlab.WithContext(ctx))...)
+	})
 	if err != nil {
 		log.Printf("[gitlab] ListBranches: error: %v", err)
 		return nil, err
@@ -81,11 +98,13 @@ func (r *ProjectRepo) ListBranches(ctx context.Context, projectID int, search st
 func (r *ProjectRepo) ListPipelines(ctx context.Context, projectID int) ([]entity.Pipeline, error) {
 	log.Printf("[gitlab] ListPipelines: project=%d", projectID)
 	opts := &gogitlab.ListProjectPipelinesOptions{
-		ListOptions: gogitlab.ListOptions{PerPage: 20},
-		OrderBy:     gogitlab.Ptr("id"),
-		Sort:        gogitlab.Ptr("desc"),
+		OrderBy: gogitlab.Ptr("id"),
+		Sort:    gogitlab.Ptr("desc"),
 	}
-	pls, _, err := r.client.Pipelines.ListProjectPipelines(projectID, opts, gogitlab.WithContext(ctx))
+	pls, err := paginate(paging{max: 20}, func(lo gogitlab.ListOptions, extra ...gogitlab.RequestOptionFunc) ([]*gogitlab.PipelineInfo, *gogitlab.Response, error) {
+		opts.ListOptions = lo
+		return r.client.Pipelines.ListProjectPipelines(projectID, opts, append(extra, gogitlab.WithContext(ctx))...)
+	})
 	if err != nil {
 		log.Printf("[gitlab] ListPipelines: error: %v", err)
 		return nil, err
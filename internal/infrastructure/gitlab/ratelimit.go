@@ -0,0 +1,141 @@
+package gitlab
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter is a token bucket shared by the REST and GraphQL clients so
+// that both count against the same GitLab budget. It starts unlimited and
+// tunes itself from the RateLimit-* headers of the first response, then
+// keeps tracking RateLimit-Remaining and honours Retry-After.
+type RateLimiter struct {
+	bucket *rate.Limiter
+
+	mu           sync.Mutex
+	configured   bool
+	limit        int // requests per window, from RateLimit-Limit
+	remaining    int // from RateLimit-Remaining, -1 when unknown
+	blockedUntil time.Time
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		bucket:    rate.NewLimiter(rate.Inf, 0),
+		remaining: -1,
+	}
+}
+
+// Wait blocks until a request may be sent. It satisfies go-gitlab's
+// RateLimiter interface.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	until := l.blockedUntil
+	l.mu.Unlock()
+	if d := time.Until(until); d > 0 {
+		log.Printf("[ratelimit] waiting %s for Retry-After", d.Round(time.Millisecond))
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	return l.bucket.Wait(ctx)
+}
+
+// Observe records the rate limit headers of a response.
+func (l *RateLimiter) Observe(resp *http.Response) {
+	h := resp.Header
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if v, err := strconv.Atoi(h.Get("RateLimit-Limit")); err == nil && v > 0 {
+		l.limit = v
+		if !l.configured {
+			// The limit is per minute. Allow bursting a third of it and spread
+			// the rest evenly, like go-gitlab's built-in limiter does.
+			perSecond := float64(v) / 60
+			burst := int(perSecond * 0.33)
+			if burst == 0 {
+				burst = 1
+			}
+			l.bucket.SetLimit(rate.Limit(perSecond * 0.66))
+			l.bucket.SetBurst(burst)
+			l.configured = true
+			log.Printf("[ratelimit] configured: %d req/min", v)
+		}
+	}
+	if v, err := strconv.Atoi(h.Get("RateLimit-Remaining")); err == nil {
+		l.remaining = v
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || h.Get("Retry-After") != "" {
+		if until := retryAfter(h); until.After(l.blockedUntil) {
+			l.blockedUntil = until
+			log.Printf("[ratelimit] throttled until %s", until.Format(time.TimeOnly))
+		}
+	}
+}
+
+// Pressure reports how much of the rate limit budget is used up, from 0
+// (plenty left or unknown) to 1 (exhausted or currently throttled).
+func (l *RateLimiter) Pressure() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if time.Now().Before(l.blockedUntil) {
+		return 1
+	}
+	if l.limit <= 0 || l.remaining < 0 {
+		return 0
+	}
+	used := 1 - float64(l.remaining)/float64(l.limit)
+	if used < 0 {
+		return 0
+	}
+	return used
+}
+
+// Transport wraps base so every response passes through Observe.
+func (l *RateLimiter) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &rateLimitTransport{base: base, limiter: l}
+}
+
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	limiter *RateLimiter
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		t.limiter.Observe(resp)
+	}
+	return resp, err
+}
+
+// retryAfter parses Retry-After (seconds or HTTP date), falling back to
+// RateLimit-Reset (unix time), and finally to a short fixed pause.
+func retryAfter(h http.Header) time.Time {
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return time.Now().Add(time.Duration(secs) * time.Second)
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return t
+		}
+	}
+	if v, err := strconv.ParseInt(h.Get("RateLimit-Reset"), 10, 64); err == nil && v > 0 {
+		return time.Unix(v, 0)
+	}
+	return time.Now().Add(10 * time.Second)
+}
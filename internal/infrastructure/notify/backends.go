@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/valueobject"
+)
+
+// hookTimeout bounds how long a hook or notify-send may run.
+const hookTimeout = 30 * time.Second
+
+// OSC9 sends a notification through the terminal (iTerm2, WezTerm, kitty,
+// Windows Terminal).
+type OSC9 struct{ W io.Writer }
+
+func (o OSC9) Notify(_ context.Context, n Notification) error {
+	_, err := fmt.Fprintf(o.W, "\x1b]9;%s: %s\x07", oscText(n.Title), oscText(n.Body))
+	return err
+}
+
+// OSC777 sends a notification through the terminal (foot, Ghostty,
+// rxvt-unicode, VTE-based terminals).
+type OSC777 struct{ W io.Writer }
+
+func (o OSC777) Notify(_ context.Context, n Notification) error {
+	_, err := fmt.Fprintf(o.W, "\x1b]777;notify;%s;%s\x07", oscText(n.Title), oscText(n.Body))
+	return err
+}
+
+// oscText drops characters that would end the escape sequence early.
+func oscText(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\x07' || r == '\x1b' || r == ';' {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// Bell rings the terminal bell, which most terminals turn into an urgency
+// hint or a dock bounce.
+type Bell struct{ W io.Writer }
+
+func (b Bell) Notify(context.Context, Notification) error {
+	_, err := io.WriteString(b.W, "\a")
+	return err
+}
+
+// Desktop shows a notification with notify-send, or osascript on macOS.
+type Desktop struct{}
+
+func (Desktop) Notify(ctx context.Context, n Notification) error {
+	ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "darwin" {
+		script := fmt.Sprintf("display notification %q with title %q", n.Body, n.Title)
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	} else {
+		urgency := "normal"
+		if n.Event != nil && n.Event.Pipeline.Status == valueobject.PipelineFailed {
+			urgency = "critical"
+		}
+		cmd = exec.CommandContext(ctx, "notify-send", "--app-name=glcli", "--urgency="+urgency, n.Title, n.Body)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", cmd.Args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Hook runs a shell command per notification with the details in
+// GLCLI_* environment variables.
+type Hook struct{ Command string }
+
+func (h Hook) Notify(ctx context.Context, n Notification) error {
+	ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", h.Command)
+	cmd.Env = append(os.Environ(),
+		"GLCLI_TITLE="+n.Title,
+		"GLCLI_MESSAGE="+n.Body,
+	)
+	if ev := n.Event; ev != nil {
+		pl := ev.Pipeline
+		cmd.Env = append(cmd.Env,
+			"GLCLI_PROJECT="+pl.ProjectPath,
+			"GLCLI_REF="+pl.Ref,
+			"GLCLI_PIPELINE_ID="+strconv.Itoa(pl.ID),
+			"GLCLI_STATUS="+string(pl.Status),
+			"GLCLI_PREVIOUS_STATUS="+string(ev.Previous),
+			"GLCLI_AUTHOR="+pl.Author,
+			"GLCLI_SOURCE="+pl.Source,
+			"GLCLI_URL="+pl.WebURL,
+		)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("hook: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+// Package notify alerts the user when watched pipelines change status, so
+// glcli can stay minimised. It diffs successive pipeline lists and hands
+// the transitions matching the configured rules to the backends.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/valueobject"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/infrastructure/config"
+)
+
+// maxEvents is how many notifications one refresh may send before the
+// rest are folded into a summary.
+const maxEvents = 3
+
+// Event is a pipeline that reached a status a rule watches.
+type Event struct {
+	Pipeline entity.Pipeline
+	Previous valueobject.PipelineStatus // "" if it appeared already finished
+}
+
+// Notification is what a backend shows.
+type Notification struct {
+	Title string
+	Body  string
+	Event *Event // nil for a summary of several events
+}
+
+// Backend delivers notifications.
+type Backend interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Notifier remembers the last status of every pipeline it has seen. It is
+// not safe for concurrent use; the TUI calls Observe from its update loop.
+type Notifier struct {
+	rules    []config.NotifyRule
+	backends []Backend
+	last     map[int]seenPipeline
+	maxID    int // newest pipeline seen so far
+}
+
+type seenPipeline struct {
+	status  valueobject.PipelineStatus
+	project string
+}
+
+// New returns a notifier for cfg, or nil when no backend is configured.
+// Terminal backends write their escape sequences to tty.
+func New(cfg config.Notifications, tty io.Writer) *Notifier {
+	var backends []Backend
+	for _, name := range cfg.Backends {
+		switch name {
+		case "osc9":
+			backends = append(backends, OSC9{W: tty})
+		case "osc777":
+			backends = append(backends, OSC777{W: tty})
+		case "desktop":
+			backends = append(backends, Desktop{})
+		case "bell":
+			backends = append(backends, Bell{W: tty})
+		case "hook":
+			backends = append(backends, Hook{Command: cfg.Hook})
+		}
+	}
+	if len(backends) == 0 {
+		return nil
+	}
+	rules := cfg.Rules
+	if len(rules) == 0 {
+		rules = []config.NotifyRule{{}}
+	}
+	return &Notifier{rules: rules, backends: backends}
+}
+
+// Observe compares pipelines with the previous call and returns the
+// transitions the rules ask for. The first call only records a baseline,
+// so starting glcli doesn't replay old failures. Pipelines seen for the
+// first time count only if they are newer than any seen before; older
+// ones show up when the list grows or a failed project loads again.
+//
+// Pipelines missing from the list keep their last status while their
+// project is among the failures in partial, or while they are unfinished
+// and only fell behind the per-project limit, so a change that happens
+// meanwhile is still reported once they are listed again.
+func (n *Notifier) Observe(pipelines []entity.Pipeline, partial *entity.PartialError) []Event {
+	if n == nil {
+		return nil
+	}
+	first := n.last == nil
+	last, maxID := n.last, n.maxID
+	n.last = make(map[int]seenPipeline, len(pipelines))
+	oldest := make(map[string]int) // oldest listed pipeline per project
+	var events []Event
+	for _, pl := range pipelines {
+		n.last[pl.ID] = seenPipeline{status: pl.Status, project: pl.ProjectPath}
+		n.maxID = max(n.maxID, pl.ID)
+		if id, ok := oldest[pl.ProjectPath]; !ok || pl.ID < id {
+			oldest[pl.ProjectPath] = pl.ID
+		}
+		if first {
+			continue
+		}
+		prev, seen := last[pl.ID]
+		if prev.status == pl.Status || (!seen && (pl.ID <= maxID || !pl.Status.IsFinished())) {
+			continue
+		}
+		if n.matches(pl) {
+			events = append(events, Event{Pipeline: pl, Previous: prev.status})
+		}
+	}
+
+	failed := make(map[string]bool)
+	if partial != nil {
+		for _, f := range partial.Failed {
+			failed[f.Path] = true
+		}
+	}
+	for id, pl := range last {
+		if _, listed := n.last[id]; listed {
+			continue
+		}
+		if failed[pl.project] || (id < oldest[pl.project] && !pl.status.IsFinished()) {
+			n.last[id] = pl
+		}
+	}
+	return events
+}
+
+func (n *Notifier) matches(pl entity.Pipeline) bool {
+	for _, r := range n.rules {
+		statuses := r.Statuses
+		if len(statuses) == 0 {
+			statuses = []string{string(valueobject.PipelineFailed)}
+		}
+		if slices.Contains(statuses, string(pl.Status)) &&
+			matchGlob(r.Projects, pl.ProjectPath) &&
+			matchGlob(r.Refs, pl.Ref) &&
+			matchAny(r.Authors, pl.Author) &&
+			matchAny(r.Sources, pl.Source) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchGlob(patterns []string, s string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, s); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(values []string, s string) bool {
+	return len(values) == 0 || slices.Contains(values, s)
+}
+
+// Send delivers events through every backend. Failures are logged, not
+// returned: a broken hook shouldn't disturb the TUI.
+func (n *Notifier) Send(ctx context.Context, events []Event) {
+	if n == nil || len(events) == 0 {
+		return
+	}
+	var notes []Notification
+	if len(events) > maxEvents {
+		notes = []Notification{summary(events)}
+	} else {
+		for i := range events {
+			notes = append(notes, notification(&events[i]))
+		}
+	}
+	for _, note := range notes {
+		for _, b := range n.backends {
+			if err := b.Notify(ctx, note); err != nil {
+				log.Printf("[notify] %T: %v", b, err)
+			}
+		}
+	}
+}
+
+func notification(ev *Event) Notification {
+	pl := ev.Pipeline
+	body := fmt.Sprintf("%s %s #%d", pl.ProjectPath, pl.Ref, pl.ID)
+	if pl.Author != "" {
+		body += " by " + pl.Author
+	}
+	if ev.Previous != "" {
+		body += fmt.Sprintf(" (was %s)", ev.Previous)
+	}
+	return Notification{
+		Title: fmt.Sprintf("%s Pipeline %s", pl.Status.Symbol(), pl.Status),
+		Body:  body,
+		Event: ev,
+	}
+}
+
+func summary(events []Event) Notification {
+	counts := make(map[valueobject.PipelineStatus]int)
+	var order []valueobject.PipelineStatus
+	for _, ev := range events {
+		if counts[ev.Pipeline.Status] == 0 {
+			order = append(order, ev.Pipeline.Status)
+		}
+		counts[ev.Pipeline.Status]++
+	}
+	parts := make([]string, len(order))
+	for i, st := range order {
+		parts[i] = fmt.Sprintf("%d %s", counts[st], st)
+	}
+	return Notification{
+		Title: fmt.Sprintf("%d pipelines changed", len(events)),
+		Body:  strings.Join(parts, ", "),
+	}
+}
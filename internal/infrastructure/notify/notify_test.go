@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/valueobject"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/infrastructure/config"
+)
+
+func pipeline(id int, project string, status valueobject.PipelineStatus) entity.Pipeline {
+	return entity.Pipeline{ID: id, ProjectPath: project, Ref: "main", Status: status}
+}
+
+func newTestNotifier(t *testing.T) *Notifier {
+	t.Helper()
+	n := New(config.Notifications{Backends: []string{"bell"}}, io.Discard)
+	if n == nil {
+		t.Fatal("no notifier")
+	}
+	return n
+}
+
+func wantFailure(t *testing.T, events []Event, id int, prev valueobject.PipelineStatus) {
+	t.Helper()
+	if len(events) != 1 || events[0].Pipeline.ID != id || events[0].Previous != prev {
+		t.Fatalf("got %+v, want pipeline %d failing after %s", events, id, prev)
+	}
+}
+
+func TestObserveAcrossFailedRefresh(t *testing.T) {
+	n := newTestNotifier(t)
+	n.Observe([]entity.Pipeline{
+		pipeline(10, "g/a", valueobject.PipelineRunning),
+		pipeline(20, "g/b", valueobject.PipelineRunning),
+	}, nil)
+
+	// g/a fails to load while its pipeline fails
+	partial := &entity.PartialError{Failed: []entity.ProjectError{{Path: "g/a", Err: errors.New("timeout")}}, Total: 2}
+	if events := n.Observe([]entity.Pipeline{pipeline(20, "g/b", valueobject.PipelineRunning)}, partial); len(events) != 0 {
+		t.Fatalf("got %+v during the failed refresh", events)
+	}
+
+	events := n.Observe([]entity.Pipeline{
+		pipeline(10, "g/a", valueobject.PipelineFailed),
+		pipeline(20, "g/b", valueobject.PipelineRunning),
+	}, nil)
+	wantFailure(t, events, 10, valueobject.PipelineRunning)
+}
+
+func TestObserveBeyondLimit(t *testing.T) {
+	n := newTestNotifier(t)
+	n.Observe([]entity.Pipeline{pipeline(10, "g/a", valueobject.PipelineRunning)}, nil)
+
+	// a newer pipeline pushes #10 out of a limit of one
+	if events := n.Observe([]entity.Pipeline{pipeline(11, "g/a", valueobject.PipelineRunning)}, nil); len(events) != 0 {
+		t.Fatalf("got %+v", events)
+	}
+
+	events := n.Observe([]entity.Pipeline{
+		pipeline(11, "g/a", valueobject.PipelineRunning),
+		pipeline(10, "g/a", valueobject.PipelineFailed),
+	}, nil)
+	wantFailure(t, events, 10, valueobject.PipelineRunning)
+}
+
+func TestObserveForgetsDroppedPipelines(t *testing.T) {
+	n := newTestNotifier(t)
+	n.Observe([]entity.Pipeline{
+		pipeline(10, "g/a", valueobject.PipelineFailed),
+		pipeline(11, "g/a", valueobject.PipelineRunning),
+	}, nil)
+	n.Observe([]entity.Pipeline{pipeline(12, "g/a", valueobject.PipelineRunning)}, nil)
+
+	// #11 was still running, #10 had finished
+	if _, ok := n.last[11]; !ok {
+		t.Error("unfinished pipeline behind the limit was forgotten")
+	}
+	if _, ok := n.last[10]; ok {
+		t.Error("finished pipeline behind the limit was kept")
+	}
+}
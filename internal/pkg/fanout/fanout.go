@@ -0,0 +1,40 @@
+// Package fanout runs a function over a list of items with bounded concurrency.
+package fanout
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultLimit is used when a caller passes a non-positive limit.
+const DefaultLimit = 4
+
+// Run calls fn for every item with at most limit calls in flight and
+// returns results and errors in the order of items. A failing item does not
+// stop the others; once ctx is canceled, items not yet started get ctx.Err().
+func Run[T, R any](ctx context.Context, items []T, limit int, fn func(context.Context, T) (R, error)) ([]R, []error) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	results := make([]R, len(items))
+	errs := make([]error, len(items))
+
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func(i int, item T) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i], errs[i] = fn(ctx, item)
+		}(i, item)
+	}
+	wg.Wait()
+	return results, errs
+}
@@ -0,0 +1,234 @@
+// Package cli implements glcli's non-interactive subcommands for use in
+// scripts and Makefiles. They share config, auth and services with the TUI.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/bearlogin/gitlab-awesome-cli/internal/bootstrap"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+)
+
+// Exit codes.
+const (
+	ExitOK      = 0
+	ExitError   = 1 // GitLab request or runtime failure
+	ExitUsage   = 2 // bad arguments
+	ExitPartial = 3 // some projects failed to load; output covers the rest
+	ExitFailed  = 4 // wait: the pipeline failed, was canceled or skipped
+	ExitTimeout = 5 // wait: --timeout elapsed first
+	ExitManual  = 6 // wait: the pipeline is blocked on a manual job
+)
+
+// usageError marks errors caused by the command line rather than GitLab.
+type usageError struct{ msg string }
+
+func (e usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return usageError{fmt.Sprintf(format, args...)}
+}
+
+// cmdContext carries what every command needs.
+type cmdContext struct {
+	ctx    context.Context
+	env    *bootstrap.Env
+	stdout io.Writer
+	stderr io.Writer
+}
+
+type command struct {
+	name    string // "pipelines list"
+	usage   string // arguments after the name
+	summary string
+	run     func(c *cmdContext, args []string) error
+}
+
+var commands = []command{
+	{"projects list", "", "List configured projects with pipeline counts", projectsList},
+	{"pipelines list", "[--project P] [--status S] [--ref R] [--limit N]", "List pipelines across configured projects", pipelinesList},
+	{"jobs list", "--project P <pipeline-id>", "List jobs of a pipeline", jobsList},
+	{"jobs play", "--project P <job-id>", "Start a manual job", jobAction("play")},
+	{"jobs retry", "--project P <job-id>", "Retry a job", jobAction("retry")},
+	{"jobs cancel", "--project P <job-id>", "Cancel a running job", jobAction("cancel")},
+	{"pipelines retry", "--project P <pipeline-id>", "Retry all failed jobs of a pipeline", pipelineAction("retry")},
+	{"pipelines cancel", "--project P <pipeline-id>", "Cancel a running pipeline", pipelineAction("cancel")},
+	{"log", "--project P [--color] <job-id>", "Print a job log", jobLog},
+	{"mr list", "[--project P] [--state opened|merged|closed|all] [--limit N]", "List merge requests", mrList},
+	{"mr create", "--project P --source B --target B --title T [--description D] [--draft]", "Create a merge request", mrCreate},
+	{"mr approve", "--project P <iid>", "Approve a merge request", mrApprove},
+	{"mr merge", "--project P [--when-pipeline-succeeds] [--squash] [--remove-source-branch] [--sha S] <iid>", "Merge a merge request", mrMerge},
+	{"daemon", "[--socket PATH] [--interval D]", "Poll GitLab and stream events as NDJSON on a Unix socket", runDaemon},
+	{"events", "[--socket PATH] [--type T,...]", "Print events from a running daemon", streamEvents},
+	{"wait", "[--project P] [--ref R | --sha S | <pipeline-id>] [--timeout D] [--log N]", "Block until a pipeline finishes (default: the HEAD commit)", waitPipeline},
+}
+
+// IsCommand reports whether args start with a known subcommand, so main
+// can decide between headless mode and the TUI.
+func IsCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	if args[0] == "help" {
+		return true
+	}
+	_, _, ok := findCommand(args)
+	return ok || isGroup(args[0])
+}
+
+func findCommand(args []string) (command, []string, bool) {
+	for _, cmd := range commands {
+		words := strings.Fields(cmd.name)
+		if len(args) >= len(words) && slices.Equal(args[:len(words)], words) {
+			return cmd, args[len(words):], true
+		}
+	}
+	return command{}, nil, false
+}
+
+func isGroup(word string) bool {
+	for _, cmd := range commands {
+		if strings.HasPrefix(cmd.name, word+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// Usage writes the list of subcommands.
+func Usage(w io.Writer) {
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\n      %s\n", strings.TrimSpace("glcli "+cmd.name+" "+cmd.usage), cmd.summary)
+	}
+	fmt.Fprintln(w, "\nEvery command accepts --output table|json|yaml (-o).")
+	fmt.Fprintf(w, "Exit codes: %d ok, %d error, %d usage, %d partial failure, %d pipeline failed, %d timeout, %d blocked on a manual job.\n",
+		ExitOK, ExitError, ExitUsage, ExitPartial, ExitFailed, ExitTimeout, ExitManual)
+}
+
+// Run executes a subcommand and returns the process exit code.
+func Run(env *bootstrap.Env, args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == "help" {
+		Usage(stdout)
+		return ExitOK
+	}
+	cmd, rest, ok := findCommand(args)
+	if !ok {
+		fmt.Fprintf(stderr, "glcli: unknown command %q\n\n", strings.Join(args, " "))
+		Usage(stderr)
+		return ExitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	c := &cmdContext{ctx: ctx, env: env, stdout: stdout, stderr: stderr}
+	err := cmd.run(c, rest)
+	return exitCode(err, stderr, cmd.name)
+}
+
+func exitCode(err error, stderr io.Writer, name string) int {
+	if err == nil {
+		return ExitOK
+	}
+	if partial, _ := entity.AsPartial(err); partial != nil {
+		fmt.Fprintf(stderr, "glcli %s: %v\n", name, partial)
+		for _, f := range partial.Failed {
+			fmt.Fprintf(stderr, "  %s: %v\n", f.Path, f.Err)
+		}
+		return ExitPartial
+	}
+	var usage usageError
+	if errors.As(err, &usage) || errors.Is(err, flag.ErrHelp) {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(stderr, "glcli %s: %v\n", name, err)
+		}
+		return ExitUsage
+	}
+	var code exitError
+	if errors.As(err, &code) {
+		return int(code)
+	}
+	fmt.Fprintf(stderr, "glcli %s: %v\n", name, err)
+	return ExitError
+}
+
+// exitError lets a command exit with a specific code without printing,
+// after it has reported the outcome itself.
+type exitError int
+
+func (e exitError) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+
+// newFlags creates a flag set with the shared --output flag.
+func newFlags(c *cmdContext, name string) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet("glcli "+name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	output := fs.String("output", "table", "output format: table, json or yaml")
+	fs.StringVar(output, "o", "table", "shorthand for --output")
+	return fs, output
+}
+
+// parseFlags parses args allowing flags after positional arguments, as in
+// "glcli log 123 --project group/app".
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// intArg parses the single positional ID a command expects.
+func intArg(args []string, what string) (int, error) {
+	if len(args) != 1 {
+		return 0, usagef("expected exactly one %s", what)
+	}
+	n, err := strconv.Atoi(strings.TrimLeft(args[0], "#!"))
+	if err != nil {
+		return 0, usagef("invalid %s %q", what, args[0])
+	}
+	return n, nil
+}
+
+// resolveProject turns --project (a path or numeric ID) into a project.
+// Without --project the only configured project is used, or the one
+// matching the git remote of the current directory.
+func (c *cmdContext) resolveProject(ref string) (*entity.Project, error) {
+	if ref == "" {
+		if configured := c.env.Config.Projects; len(configured) == 1 {
+			ref = configured[0]
+		} else {
+			ref = projectFromRemote(c.ctx, configured)
+		}
+		if ref == "" {
+			return nil, usagef("--project is required when %d projects are configured", len(c.env.Config.Projects))
+		}
+	}
+	if id, err := strconv.Atoi(ref); err == nil {
+		return &entity.Project{ID: id, PathWithNS: ref}, nil
+	}
+	projects, err := c.env.Pipelines.ResolveProjects(c.ctx, []string{ref})
+	if err != nil {
+		return nil, err
+	}
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("project %q not found", ref)
+	}
+	return &projects[0], nil
+}
@@ -0,0 +1,386 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/joblog"
+)
+
+func projectsList(c *cmdContext, args []string) error {
+	fs, output := newFlags(c, "projects list")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*output); err != nil {
+		return err
+	}
+	projects, loadErr := c.env.Pipelines.LoadProjects(c.ctx, c.env.Config.Projects)
+	if _, err := entity.AsPartial(loadErr); err != nil {
+		return err
+	}
+	err := write(c.stdout, *output, mapSlice(projects, toProjectOut), func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "ID\tPROJECT\tPIPELINES\tACTIVE")
+		for _, p := range projects {
+			fmt.Fprintf(tw, "%d\t%s\t%d\t%d\n", p.ID, p.PathWithNS, p.PipelineCount, p.ActiveCount)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return loadErr
+}
+
+func pipelinesList(c *cmdContext, args []string) error {
+	fs, output := newFlags(c, "pipelines list")
+	project := fs.String("project", "", "project path; default: all configured projects")
+	status := fs.String("status", "", "only pipelines with this status")
+	ref := fs.String("ref", "", "only pipelines for this branch or tag")
+	limit := fs.Int("limit", c.env.Config.PipelineLimit, "pipelines per project")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*output); err != nil {
+		return err
+	}
+	paths := c.env.Config.Projects
+	if *project != "" {
+		paths = []string{*project}
+	}
+	pipelines, loadErr := c.env.Pipelines.LoadAllPipelines(c.ctx, paths, *limit)
+	if _, err := entity.AsPartial(loadErr); err != nil {
+		return err
+	}
+	filtered := pipelines[:0]
+	for _, p := range pipelines {
+		if *status != "" && string(p.Status) != *status {
+			continue
+		}
+		if *ref != "" && p.Ref != *ref {
+			continue
+		}
+		filtered = append(filtered, p)
+	}
+	err := write(c.stdout, *output, mapSlice(filtered, toPipelineOut), func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "ID\tPROJECT\tREF\tSHA\tSTATUS\tDURATION\tCREATED")
+		for _, p := range filtered {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				p.ID, p.ProjectPath, p.Ref, shortSHA(p.SHA), p.Status, formatSeconds(p.Duration), ago(p.CreatedAt))
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return loadErr
+}
+
+// pipelineAction builds the pipeline retry/cancel commands.
+func pipelineAction(action string) func(c *cmdContext, args []string) error {
+	return func(c *cmdContext, args []string) error {
+		fs, output := newFlags(c, "pipelines "+action)
+		projectRef := fs.String("project", "", "project path or ID")
+		pos, err := parseFlags(fs, args)
+		if err != nil {
+			return err
+		}
+		if err := checkFormat(*output); err != nil {
+			return err
+		}
+		pipelineID, err := intArg(pos, "pipeline ID")
+		if err != nil {
+			return err
+		}
+		project, err := c.resolveProject(*projectRef)
+		if err != nil {
+			return err
+		}
+		var pl *entity.Pipeline
+		switch action {
+		case "retry":
+			pl, err = c.env.Pipelines.RetryPipeline(c.ctx, project.ID, pipelineID)
+		case "cancel":
+			pl, err = c.env.Pipelines.CancelPipeline(c.ctx, project.ID, pipelineID)
+		}
+		if err != nil {
+			return err
+		}
+		pl.ProjectPath = project.PathWithNS
+		return write(c.stdout, *output, toPipelineOut(*pl), func(tw *tabwriter.Writer) {
+			fmt.Fprintln(tw, "ID\tPROJECT\tREF\tSTATUS\tURL")
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", pl.ID, pl.ProjectPath, pl.Ref, pl.Status, pl.WebURL)
+		})
+	}
+}
+
+func jobsList(c *cmdContext, args []string) error {
+	fs, output := newFlags(c, "jobs list")
+	projectRef := fs.String("project", "", "project path or ID")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := checkFormat(*output); err != nil {
+		return err
+	}
+	pipelineID, err := intArg(pos, "pipeline ID")
+	if err != nil {
+		return err
+	}
+	project, err := c.resolveProject(*projectRef)
+	if err != nil {
+		return err
+	}
+	jobs, err := c.env.Pipelines.ListJobs(c.ctx, project.ID, pipelineID)
+	if err != nil {
+		return err
+	}
+	return writeJobs(c.stdout, *output, jobs)
+}
+
+func writeJobs(w io.Writer, format string, jobs []entity.Job) error {
+	return write(w, format, mapSlice(jobs, toJobOut), func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "ID\tSTAGE\tNAME\tSTATUS\tDURATION")
+		for _, j := range jobs {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", j.ID, j.Stage, j.Name, j.Status, formatSeconds(int(j.Duration)))
+		}
+	})
+}
+
+// jobAction builds the play/retry/cancel commands, which only differ in
+// the service call.
+func jobAction(action string) func(c *cmdContext, args []string) error {
+	return func(c *cmdContext, args []string) error {
+		fs, output := newFlags(c, "jobs "+action)
+		projectRef := fs.String("project", "", "project path or ID")
+		pos, err := parseFlags(fs, args)
+		if err != nil {
+			return err
+		}
+		if err := checkFormat(*output); err != nil {
+			return err
+		}
+		jobID, err := intArg(pos, "job ID")
+		if err != nil {
+			return err
+		}
+		project, err := c.resolveProject(*projectRef)
+		if err != nil {
+			return err
+		}
+		var job *entity.Job
+		switch action {
+		case "play":
+			job, err = c.env.Jobs.PlayJob(c.ctx, project.ID, jobID)
+		case "retry":
+			job, err = c.env.Jobs.RetryJob(c.ctx, project.ID, jobID)
+		case "cancel":
+			job, err = c.env.Jobs.CancelJob(c.ctx, project.ID, jobID)
+		}
+		if err != nil {
+			return err
+		}
+		return writeJobs(c.stdout, *output, []entity.Job{*job})
+	}
+}
+
+func jobLog(c *cmdContext, args []string) error {
+	fs, _ := newFlags(c, "log")
+	projectRef := fs.String("project", "", "project path or ID")
+	color := fs.Bool("color", false, "keep ANSI colours")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	jobID, err := intArg(pos, "job ID")
+	if err != nil {
+		return err
+	}
+	project, err := c.resolveProject(*projectRef)
+	if err != nil {
+		return err
+	}
+	rc, err := c.env.Jobs.GetJobLog(c.ctx, project.ID, jobID)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+	for _, ln := range joblog.Parse(string(data)).Lines {
+		text := ln.Text
+		if !*color {
+			text = joblog.StripANSI(text)
+		}
+		fmt.Fprintln(c.stdout, text)
+	}
+	return nil
+}
+
+func mrList(c *cmdContext, args []string) error {
+	fs, output := newFlags(c, "mr list")
+	projectRef := fs.String("project", "", "project path or ID; default: all configured projects")
+	state := fs.String("state", "opened", "opened, merged, closed or all")
+	limit := fs.Int("limit", 50, "merge requests per project; 0 for all")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*output); err != nil {
+		return err
+	}
+
+	var projects []entity.Project
+	var resolveErr error
+	if *projectRef != "" {
+		project, err := c.resolveProject(*projectRef)
+		if err != nil {
+			return err
+		}
+		projects = []entity.Project{*project}
+	} else {
+		projects, resolveErr = c.env.Pipelines.ResolveProjects(c.ctx, c.env.Config.Projects)
+		if _, err := entity.AsPartial(resolveErr); err != nil {
+			return err
+		}
+	}
+	mrs, loadErr := c.env.MRs.ListProjectsMRs(c.ctx, projects, *state, *limit)
+	if _, err := entity.AsPartial(loadErr); err != nil {
+		return err
+	}
+	err := write(c.stdout, *output, mapSlice(mrs, toMROut), func(tw *tabwriter.Writer) {
+		fmt.Fprintln(tw, "IID\tPROJECT\tTITLE\tAUTHOR\tBRANCHES\tUPDATED")
+		for _, mr := range mrs {
+			fmt.Fprintf(tw, "!%d\t%s\t%s\t%s\t%s → %s\t%s\n",
+				mr.IID, mr.ProjectPath, truncate(mr.Title, 50), mr.Author, mr.SourceBranch, mr.TargetBranch, ago(mr.UpdatedAt))
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if resolveErr != nil {
+		return resolveErr
+	}
+	return loadErr
+}
+
+func writeMR(w io.Writer, format string, mr *entity.MergeRequest) error {
+	return write(w, format, toMROut(*mr), func(tw *tabwriter.Writer) {
+		fmt.Fprintf(tw, "MR\t!%d\n", mr.IID)
+		fmt.Fprintf(tw, "Title\t%s\n", mr.Title)
+		if mr.AutoMerge && mr.State == "opened" {
+			fmt.Fprintf(tw, "State\t%s, merges when the pipeline succeeds\n", mr.State)
+		} else {
+			fmt.Fprintf(tw, "State\t%s\n", mr.State)
+		}
+		fmt.Fprintf(tw, "Branches\t%s → %s\n", mr.SourceBranch, mr.TargetBranch)
+		fmt.Fprintf(tw, "URL\t%s\n", mr.WebURL)
+	})
+}
+
+func mrCreate(c *cmdContext, args []string) error {
+	fs, output := newFlags(c, "mr create")
+	projectRef := fs.String("project", "", "project path or ID")
+	var opts entity.CreateMROptions
+	fs.StringVar(&opts.SourceBranch, "source", "", "source branch")
+	fs.StringVar(&opts.TargetBranch, "target", "", "target branch")
+	fs.StringVar(&opts.Title, "title", "", "title")
+	fs.StringVar(&opts.Description, "description", "", "description")
+	fs.BoolVar(&opts.Draft, "draft", false, "create as draft")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*output); err != nil {
+		return err
+	}
+	if opts.SourceBranch == "" || opts.TargetBranch == "" || opts.Title == "" {
+		return usagef("--source, --target and --title are required")
+	}
+	project, err := c.resolveProject(*projectRef)
+	if err != nil {
+		return err
+	}
+	mr, err := c.env.MRs.CreateMR(c.ctx, project.ID, opts)
+	if err != nil {
+		return err
+	}
+	return writeMR(c.stdout, *output, mr)
+}
+
+func mrApprove(c *cmdContext, args []string) error {
+	fs, output := newFlags(c, "mr approve")
+	projectRef := fs.String("project", "", "project path or ID")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := checkFormat(*output); err != nil {
+		return err
+	}
+	iid, err := intArg(pos, "merge request IID")
+	if err != nil {
+		return err
+	}
+	project, err := c.resolveProject(*projectRef)
+	if err != nil {
+		return err
+	}
+	if err := c.env.MRs.ApproveMR(c.ctx, project.ID, iid); err != nil {
+		return err
+	}
+	mr, err := c.env.MRs.GetMR(c.ctx, project.ID, iid)
+	if err != nil {
+		return err
+	}
+	return writeMR(c.stdout, *output, mr)
+}
+
+func mrMerge(c *cmdContext, args []string) error {
+	fs, output := newFlags(c, "mr merge")
+	projectRef := fs.String("project", "", "project path or ID")
+	var opts entity.MergeOptions
+	fs.BoolVar(&opts.WhenPipelineSucceeds, "when-pipeline-succeeds", false, "set auto-merge instead of merging now")
+	fs.BoolVar(&opts.Squash, "squash", false, "squash commits (default: the MR's setting)")
+	fs.StringVar(&opts.SquashCommitMessage, "squash-message", "", "squash commit message")
+	fs.StringVar(&opts.MergeCommitMessage, "message", "", "merge commit message")
+	fs.BoolVar(&opts.RemoveSourceBranch, "remove-source-branch", false, "delete the source branch (default: the MR's setting)")
+	fs.StringVar(&opts.SHA, "sha", "", "merge only if the source branch head is this commit")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := checkFormat(*output); err != nil {
+		return err
+	}
+	iid, err := intArg(pos, "merge request IID")
+	if err != nil {
+		return err
+	}
+	project, err := c.resolveProject(*projectRef)
+	if err != nil {
+		return err
+	}
+	// Flags left unset keep what the author chose on the MR
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !set["squash"] || !set["remove-source-branch"] {
+		current, err := c.env.MRs.GetMR(c.ctx, project.ID, iid)
+		if err != nil {
+			return err
+		}
+		if !set["squash"] {
+			opts.Squash = current.Squash
+		}
+		if !set["remove-source-branch"] {
+			opts.RemoveSourceBranch = current.RemoveSource
+		}
+	}
+	mr, err := c.env.MRs.MergeMR(c.ctx, project.ID, iid, opts)
+	if err != nil {
+		return err
+	}
+	return writeMR(c.stdout, *output, mr)
+}
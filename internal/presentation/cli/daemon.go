@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bearlogin/gitlab-awesome-cli/internal/presentation/daemon"
+)
+
+func runDaemon(c *cmdContext, args []string) error {
+	fs, _ := newFlags(c, "daemon")
+	socket := fs.String("socket", daemon.DefaultSocketPath(), "Unix socket to publish events on")
+	interval := fs.Duration("interval", c.env.Config.RefreshInterval, "poll interval")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *interval < time.Second {
+		return usagef("--interval must be at least 1s")
+	}
+	return daemon.Run(c.ctx, c.env, *socket, *interval, c.stderr)
+}
+
+// streamEvents prints events from a running daemon until it exits or the
+// command is interrupted.
+func streamEvents(c *cmdContext, args []string) error {
+	fs, output := newFlags(c, "events")
+	socket := fs.String("socket", daemon.DefaultSocketPath(), "Unix socket of the daemon")
+	types := fs.String("type", "", "comma-separated event types to print (default: all)")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*output); err != nil {
+		return err
+	}
+	if *output == FormatYAML {
+		return usagef("events are a stream; use --output table or json")
+	}
+	var want []string
+	if *types != "" {
+		want = strings.Split(*types, ",")
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(c.ctx, "unix", *socket)
+	if err != nil {
+		return fmt.Errorf("no daemon on %s (start one with glcli daemon): %w", *socket, err)
+	}
+	go func() {
+		<-c.ctx.Done()
+		conn.Close()
+	}()
+
+	sc := bufio.NewScanner(conn)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		var ev daemon.Event
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			return fmt.Errorf("bad event from daemon: %w", err)
+		}
+		if want != nil && !slices.Contains(want, ev.Type) {
+			continue
+		}
+		if *output == FormatJSON {
+			fmt.Fprintf(c.stdout, "%s\n", sc.Bytes())
+		} else {
+			fmt.Fprintln(c.stdout, formatEvent(ev))
+		}
+	}
+	if c.ctx.Err() != nil {
+		return nil
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("daemon closed the connection")
+}
+
+func formatEvent(ev daemon.Event) string {
+	ts := ev.Time.Local().Format("15:04:05")
+	switch {
+	case ev.Pipeline != nil:
+		pl := ev.Pipeline
+		change := string(pl.Status)
+		if ev.Previous != "" {
+			change = ev.Previous + " → " + change
+		}
+		return fmt.Sprintf("%s %s %s %s #%d %s", ts, pl.Status.Symbol(), pl.ProjectPath, pl.Ref, pl.ID, change)
+	case ev.MergeRequest != nil:
+		mr := ev.MergeRequest
+		ref := fmt.Sprintf("%s!%d", mr.ProjectPath, mr.IID)
+		switch ev.Type {
+		case daemon.EventMROpened:
+			return fmt.Sprintf("%s new MR %s %q by %s", ts, ref, truncate(mr.Title, 60), mr.Author)
+		case daemon.EventApproval:
+			return fmt.Sprintf("%s %s approved by %s", ts, ref, ev.Note.Author)
+		case daemon.EventNote:
+			return fmt.Sprintf("%s %s %s: %s", ts, ref, ev.Note.Author, truncate(strings.Join(strings.Fields(ev.Note.Body), " "), 80))
+		}
+	case ev.Type == daemon.EventError:
+		return fmt.Sprintf("%s error: %s", ts, ev.Error)
+	}
+	return fmt.Sprintf("%s %s", ts, ev.Type)
+}
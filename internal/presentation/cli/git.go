@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"context"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// git runs a git command in the working directory and returns its trimmed
+// output, or "" when git is missing or the directory is not a repository.
+func git(ctx context.Context, args ...string) string {
+	out, err := exec.CommandContext(ctx, "git", args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// remoteProjectPath extracts "group/app" from a git remote URL in any of
+// the usual forms: https://host/group/app.git, git@host:group/app.git or
+// ssh://git@host:22/group/app.git.
+func remoteProjectPath(remote string) string {
+	var path string
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return ""
+		}
+		path = u.Path
+	} else if _, after, ok := strings.Cut(remote, ":"); ok {
+		path = after
+	}
+	return strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+}
+
+// projectFromRemote returns the configured project matching the origin
+// remote of the current git checkout, if any.
+func projectFromRemote(ctx context.Context, configured []string) string {
+	path := remoteProjectPath(git(ctx, "remote", "get-url", "origin"))
+	if path == "" {
+		return ""
+	}
+	for _, p := range configured {
+		if strings.EqualFold(p, path) {
+			return p
+		}
+	}
+	return ""
+}
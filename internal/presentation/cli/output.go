@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats.
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+)
+
+func checkFormat(format string) error {
+	switch format {
+	case FormatTable, FormatJSON, FormatYAML:
+		return nil
+	}
+	return usagef("invalid --output %q (want table, json or yaml)", format)
+}
+
+// write renders v as JSON or YAML, or calls table with a tabwriter.
+func write(w io.Writer, format string, v any, table func(tw *tabwriter.Writer)) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		defer enc.Close()
+		return enc.Encode(v)
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	table(tw)
+	return tw.Flush()
+}
+
+// Stable, snake_case views of the entities for machine-readable output.
+
+type projectOut struct {
+	ID        int    `json:"id" yaml:"id"`
+	Path      string `json:"path" yaml:"path"`
+	WebURL    string `json:"web_url" yaml:"web_url"`
+	Pipelines int    `json:"pipelines" yaml:"pipelines"`
+	Active    int    `json:"active" yaml:"active"`
+}
+
+type pipelineOut struct {
+	ID        int       `json:"id" yaml:"id"`
+	ProjectID int       `json:"project_id" yaml:"project_id"`
+	Project   string    `json:"project" yaml:"project"`
+	Ref       string    `json:"ref" yaml:"ref"`
+	SHA       string    `json:"sha,omitempty" yaml:"sha,omitempty"`
+	Status    string    `json:"status" yaml:"status"`
+	Source    string    `json:"source,omitempty" yaml:"source,omitempty"`
+	Author    string    `json:"author,omitempty" yaml:"author,omitempty"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+	Duration  int       `json:"duration" yaml:"duration"`
+	WebURL    string    `json:"web_url,omitempty" yaml:"web_url,omitempty"`
+}
+
+type jobOut struct {
+	ID         int        `json:"id" yaml:"id"`
+	PipelineID int        `json:"pipeline_id" yaml:"pipeline_id"`
+	ProjectID  int        `json:"project_id" yaml:"project_id"`
+	Name       string     `json:"name" yaml:"name"`
+	Stage      string     `json:"stage" yaml:"stage"`
+	Status     string     `json:"status" yaml:"status"`
+	Duration   float64    `json:"duration" yaml:"duration"`
+	StartedAt  *time.Time `json:"started_at,omitempty" yaml:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" yaml:"finished_at,omitempty"`
+	WebURL     string     `json:"web_url,omitempty" yaml:"web_url,omitempty"`
+	// Set for trigger jobs once they have started their pipeline
+	Downstream *downstreamOut `json:"downstream_pipeline,omitempty" yaml:"downstream_pipeline,omitempty"`
+}
+
+type downstreamOut struct {
+	ID        int    `json:"id" yaml:"id"`
+	ProjectID int    `json:"project_id" yaml:"project_id"`
+	Status    string `json:"status" yaml:"status"`
+	WebURL    string `json:"web_url,omitempty" yaml:"web_url,omitempty"`
+}
+
+type mrOut struct {
+	IID          int       `json:"iid" yaml:"iid"`
+	ProjectID    int       `json:"project_id" yaml:"project_id"`
+	Project      string    `json:"project" yaml:"project"`
+	Title        string    `json:"title" yaml:"title"`
+	State        string    `json:"state" yaml:"state"`
+	Author       string    `json:"author" yaml:"author"`
+	SourceBranch string    `json:"source_branch" yaml:"source_branch"`
+	TargetBranch string    `json:"target_branch" yaml:"target_branch"`
+	MergeStatus  string    `json:"merge_status,omitempty" yaml:"merge_status,omitempty"`
+	Draft        bool      `json:"draft" yaml:"draft"`
+	AutoMerge    bool      `json:"auto_merge,omitempty" yaml:"auto_merge,omitempty"`
+	Unresolved   int       `json:"unresolved_threads,omitempty" yaml:"unresolved_threads,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at" yaml:"updated_at"`
+	WebURL       string    `json:"web_url" yaml:"web_url"`
+}
+
+func toProjectOut(p entity.Project) projectOut {
+	return projectOut{ID: p.ID, Path: p.PathWithNS, WebURL: p.WebURL, Pipelines: p.PipelineCount, Active: p.ActiveCount}
+}
+
+func toPipelineOut(p entity.Pipeline) pipelineOut {
+	return pipelineOut{
+		ID: p.ID, ProjectID: p.ProjectID, Project: p.ProjectPath, Ref: p.Ref, SHA: p.SHA,
+		Status: string(p.Status), Source: p.Source, Author: p.Author,
+		CreatedAt: p.CreatedAt, Duration: p.Duration, WebURL: p.WebURL,
+	}
+}
+
+func toJobOut(j entity.Job) jobOut {
+	out := jobOut{
+		ID: j.ID, PipelineID: j.PipelineID, ProjectID: j.ProjectID, Name: j.Name, Stage: j.Stage,
+		Status: string(j.Status), Duration: j.Duration, StartedAt: j.StartedAt, FinishedAt: j.FinishedAt,
+		WebURL: j.WebURL,
+	}
+	if d := j.Downstream; d != nil {
+		out.Downstream = &downstreamOut{ID: d.ID, ProjectID: d.ProjectID, Status: string(d.Status), WebURL: d.WebURL}
+	}
+	return out
+}
+
+func toMROut(mr entity.MergeRequest) mrOut {
+	return mrOut{
+		IID: mr.IID, ProjectID: mr.ProjectID, Project: mr.ProjectPath, Title: mr.Title, State: mr.State,
+		Author: mr.Author, SourceBranch: mr.SourceBranch, TargetBranch: mr.TargetBranch,
+		MergeStatus: mr.MergeStatus, Draft: mr.Draft, AutoMerge: mr.AutoMerge, Unresolved: mr.UnresolvedThreads, UpdatedAt: mr.UpdatedAt, WebURL: mr.WebURL,
+	}
+}
+
+func mapSlice[T, R any](items []T, fn func(T) R) []R {
+	out := make([]R, len(items))
+	for i, item := range items {
+		out[i] = fn(item)
+	}
+	return out
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+func formatSeconds(secs int) string {
+	if secs <= 0 {
+		return "-"
+	}
+	return (time.Duration(secs) * time.Second).String()
+}
+
+func ago(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	d := time.Since(t).Round(time.Minute)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
+
+func truncate(s string, n int) string {
+	r := []rune(strings.TrimSpace(s))
+	if len(r) <= n {
+		return string(r)
+	}
+	return string(r[:n-1]) + "…"
+}
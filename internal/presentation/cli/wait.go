@@ -0,0 +1,284 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/joblog"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/valueobject"
+)
+
+// waitMaxErrors is how many polls in a row may fail before wait gives up,
+// so a network blip doesn't abort a long wait.
+const waitMaxErrors = 3
+
+// waitEvent is one line of `glcli wait -o json` output.
+type waitEvent struct {
+	Type     string       `json:"type"` // pipeline, job, finished, log
+	Time     time.Time    `json:"time"`
+	Pipeline *pipelineOut `json:"pipeline,omitempty"`
+	Job      *jobOut      `json:"job,omitempty"`
+	Previous string       `json:"previous,omitempty"`
+	Failed   []jobOut     `json:"failed_jobs,omitempty"`
+	Log      []string     `json:"log,omitempty"`
+}
+
+// waiter polls one pipeline and reports status transitions.
+type waiter struct {
+	c        *cmdContext
+	json     bool
+	project  *entity.Project
+	interval time.Duration
+	logLines int
+
+	pipelineID int
+	ref, sha   string
+
+	lastPipeline valueobject.PipelineStatus
+	jobs         map[int]valueobject.JobStatus
+	announced    bool
+}
+
+func waitPipeline(c *cmdContext, args []string) error {
+	fs, output := newFlags(c, "wait")
+	projectRef := fs.String("project", "", "project path or ID")
+	ref := fs.String("ref", "", "wait for the latest pipeline of this branch or tag")
+	sha := fs.String("sha", "", "wait for the latest pipeline of this commit")
+	interval := fs.Duration("interval", 5*time.Second, "poll interval")
+	timeout := fs.Duration("timeout", 0, "give up after this long; 0 waits forever")
+	logLines := fs.Int("log", 0, "print the last N log lines of each failed job")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := checkFormat(*output); err != nil {
+		return err
+	}
+	if *output == FormatYAML {
+		return usagef("wait streams events; use --output table or json")
+	}
+	if *interval < time.Second {
+		return usagef("--interval must be at least 1s")
+	}
+
+	w := &waiter{
+		c:        c,
+		json:     *output == FormatJSON,
+		interval: *interval,
+		logLines: *logLines,
+		ref:      *ref,
+		sha:      *sha,
+		jobs:     make(map[int]valueobject.JobStatus),
+	}
+	switch {
+	case len(pos) > 0:
+		if *ref != "" || *sha != "" {
+			return usagef("give either a pipeline ID or --ref/--sha, not both")
+		}
+		if w.pipelineID, err = intArg(pos, "pipeline ID"); err != nil {
+			return err
+		}
+	case *ref == "" && *sha == "":
+		// Right after `git push`: wait for the commit that was just pushed
+		w.sha = git(c.ctx, "rev-parse", "HEAD")
+		if w.sha == "" {
+			return usagef("give a pipeline ID, --ref or --sha, or run inside a git checkout")
+		}
+	}
+	if w.project, err = c.resolveProject(*projectRef); err != nil {
+		return err
+	}
+
+	ctx := c.ctx
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	err = w.run(ctx)
+	if errors.Is(err, context.DeadlineExceeded) && c.ctx.Err() == nil {
+		fmt.Fprintf(c.stderr, "glcli wait: timed out after %s\n", *timeout)
+		return exitError(ExitTimeout)
+	}
+	return err
+}
+
+func (w *waiter) run(ctx context.Context) error {
+	failures := 0
+	for {
+		pl, err := w.poll(ctx)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil:
+			failures++
+			if failures >= waitMaxErrors {
+				return err
+			}
+			fmt.Fprintf(w.c.stderr, "glcli wait: %v (retrying)\n", err)
+		default:
+			failures = 0
+		}
+		if pl != nil && pl.Status.IsFinished() {
+			return w.finish(ctx, pl)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(w.interval):
+		}
+	}
+}
+
+// poll fetches the pipeline and its jobs and reports what changed. It
+// returns nil while no pipeline exists for the ref or commit yet.
+func (w *waiter) poll(ctx context.Context) (*entity.Pipeline, error) {
+	svc := w.c.env.Pipelines
+	var pl *entity.Pipeline
+	var err error
+	if w.pipelineID != 0 {
+		pl, err = svc.GetPipeline(ctx, w.project.ID, w.pipelineID)
+	} else {
+		pl, err = svc.LatestPipeline(ctx, w.project.ID, w.ref, w.sha)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if pl == nil {
+		if !w.announced {
+			w.announced = true
+			w.textf("waiting for a pipeline for %s in %s…\n", w.target(), w.project.PathWithNS)
+		}
+		return nil, nil
+	}
+	// Stick to the first pipeline found even if a newer one appears
+	w.pipelineID = pl.ID
+	pl.ProjectPath = w.project.PathWithNS
+
+	jobs, err := svc.ListJobs(ctx, w.project.ID, pl.ID)
+	if err != nil {
+		return nil, err
+	}
+	if pl.Status != w.lastPipeline {
+		w.emit(waitEvent{Type: "pipeline", Pipeline: ptr(toPipelineOut(*pl)), Previous: string(w.lastPipeline)})
+		if w.lastPipeline == "" {
+			w.textf("%s pipeline #%d %s (%s %s) %s\n", stamp(), pl.ID, pl.Status, pl.Ref, shortSHA(pl.SHA), pl.WebURL)
+		} else {
+			w.textf("%s pipeline #%d %s → %s\n", stamp(), pl.ID, w.lastPipeline, pl.Status)
+		}
+		w.lastPipeline = pl.Status
+	}
+	for _, j := range jobs {
+		prev, seen := w.jobs[j.ID]
+		if seen && prev == j.Status {
+			continue
+		}
+		w.jobs[j.ID] = j.Status
+		w.emit(waitEvent{Type: "job", Job: ptr(toJobOut(j)), Previous: string(prev)})
+		if seen {
+			w.textf("%s   %s %s/%s %s → %s\n", stamp(), j.Status.Symbol(), j.Stage, j.Name, prev, j.Status)
+		} else {
+			w.textf("%s   %s %s/%s %s\n", stamp(), j.Status.Symbol(), j.Stage, j.Name, j.Status)
+		}
+	}
+	return pl, nil
+}
+
+// finish prints the outcome and maps it to an exit code.
+func (w *waiter) finish(ctx context.Context, pl *entity.Pipeline) error {
+	jobs, err := w.c.env.Pipelines.ListJobs(ctx, w.project.ID, pl.ID)
+	if err != nil {
+		return err
+	}
+	var failed []entity.Job
+	for _, j := range jobs {
+		if j.Status == valueobject.JobFailed {
+			failed = append(failed, j)
+		}
+	}
+
+	w.emit(waitEvent{Type: "finished", Pipeline: ptr(toPipelineOut(*pl)), Failed: mapSlice(failed, toJobOut)})
+	w.textf("pipeline #%d %s %s in %s\n", pl.ID, pl.Status.Symbol(), pl.Status, formatSeconds(pl.Duration))
+	for _, j := range failed {
+		w.textf("  ✗ %s/%s %s\n", j.Stage, j.Name, j.WebURL)
+	}
+	if w.logLines > 0 {
+		for _, j := range failed {
+			w.printLogTail(ctx, j)
+		}
+	}
+
+	// Only success may let `glcli wait && deploy` go on
+	switch pl.Status {
+	case valueobject.PipelineSuccess:
+		return nil
+	case valueobject.PipelineManual:
+		return exitError(ExitManual)
+	}
+	return exitError(ExitFailed)
+}
+
+func (w *waiter) printLogTail(ctx context.Context, j entity.Job) {
+	rc, err := w.c.env.Jobs.GetJobLog(ctx, w.project.ID, j.ID)
+	if err != nil {
+		fmt.Fprintf(w.c.stderr, "glcli wait: log of %s: %v\n", j.Name, err)
+		return
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		fmt.Fprintf(w.c.stderr, "glcli wait: log of %s: %v\n", j.Name, err)
+		return
+	}
+	lines := joblog.Parse(string(data)).Lines
+	if len(lines) > w.logLines {
+		lines = lines[len(lines)-w.logLines:]
+	}
+	tail := make([]string, len(lines))
+	for i, ln := range lines {
+		tail[i] = joblog.StripANSI(ln.Text)
+	}
+
+	w.emit(waitEvent{Type: "log", Job: ptr(toJobOut(j)), Log: tail})
+	w.textf("\n── %s/%s (last %d lines) ──\n", j.Stage, j.Name, len(tail))
+	for _, ln := range tail {
+		w.textf("%s\n", ln)
+	}
+}
+
+func (w *waiter) target() string {
+	switch {
+	case w.sha != "" && w.ref != "":
+		return w.ref + "@" + shortSHA(w.sha)
+	case w.sha != "":
+		return "commit " + shortSHA(w.sha)
+	}
+	return w.ref
+}
+
+// emit writes an event as one JSON line in json mode.
+func (w *waiter) emit(ev waitEvent) {
+	if !w.json {
+		return
+	}
+	ev.Time = time.Now().UTC()
+	_ = json.NewEncoder(w.c.stdout).Encode(ev)
+}
+
+// textf writes human-readable progress in table mode.
+func (w *waiter) textf(format string, args ...any) {
+	if w.json {
+		return
+	}
+	fmt.Fprintf(w.c.stdout, format, args...)
+}
+
+func stamp() string { return time.Now().Format("15:04:05") }
+
+func ptr[T any](v T) *T { return &v }
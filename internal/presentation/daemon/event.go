@@ -0,0 +1,31 @@
+// Package daemon implements `glcli daemon`: it polls the configured
+// projects once for everyone and streams what changed as NDJSON on a Unix
+// socket, so scripts and other tools can subscribe instead of polling
+// GitLab themselves.
+package daemon
+
+import (
+	"time"
+
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+)
+
+// Event types.
+const (
+	EventPipeline = "pipeline"  // a pipeline appeared or changed status
+	EventMROpened = "mr_opened" // a merge request was opened
+	EventNote     = "note"      // someone commented on an open merge request
+	EventApproval = "approval"  // someone approved an open merge request
+	EventError    = "error"     // a poll failed; the daemon keeps going
+)
+
+// Event is one line on the socket. Payloads are the domain entities.
+type Event struct {
+	Type         string               `json:"type"`
+	Time         time.Time            `json:"time"`
+	Previous     string               `json:"previous,omitempty"` // pipeline status before the change
+	Pipeline     *entity.Pipeline     `json:"pipeline,omitempty"`
+	MergeRequest *entity.MergeRequest `json:"merge_request,omitempty"`
+	Note         *entity.MRNote       `json:"note,omitempty"`
+	Error        string               `json:"error,omitempty"`
+}
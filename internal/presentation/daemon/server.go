@@ -0,0 +1,159 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/bearlogin/gitlab-awesome-cli/internal/bootstrap"
+)
+
+// subscriberBuffer is how many events a slow subscriber may fall behind
+// before it is disconnected.
+const subscriberBuffer = 256
+
+// DefaultSocketPath is $XDG_RUNTIME_DIR/glcli.sock, or a per-user path in
+// the temp directory when that isn't set.
+func DefaultSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "glcli.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("glcli-%d.sock", os.Getuid()))
+}
+
+// Run polls GitLab every interval and publishes events on a Unix socket at
+// path until ctx is canceled. Progress is written to logw.
+func Run(ctx context.Context, env *bootstrap.Env, path string, interval time.Duration, logw io.Writer) error {
+	ln, err := listen(path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+	defer ln.Close()
+
+	srv := &server{subs: make(map[chan []byte]struct{})}
+	go srv.accept(ln)
+	fmt.Fprintf(logw, "glcli daemon: watching %d projects every %s, events on %s\n", len(env.Config.Projects), interval, path)
+
+	w := newWatcher(env)
+	for {
+		for _, ev := range w.poll(ctx) {
+			if ctx.Err() != nil {
+				break
+			}
+			srv.publish(ev)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// listen binds the socket, replacing a stale one left by a daemon that
+// didn't shut down cleanly.
+func listen(path string) (net.Listener, error) {
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("a daemon is already listening on %s", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	// Events include private project names; keep them to the user
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// server fans events out to every connected subscriber.
+type server struct {
+	mu   sync.Mutex
+	subs map[chan []byte]struct{}
+}
+
+func (s *server) accept(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.serve(conn)
+	}
+}
+
+func (s *server) serve(conn net.Conn) {
+	defer conn.Close()
+	ch := make(chan []byte, subscriberBuffer)
+	s.mu.Lock()
+	s.subs[ch] = struct{}{}
+	log.Printf("[daemon] subscriber connected (%d total)", len(s.subs))
+	s.mu.Unlock()
+
+	// Subscribers only listen; a read returning means they hung up
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, conn)
+		close(done)
+	}()
+	defer s.unsubscribe(ch)
+	for {
+		select {
+		case line, ok := <-ch:
+			if !ok {
+				return
+			}
+			if _, err := conn.Write(line); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+func (s *server) unsubscribe(ch chan []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subs[ch]; ok {
+		delete(s.subs, ch)
+		close(ch)
+	}
+}
+
+func (s *server) publish(ev Event) {
+	ev.Time = time.Now().UTC()
+	line, err := json.Marshal(ev)
+	if err != nil {
+		log.Printf("[daemon] encoding %s event: %v", ev.Type, err)
+		return
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subs {
+		select {
+		case ch <- line:
+		default:
+			log.Printf("[daemon] dropping a subscriber that fell %d events behind", subscriberBuffer)
+			delete(s.subs, ch)
+			close(ch)
+		}
+	}
+}
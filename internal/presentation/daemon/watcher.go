@@ -0,0 +1,137 @@
+package daemon
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bearlogin/gitlab-awesome-cli/internal/bootstrap"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/valueobject"
+)
+
+// approvalNote starts the system note GitLab adds when someone approves.
+const approvalNote = "approved this merge request"
+
+// watcher turns successive polls into events. The first poll only records
+// a baseline.
+type watcher struct {
+	env    *bootstrap.Env
+	primed bool
+
+	pipelines   map[int]valueobject.PipelineStatus
+	maxPipeline int
+	mrs         map[int]time.Time // open MR ID → last UpdatedAt
+	maxMR       int
+}
+
+func newWatcher(env *bootstrap.Env) *watcher {
+	return &watcher{env: env}
+}
+
+// poll loads pipelines and open merge requests and returns what changed
+// since the previous poll. Projects that fail to load are logged and
+// skipped.
+func (w *watcher) poll(ctx context.Context) []Event {
+	var events []Event
+	pipelineEvents, err := w.pollPipelines(ctx)
+	events = append(events, pipelineEvents...)
+	if err != nil {
+		events = append(events, errorEvent(err))
+	}
+	mrEvents, err := w.pollMRs(ctx)
+	events = append(events, mrEvents...)
+	if err != nil {
+		events = append(events, errorEvent(err))
+	}
+	w.primed = true
+	return events
+}
+
+func (w *watcher) pollPipelines(ctx context.Context) ([]Event, error) {
+	cfg := w.env.Config
+	pls, err := w.env.Pipelines.LoadAllPipelines(ctx, cfg.Projects, cfg.PipelineLimit)
+	partial, fatal := entity.AsPartial(err)
+	if fatal != nil {
+		return nil, fatal
+	}
+	if partial != nil {
+		log.Printf("[daemon] pipelines: %v", partial)
+	}
+
+	last, maxID := w.pipelines, w.maxPipeline
+	w.pipelines = make(map[int]valueobject.PipelineStatus, len(pls))
+	var events []Event
+	for _, pl := range pls {
+		w.pipelines[pl.ID] = pl.Status
+		w.maxPipeline = max(w.maxPipeline, pl.ID)
+		prev, seen := last[pl.ID]
+		// Unseen pipelines older than the newest one are only new to the
+		// list, e.g. because their project failed to load last time
+		if !w.primed || prev == pl.Status || (!seen && pl.ID <= maxID) {
+			continue
+		}
+		events = append(events, Event{Type: EventPipeline, Pipeline: &pl, Previous: string(prev)})
+	}
+	return events, nil
+}
+
+func (w *watcher) pollMRs(ctx context.Context) ([]Event, error) {
+	projects, err := w.env.Pipelines.ResolveProjects(ctx, w.env.Config.Projects)
+	if _, fatal := entity.AsPartial(err); fatal != nil {
+		return nil, fatal
+	}
+	mrs, err := w.env.MRs.ListProjectsMRs(ctx, projects, "opened", 0)
+	if _, fatal := entity.AsPartial(err); fatal != nil {
+		return nil, fatal
+	}
+	if err != nil {
+		log.Printf("[daemon] merge requests: %v", err)
+	}
+
+	last, maxID := w.mrs, w.maxMR
+	w.mrs = make(map[int]time.Time, len(mrs))
+	var events []Event
+	for _, mr := range mrs {
+		w.mrs[mr.ID] = mr.UpdatedAt
+		w.maxMR = max(w.maxMR, mr.ID)
+		if !w.primed {
+			continue
+		}
+		prev, seen := last[mr.ID]
+		switch {
+		case !seen && mr.ID > maxID:
+			events = append(events, Event{Type: EventMROpened, MergeRequest: &mr})
+		case seen && mr.UpdatedAt.After(prev):
+			events = append(events, w.newNotes(ctx, mr, prev)...)
+		}
+	}
+	return events, nil
+}
+
+// newNotes returns comments and approvals added to mr after since.
+func (w *watcher) newNotes(ctx context.Context, mr entity.MergeRequest, since time.Time) []Event {
+	notes, err := w.env.MRs.ListNotes(ctx, mr.ProjectID, mr.IID)
+	if err != nil {
+		log.Printf("[daemon] notes of %s!%d: %v", mr.ProjectPath, mr.IID, err)
+		return nil
+	}
+	var events []Event
+	for _, n := range notes {
+		if !n.CreatedAt.After(since) {
+			continue
+		}
+		switch {
+		case !n.System:
+			events = append(events, Event{Type: EventNote, MergeRequest: &mr, Note: &n})
+		case strings.HasPrefix(n.Body, approvalNote):
+			events = append(events, Event{Type: EventApproval, MergeRequest: &mr, Note: &n})
+		}
+	}
+	return events
+}
+
+func errorEvent(err error) Event {
+	return Event{Type: EventError, Error: err.Error()}
+}
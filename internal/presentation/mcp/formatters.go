@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/joblog"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/valueobject"
 )
 
@@ -27,10 +28,32 @@ func formatProjects(projects []entity.Project) string {
 	return b.String()
 }
 
+func formatPartialError(partial *entity.PartialError) string {
+	if partial == nil {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n%d of %d project(s) failed to load:\n", len(partial.Failed), partial.Total)
+	for _, f := range partial.Failed {
+		fmt.Fprintf(&b, "- %s: %v\n", f.Path, f.Err)
+	}
+	return b.String()
+}
+
 func formatPipeline(p entity.Pipeline) string {
 	age := time.Since(p.CreatedAt).Truncate(time.Second)
-	return fmt.Sprintf("- %s #%d | %s | ref: %s | %s ago | %d jobs",
+	s := fmt.Sprintf("- %s #%d | %s | ref: %s | %s ago | %d jobs",
 		p.Status.Symbol(), p.ID, p.ProjectPath, p.Ref, age, p.JobCount)
+	if len(p.SHA) >= 8 {
+		s += " | sha: " + p.SHA[:8]
+	}
+	if p.Author != "" {
+		s += " | @" + p.Author
+	}
+	if p.Source != "" {
+		s += " | source: " + p.Source
+	}
+	return s
 }
 
 func formatPipelines(pipelines []entity.Pipeline) string {
@@ -48,8 +71,12 @@ func formatPipelines(pipelines []entity.Pipeline) string {
 
 func formatJob(j entity.Job) string {
 	dur := fmt.Sprintf("%.0fs", j.Duration)
-	return fmt.Sprintf("- %s %s (ID: %d) | stage: %s | %s | %s",
+	s := fmt.Sprintf("- %s %s (ID: %d) | stage: %s | %s | %s",
 		j.Status.Symbol(), j.Name, j.ID, j.Stage, dur, j.WebURL)
+	if d := j.Downstream; d != nil {
+		s += fmt.Sprintf(" | downstream pipeline: %d (project %d, %s)", d.ID, d.ProjectID, d.Status)
+	}
+	return s
 }
 
 func formatJobs(jobs []entity.Job) string {
@@ -65,6 +92,27 @@ func formatJobs(jobs []entity.Job) string {
 	return b.String()
 }
 
+// formatJobLog renders a parsed trace as plain text, marking each GitLab
+// section with a heading line and its duration.
+func formatJobLog(l *joblog.Log) string {
+	var b strings.Builder
+	for _, ln := range l.Lines {
+		text := joblog.StripANSI(ln.Text)
+		if ln.Header {
+			sec := l.Sections[ln.Section]
+			fmt.Fprintf(&b, "%s## %s [%s]", strings.Repeat("#", sec.Depth), text, sec.Name)
+			if d := sec.Duration(); d > 0 {
+				fmt.Fprintf(&b, " (%s)", joblog.FormatDuration(d))
+			}
+			b.WriteByte('\n')
+			continue
+		}
+		b.WriteString(text)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
 func formatMergeRequest(mr entity.MergeRequest) string {
 	state := valueobject.MRState(mr.State)
 	age := time.Since(mr.UpdatedAt).Truncate(time.Second)
@@ -72,6 +120,9 @@ func formatMergeRequest(mr entity.MergeRequest) string {
 	if mr.Draft {
 		draft = " [Draft]"
 	}
+	if mr.UnresolvedThreads > 0 {
+		draft += fmt.Sprintf(" [%d unresolved]", mr.UnresolvedThreads)
+	}
 	return fmt.Sprintf("- %s !%d%s | %s → %s | %s | @%s | %s ago | %s",
 		state.Symbol(), mr.IID, draft, mr.SourceBranch, mr.TargetBranch, mr.Title, mr.Author, age, mr.WebURL)
 }
@@ -101,6 +152,15 @@ func formatMergeRequestDetail(mr *entity.MergeRequest) string {
 	fmt.Fprintf(&b, "- **Author:** @%s\n", mr.Author)
 	fmt.Fprintf(&b, "- **Branch:** %s → %s\n", mr.SourceBranch, mr.TargetBranch)
 	fmt.Fprintf(&b, "- **Merge status:** %s\n", mr.MergeStatus)
+	if mr.PipelineStatus != "" {
+		fmt.Fprintf(&b, "- **Pipeline:** %s\n", mr.PipelineStatus)
+	}
+	if mr.AutoMerge {
+		b.WriteString("- **Auto-merge:** merges when the pipeline succeeds\n")
+	}
+	if mr.SHA != "" {
+		fmt.Fprintf(&b, "- **Head SHA:** %s\n", mr.SHA)
+	}
 	fmt.Fprintf(&b, "- **URL:** %s\n", mr.WebURL)
 	if mr.Description != "" {
 		fmt.Fprintf(&b, "\n### Description\n\n%s\n", mr.Description)
@@ -108,6 +168,38 @@ func formatMergeRequestDetail(mr *entity.MergeRequest) string {
 	return b.String()
 }
 
+func formatDiscussions(discussions []entity.Discussion) string {
+	if len(discussions) == 0 {
+		return "No discussions found."
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Found %d discussion(s), %d unresolved:\n\n", len(discussions), entity.CountUnresolved(discussions))
+	for _, d := range discussions {
+		state := ""
+		switch {
+		case d.Unresolved():
+			state = " [unresolved]"
+		case d.Resolved:
+			state = " [resolved]"
+		}
+		where := ""
+		if p := d.Position; p != nil {
+			where = fmt.Sprintf(" on %s:%d", p.NewPath, max(p.NewLine, p.OldLine))
+		}
+		fmt.Fprintf(&b, "### %s%s%s\n", d.ID, state, where)
+		for _, n := range d.Notes {
+			age := time.Since(n.CreatedAt).Truncate(time.Second)
+			prefix := ""
+			if n.System {
+				prefix = "[system] "
+			}
+			fmt.Fprintf(&b, "- %s@%s (%s ago): %s\n", prefix, n.Author, age, n.Body)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
 func formatMRNotes(notes []entity.MRNote) string {
 	if len(notes) == 0 {
 		return "No notes found."
@@ -14,10 +14,20 @@ func configResourceHandler(cfg *config.Config) func(context.Context, *mcp.ReadRe
 	return func(_ context.Context, _ *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
 		log.Print("[resource] gitlab://config: read")
 		var b strings.Builder
+		if len(cfg.Contexts) > 0 {
+			fmt.Fprintf(&b, "Current Context: %s\n", cfg.ActiveContext())
+			for _, c := range cfg.Contexts {
+				fmt.Fprintf(&b, "Context %s: %s (%d projects)\n", c.Name, c.GitLabURL, len(c.Projects))
+			}
+		}
 		fmt.Fprintf(&b, "GitLab URL: %s\n", cfg.GitLabURL)
+		fmt.Fprintf(&b, "Token Source: %s\n", cfg.TokenSource())
 		fmt.Fprintf(&b, "Projects: %s\n", strings.Join(cfg.Projects, ", "))
 		fmt.Fprintf(&b, "Refresh Interval: %s\n", cfg.RefreshInterval)
+		fmt.Fprintf(&b, "Max Refresh Interval: %s\n", cfg.MaxRefreshInterval)
 		fmt.Fprintf(&b, "Pipeline Limit: %d\n", cfg.PipelineLimit)
+		fmt.Fprintf(&b, "Pipeline Backend: %s\n", cfg.PipelineBackend)
+		fmt.Fprintf(&b, "Concurrency: %d\n", cfg.Concurrency)
 
 		return &mcp.ReadResourceResult{
 			Contents: []*mcp.ResourceContents{{
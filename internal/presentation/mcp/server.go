@@ -1,12 +1,13 @@
 package mcp
 
 import (
-	"github.com/bearlogin/gitlab-awesome-cli/internal/application/service"
-	"github.com/bearlogin/gitlab-awesome-cli/internal/infrastructure/config"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/bootstrap"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func NewServer(cfg *config.Config, pSvc *service.PipelineService, jSvc *service.JobService, mrSvc *service.MergeRequestService, version string) *mcp.Server {
+// NewServer registers the tools. Every tool accepts an optional "context"
+// argument; its services are taken from pool.
+func NewServer(pool *bootstrap.Pool, version string) *mcp.Server {
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:    "glcli-mcp",
 		Version: version,
@@ -17,94 +18,163 @@ func NewServer(cfg *config.Config, pSvc *service.PipelineService, jSvc *service.
 		URI:         "gitlab://config",
 		Name:        "GitLab Configuration",
 		Description: "Current glcli configuration (without token)",
-	}, configResourceHandler(cfg))
+	}, configResourceHandler(pool.Config()))
 
 	// Tools
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_projects",
 		Description: "List configured GitLab projects with pipeline counts",
-	}, listProjectsHandler(cfg, pSvc))
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[ListProjectsInput, any] {
+		return listProjectsHandler(e.Config, e.Pipelines)
+	}))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_pipelines",
 		Description: "List pipelines across configured projects. Optionally filter by project, status, ref, or limit.",
-	}, listPipelinesHandler(cfg, pSvc))
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[ListPipelinesInput, any] {
+		return listPipelinesHandler(e.Config, e.Pipelines)
+	}))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_jobs",
 		Description: "List jobs for a specific pipeline",
-	}, listJobsHandler(pSvc))
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[ListJobsInput, any] {
+		return listJobsHandler(e.Pipelines)
+	}))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_job_log",
-		Description: "Get the log output of a specific job",
-	}, getJobLogHandler(jSvc))
+		Description: "Get the log output of a specific job, with ANSI colours stripped and GitLab sections marked as headings with their durations",
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[JobActionInput, any] {
+		return getJobLogHandler(e.Jobs)
+	}))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "play_job",
 		Description: "Start a manual job",
-	}, playJobHandler(jSvc))
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[JobActionInput, any] {
+		return playJobHandler(e.Jobs)
+	}))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "retry_job",
 		Description: "Retry a failed job",
-	}, retryJobHandler(jSvc))
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[JobActionInput, any] {
+		return retryJobHandler(e.Jobs)
+	}))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "cancel_job",
 		Description: "Cancel a running or pending job",
-	}, cancelJobHandler(jSvc))
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[JobActionInput, any] {
+		return cancelJobHandler(e.Jobs)
+	}))
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "run_pipeline",
+		Description: "Start a new pipeline for a branch or tag, optionally with CI/CD variables",
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[RunPipelineInput, any] {
+		return runPipelineHandler(e.Pipelines)
+	}))
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "retry_pipeline",
+		Description: "Retry all failed and canceled jobs of a pipeline",
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[PipelineActionInput, any] {
+		return retryPipelineHandler(e.Pipelines)
+	}))
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "cancel_pipeline",
+		Description: "Cancel all running and pending jobs of a pipeline",
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[PipelineActionInput, any] {
+		return cancelPipelineHandler(e.Pipelines)
+	}))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "search_projects",
 		Description: "Search GitLab projects by name or path",
-	}, searchProjectsHandler(pSvc))
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[SearchProjectsInput, any] {
+		return searchProjectsHandler(e.Pipelines)
+	}))
 
 	// Merge Request tools
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_merge_requests",
 		Description: "List merge requests for a project",
-	}, listMergeRequestsHandler(mrSvc))
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[ListMergeRequestsInput, any] {
+		return listMergeRequestsHandler(e.MRs)
+	}))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_merge_request",
 		Description: "Get details of a specific merge request",
-	}, getMergeRequestHandler(mrSvc))
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[MRInput, any] {
+		return getMergeRequestHandler(e.MRs)
+	}))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_mr_notes",
 		Description: "List comments/notes on a merge request",
-	}, listMRNotesHandler(mrSvc))
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[MRInput, any] {
+		return listMRNotesHandler(e.MRs)
+	}))
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_mr_discussions",
+		Description: "List the discussion threads of a merge request with their resolved state and diff position",
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[ListDiscussionsInput, any] {
+		return listMRDiscussionsHandler(e.MRs)
+	}))
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "resolve_mr_discussion",
+		Description: "Resolve a discussion thread of a merge request, or reopen it",
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[ResolveDiscussionInput, any] {
+		return resolveMRDiscussionHandler(e.MRs)
+	}))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_mr_diffs",
 		Description: "Get diffs of a merge request",
-	}, getMRDiffsHandler(mrSvc))
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[MRInput, any] {
+		return getMRDiffsHandler(e.MRs)
+	}))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "approve_mr",
 		Description: "Approve a merge request",
-	}, approveMRHandler(mrSvc))
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[MRInput, any] {
+		return approveMRHandler(e.MRs)
+	}))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "merge_mr",
-		Description: "Merge a merge request",
-	}, mergeMRHandler(mrSvc))
+		Description: "Merge a merge request now or when its pipeline succeeds, optionally squashing and deleting the source branch",
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[MergeMRInput, any] {
+		return mergeMRHandler(e.MRs)
+	}))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "create_merge_request",
 		Description: "Create a new merge request",
-	}, createMRHandler(mrSvc))
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[CreateMRInput, any] {
+		return createMRHandler(e.MRs)
+	}))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_branches",
 		Description: "List repository branches, optionally filtered by name",
-	}, listBranchesHandler(pSvc))
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[ListBranchesInput, any] {
+		return listBranchesHandler(e.Pipelines)
+	}))
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_pipeline_commits",
 		Description: "List commits for a pipeline ref (branch/tag)",
-	}, listPipelineCommitsHandler(mrSvc))
+	}, withContext(pool, func(e *bootstrap.Env) mcp.ToolHandlerFor[ListPipelineCommitsInput, any] {
+		return listPipelineCommitsHandler(e.MRs)
+	}))
 
 	return server
 }
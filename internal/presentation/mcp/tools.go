@@ -2,22 +2,38 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/bearlogin/gitlab-awesome-cli/internal/application/service"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/bootstrap"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/joblog"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/infrastructure/config"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // Tool input types
 
-type ListProjectsInput struct{}
+// Target is embedded in every tool input to pick the config context the
+// call runs against.
+type Target struct {
+	Context string `json:"context,omitempty" jsonschema:"config context (GitLab instance) to use; defaults to the current context"`
+}
+
+func (t Target) contextName() string { return t.Context }
+
+type ListProjectsInput struct {
+	Target
+}
 
 type ListPipelinesInput struct {
+	Target
 	Project string `json:"project,omitempty" jsonschema:"project path to filter by"`
 	Status  string `json:"status,omitempty" jsonschema:"pipeline status filter (running/pending/success/failed/canceled)"`
 	Ref     string `json:"ref,omitempty" jsonschema:"git ref (branch/tag) to filter by"`
@@ -25,30 +41,65 @@ type ListPipelinesInput struct {
 }
 
 type ListJobsInput struct {
+	Target
 	ProjectID  int `json:"project_id" jsonschema:"GitLab project ID"`
 	PipelineID int `json:"pipeline_id" jsonschema:"pipeline ID"`
 }
 
 type JobActionInput struct {
+	Target
 	ProjectID int `json:"project_id" jsonschema:"GitLab project ID"`
 	JobID     int `json:"job_id" jsonschema:"job ID"`
 }
 
 type SearchProjectsInput struct {
+	Target
 	Query string `json:"query" jsonschema:"search query for project name or path"`
+	Limit int    `json:"limit,omitempty" jsonschema:"max number of projects to return (default 10)"`
 }
 
 type ListMergeRequestsInput struct {
+	Target
 	ProjectID int    `json:"project_id" jsonschema:"GitLab project ID"`
 	State     string `json:"state,omitempty" jsonschema:"MR state filter (opened/merged/closed)"`
+	Limit     int    `json:"limit,omitempty" jsonschema:"max number of merge requests to return (default 50)"`
 }
 
 type MRInput struct {
+	Target
 	ProjectID int `json:"project_id" jsonschema:"GitLab project ID"`
 	MRIID     int `json:"mr_iid" jsonschema:"merge request IID (project-scoped ID)"`
 }
 
+type MergeMRInput struct {
+	Target
+	ProjectID            int    `json:"project_id" jsonschema:"GitLab project ID"`
+	MRIID                int    `json:"mr_iid" jsonschema:"merge request IID (project-scoped ID)"`
+	WhenPipelineSucceeds bool   `json:"when_pipeline_succeeds,omitempty" jsonschema:"set auto-merge: merge once the pipeline succeeds instead of now"`
+	Squash               *bool  `json:"squash,omitempty" jsonschema:"squash commits into one; defaults to the MR's setting"`
+	SquashCommitMessage  string `json:"squash_commit_message,omitempty" jsonschema:"message of the squash commit"`
+	MergeCommitMessage   string `json:"merge_commit_message,omitempty" jsonschema:"message of the merge commit"`
+	RemoveSourceBranch   *bool  `json:"remove_source_branch,omitempty" jsonschema:"delete the source branch after merging; defaults to the MR's setting"`
+	SHA                  string `json:"sha,omitempty" jsonschema:"merge only if the source branch head is still this commit"`
+}
+
+type ListDiscussionsInput struct {
+	Target
+	ProjectID      int  `json:"project_id" jsonschema:"GitLab project ID"`
+	MRIID          int  `json:"mr_iid" jsonschema:"merge request IID (project-scoped ID)"`
+	UnresolvedOnly bool `json:"unresolved_only,omitempty" jsonschema:"only list threads that still need resolving"`
+}
+
+type ResolveDiscussionInput struct {
+	Target
+	ProjectID    int    `json:"project_id" jsonschema:"GitLab project ID"`
+	MRIID        int    `json:"mr_iid" jsonschema:"merge request IID (project-scoped ID)"`
+	DiscussionID string `json:"discussion_id" jsonschema:"discussion ID from list_mr_discussions"`
+	Resolved     bool   `json:"resolved" jsonschema:"true to resolve the thread, false to reopen it"`
+}
+
 type CreateMRInput struct {
+	Target
 	ProjectID    int    `json:"project_id" jsonschema:"GitLab project ID"`
 	SourceBranch string `json:"source_branch" jsonschema:"source branch name"`
 	TargetBranch string `json:"target_branch" jsonschema:"target branch name"`
@@ -58,13 +109,46 @@ type CreateMRInput struct {
 }
 
 type ListBranchesInput struct {
+	Target
 	ProjectID int    `json:"project_id" jsonschema:"GitLab project ID"`
 	Search    string `json:"search,omitempty" jsonschema:"filter branches by name"`
+	Limit     int    `json:"limit,omitempty" jsonschema:"max number of branches to return (default 20)"`
 }
 
 type ListPipelineCommitsInput struct {
+	Target
 	ProjectID int    `json:"project_id" jsonschema:"GitLab project ID"`
 	Ref       string `json:"ref" jsonschema:"git ref (branch/tag) to list commits for"`
+	Limit     int    `json:"limit,omitempty" jsonschema:"max number of commits to return (default 50)"`
+}
+
+type PipelineActionInput struct {
+	Target
+	ProjectID  int `json:"project_id" jsonschema:"GitLab project ID"`
+	PipelineID int `json:"pipeline_id" jsonschema:"pipeline ID"`
+}
+
+type RunPipelineInput struct {
+	Target
+	ProjectID int               `json:"project_id" jsonschema:"GitLab project ID"`
+	Ref       string            `json:"ref" jsonschema:"branch or tag to run the pipeline for"`
+	Variables map[string]string `json:"variables,omitempty" jsonschema:"CI/CD variables for the pipeline, e.g. {\"DEPLOY_ENV\": \"staging\"}"`
+}
+
+// targeted is implemented by every tool input through the embedded Target.
+type targeted interface{ contextName() string }
+
+// withContext resolves the context named in the call and runs the handler
+// built for its services.
+func withContext[In targeted](pool *bootstrap.Pool, build func(*bootstrap.Env) mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, any, error) {
+		env, err := pool.Get(input.contextName())
+		if err != nil {
+			log.Printf("[tool] context %q: error: %v", input.contextName(), err)
+			return errResult(err), nil, nil
+		}
+		return build(env)(ctx, req, input)
+	}
 }
 
 // Tool handlers
@@ -73,12 +157,16 @@ func listProjectsHandler(cfg *config.Config, pSvc *service.PipelineService) func
 	return func(ctx context.Context, _ *mcp.CallToolRequest, _ ListProjectsInput) (*mcp.CallToolResult, any, error) {
 		log.Printf("[tool] list_projects: paths=%v", cfg.Projects)
 		projects, err := pSvc.LoadProjects(ctx, cfg.Projects)
+		partial, err := entity.AsPartial(err)
 		if err != nil {
 			log.Printf("[tool] list_projects: error: %v", err)
 			return errResult(err), nil, nil
 		}
+		if partial != nil {
+			log.Printf("[tool] list_projects: partial failure: %v", partial)
+		}
 		log.Printf("[tool] list_projects: ok, %d projects", len(projects))
-		return textResult(formatProjects(projects)), nil, nil
+		return textResult(formatProjects(projects) + formatPartialError(partial)), nil, nil
 	}
 }
 
@@ -95,10 +183,14 @@ func listPipelinesHandler(cfg *config.Config, pSvc *service.PipelineService) fun
 		log.Printf("[tool] list_pipelines: paths=%v status=%q ref=%q limit=%d", paths, input.Status, input.Ref, limit)
 
 		pipelines, err := pSvc.LoadAllPipelines(ctx, paths, limit)
+		partial, err := entity.AsPartial(err)
 		if err != nil {
 			log.Printf("[tool] list_pipelines: error: %v", err)
 			return errResult(err), nil, nil
 		}
+		if partial != nil {
+			log.Printf("[tool] list_pipelines: partial failure: %v", partial)
+		}
 
 		// Apply filters
 		if input.Status != "" || input.Ref != "" {
@@ -116,7 +208,7 @@ func listPipelinesHandler(cfg *config.Config, pSvc *service.PipelineService) fun
 		}
 
 		log.Printf("[tool] list_pipelines: ok, %d pipelines", len(pipelines))
-		return textResult(formatPipelines(pipelines)), nil, nil
+		return textResult(formatPipelines(pipelines) + formatPartialError(partial)), nil, nil
 	}
 }
 
@@ -147,7 +239,7 @@ func getJobLogHandler(jSvc *service.JobService) func(context.Context, *mcp.CallT
 			log.Printf("[tool] get_job_log: read error: %v", err)
 			return errResult(err), nil, nil
 		}
-		jobLog := string(data)
+		jobLog := formatJobLog(joblog.Parse(string(data)))
 		const maxLen = 50000
 		if len(jobLog) > maxLen {
 			log.Printf("[tool] get_job_log: truncating %d -> %d bytes", len(jobLog), maxLen)
@@ -197,10 +289,62 @@ func cancelJobHandler(jSvc *service.JobService) func(context.Context, *mcp.CallT
 	}
 }
 
+func runPipelineHandler(pSvc *service.PipelineService) func(context.Context, *mcp.CallToolRequest, RunPipelineInput) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, input RunPipelineInput) (*mcp.CallToolResult, any, error) {
+		log.Printf("[tool] run_pipeline: project=%d ref=%s vars=%d", input.ProjectID, input.Ref, len(input.Variables))
+		if input.Ref == "" {
+			return errResult(errors.New("ref is required")), nil, nil
+		}
+		keys := make([]string, 0, len(input.Variables))
+		for k := range input.Variables {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		vars := make([]entity.PipelineVariable, len(keys))
+		for i, k := range keys {
+			vars[i] = entity.PipelineVariable{Key: k, Value: input.Variables[k]}
+		}
+		pl, err := pSvc.CreatePipeline(ctx, input.ProjectID, input.Ref, vars)
+		if err != nil {
+			log.Printf("[tool] run_pipeline: error: %v", err)
+			return errResult(err), nil, nil
+		}
+		log.Printf("[tool] run_pipeline: ok, id=%d status=%s", pl.ID, pl.Status)
+		return textResult(fmt.Sprintf("Pipeline started: %s\n%s", formatPipeline(*pl), pl.WebURL)), nil, nil
+	}
+}
+
+func retryPipelineHandler(pSvc *service.PipelineService) func(context.Context, *mcp.CallToolRequest, PipelineActionInput) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, input PipelineActionInput) (*mcp.CallToolResult, any, error) {
+		log.Printf("[tool] retry_pipeline: project=%d pipeline=%d", input.ProjectID, input.PipelineID)
+		pl, err := pSvc.RetryPipeline(ctx, input.ProjectID, input.PipelineID)
+		if err != nil {
+			log.Printf("[tool] retry_pipeline: error: %v", err)
+			return errResult(err), nil, nil
+		}
+		log.Printf("[tool] retry_pipeline: ok, status=%s", pl.Status)
+		return textResult(fmt.Sprintf("Pipeline retried: %s", formatPipeline(*pl))), nil, nil
+	}
+}
+
+func cancelPipelineHandler(pSvc *service.PipelineService) func(context.Context, *mcp.CallToolRequest, PipelineActionInput) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, input PipelineActionInput) (*mcp.CallToolResult, any, error) {
+		log.Printf("[tool] cancel_pipeline: project=%d pipeline=%d", input.ProjectID, input.PipelineID)
+		pl, err := pSvc.CancelPipeline(ctx, input.ProjectID, input.PipelineID)
+		if err != nil {
+			log.Printf("[tool] cancel_pipeline: error: %v", err)
+			return errResult(err), nil, nil
+		}
+		log.Printf("[tool] cancel_pipeline: ok, status=%s", pl.Status)
+		return textResult(fmt.Sprintf("Pipeline canceled: %s", formatPipeline(*pl))), nil, nil
+	}
+}
+
 func searchProjectsHandler(pSvc *service.PipelineService) func(context.Context, *mcp.CallToolRequest, SearchProjectsInput) (*mcp.CallToolResult, any, error) {
 	return func(ctx context.Context, _ *mcp.CallToolRequest, input SearchProjectsInput) (*mcp.CallToolResult, any, error) {
-		log.Printf("[tool] search_projects: query=%q", input.Query)
-		projects, err := pSvc.SearchProjects(ctx, input.Query)
+		limit := orDefault(input.Limit, 10)
+		log.Printf("[tool] search_projects: query=%q limit=%d", input.Query, limit)
+		projects, err := pSvc.SearchProjects(ctx, input.Query, limit)
 		if err != nil {
 			log.Printf("[tool] search_projects: error: %v", err)
 			return errResult(err), nil, nil
@@ -214,8 +358,9 @@ func searchProjectsHandler(pSvc *service.PipelineService) func(context.Context,
 
 func listMergeRequestsHandler(mrSvc *service.MergeRequestService) func(context.Context, *mcp.CallToolRequest, ListMergeRequestsInput) (*mcp.CallToolResult, any, error) {
 	return func(ctx context.Context, _ *mcp.CallToolRequest, input ListMergeRequestsInput) (*mcp.CallToolResult, any, error) {
-		log.Printf("[tool] list_merge_requests: project=%d state=%q", input.ProjectID, input.State)
-		mrs, err := mrSvc.ListMRs(ctx, input.ProjectID, input.State)
+		limit := orDefault(input.Limit, 50)
+		log.Printf("[tool] list_merge_requests: project=%d state=%q limit=%d", input.ProjectID, input.State, limit)
+		mrs, err := mrSvc.ListMRs(ctx, input.ProjectID, input.State, limit)
 		if err != nil {
 			log.Printf("[tool] list_merge_requests: error: %v", err)
 			return errResult(err), nil, nil
@@ -251,6 +396,39 @@ func listMRNotesHandler(mrSvc *service.MergeRequestService) func(context.Context
 	}
 }
 
+func listMRDiscussionsHandler(mrSvc *service.MergeRequestService) func(context.Context, *mcp.CallToolRequest, ListDiscussionsInput) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, input ListDiscussionsInput) (*mcp.CallToolResult, any, error) {
+		log.Printf("[tool] list_mr_discussions: project=%d mr=!%d unresolved_only=%v", input.ProjectID, input.MRIID, input.UnresolvedOnly)
+		discussions, err := mrSvc.ListDiscussions(ctx, input.ProjectID, input.MRIID)
+		if err != nil {
+			log.Printf("[tool] list_mr_discussions: error: %v", err)
+			return errResult(err), nil, nil
+		}
+		if input.UnresolvedOnly {
+			discussions = slices.DeleteFunc(discussions, func(d entity.Discussion) bool { return !d.Unresolved() })
+		}
+		log.Printf("[tool] list_mr_discussions: ok, %d discussions", len(discussions))
+		return textResult(formatDiscussions(discussions)), nil, nil
+	}
+}
+
+func resolveMRDiscussionHandler(mrSvc *service.MergeRequestService) func(context.Context, *mcp.CallToolRequest, ResolveDiscussionInput) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, input ResolveDiscussionInput) (*mcp.CallToolResult, any, error) {
+		log.Printf("[tool] resolve_mr_discussion: project=%d mr=!%d discussion=%s resolved=%v", input.ProjectID, input.MRIID, input.DiscussionID, input.Resolved)
+		d, err := mrSvc.ResolveDiscussion(ctx, input.ProjectID, input.MRIID, input.DiscussionID, input.Resolved)
+		if err != nil {
+			log.Printf("[tool] resolve_mr_discussion: error: %v", err)
+			return errResult(err), nil, nil
+		}
+		log.Printf("[tool] resolve_mr_discussion: ok, resolved=%v", d.Resolved)
+		verb := "resolved"
+		if !d.Resolved {
+			verb = "reopened"
+		}
+		return textResult(fmt.Sprintf("Discussion %s %s.", d.ID, verb)), nil, nil
+	}
+}
+
 func getMRDiffsHandler(mrSvc *service.MergeRequestService) func(context.Context, *mcp.CallToolRequest, MRInput) (*mcp.CallToolResult, any, error) {
 	return func(ctx context.Context, _ *mcp.CallToolRequest, input MRInput) (*mcp.CallToolResult, any, error) {
 		log.Printf("[tool] get_mr_diffs: project=%d mr=!%d", input.ProjectID, input.MRIID)
@@ -277,23 +455,48 @@ func approveMRHandler(mrSvc *service.MergeRequestService) func(context.Context,
 	}
 }
 
-func mergeMRHandler(mrSvc *service.MergeRequestService) func(context.Context, *mcp.CallToolRequest, MRInput) (*mcp.CallToolResult, any, error) {
-	return func(ctx context.Context, _ *mcp.CallToolRequest, input MRInput) (*mcp.CallToolResult, any, error) {
-		log.Printf("[tool] merge_mr: project=%d mr=!%d", input.ProjectID, input.MRIID)
-		mr, err := mrSvc.MergeMR(ctx, input.ProjectID, input.MRIID)
+func mergeMRHandler(mrSvc *service.MergeRequestService) func(context.Context, *mcp.CallToolRequest, MergeMRInput) (*mcp.CallToolResult, any, error) {
+	return func(ctx context.Context, _ *mcp.CallToolRequest, input MergeMRInput) (*mcp.CallToolResult, any, error) {
+		log.Printf("[tool] merge_mr: project=%d mr=!%d auto=%v", input.ProjectID, input.MRIID, input.WhenPipelineSucceeds)
+		opts := entity.MergeOptions{
+			WhenPipelineSucceeds: input.WhenPipelineSucceeds,
+			SquashCommitMessage:  input.SquashCommitMessage,
+			MergeCommitMessage:   input.MergeCommitMessage,
+			SHA:                  input.SHA,
+		}
+		// Options left out keep what the author chose on the MR
+		if input.Squash == nil || input.RemoveSourceBranch == nil {
+			current, err := mrSvc.GetMR(ctx, input.ProjectID, input.MRIID)
+			if err != nil {
+				log.Printf("[tool] merge_mr: error: %v", err)
+				return errResult(err), nil, nil
+			}
+			opts.Squash, opts.RemoveSourceBranch = current.Squash, current.RemoveSource
+		}
+		if input.Squash != nil {
+			opts.Squash = *input.Squash
+		}
+		if input.RemoveSourceBranch != nil {
+			opts.RemoveSourceBranch = *input.RemoveSourceBranch
+		}
+		mr, err := mrSvc.MergeMR(ctx, input.ProjectID, input.MRIID, opts)
 		if err != nil {
 			log.Printf("[tool] merge_mr: error: %v", err)
 			return errResult(err), nil, nil
 		}
-		log.Printf("[tool] merge_mr: ok, state=%s", mr.State)
+		log.Printf("[tool] merge_mr: ok, state=%s auto_merge=%v", mr.State, mr.AutoMerge)
+		if mr.AutoMerge && mr.State != "merged" {
+			return textResult(fmt.Sprintf("Merge request set to merge when the pipeline succeeds: %s", formatMergeRequest(*mr))), nil, nil
+		}
 		return textResult(fmt.Sprintf("Merge request merged: %s", formatMergeRequest(*mr))), nil, nil
 	}
 }
 
 func listBranchesHandler(pSvc *service.PipelineService) func(context.Context, *mcp.CallToolRequest, ListBranchesInput) (*mcp.CallToolResult, any, error) {
 	return func(ctx context.Context, _ *mcp.CallToolRequest, input ListBranchesInput) (*mcp.CallToolResult, any, error) {
-		log.Printf("[tool] list_branches: project=%d search=%q", input.ProjectID, input.Search)
-		branches, err := pSvc.ListBranches(ctx, input.ProjectID, input.Search)
+		limit := orDefault(input.Limit, 20)
+		log.Printf("[tool] list_branches: project=%d search=%q limit=%d", input.ProjectID, input.Search, limit)
+		branches, err := pSvc.ListBranches(ctx, input.ProjectID, input.Search, limit)
 		if err != nil {
 			log.Printf("[tool] list_branches: error: %v", err)
 			return errResult(err), nil, nil
@@ -333,8 +536,9 @@ func createMRHandler(mrSvc *service.MergeRequestService) func(context.Context, *
 
 func listPipelineCommitsHandler(mrSvc *service.MergeRequestService) func(context.Context, *mcp.CallToolRequest, ListPipelineCommitsInput) (*mcp.CallToolResult, any, error) {
 	return func(ctx context.Context, _ *mcp.CallToolRequest, input ListPipelineCommitsInput) (*mcp.CallToolResult, any, error) {
-		log.Printf("[tool] list_pipeline_commits: project=%d ref=%s", input.ProjectID, input.Ref)
-		commits, err := mrSvc.ListCommits(ctx, input.ProjectID, input.Ref)
+		limit := orDefault(input.Limit, 50)
+		log.Printf("[tool] list_pipeline_commits: project=%d ref=%s limit=%d", input.ProjectID, input.Ref, limit)
+		commits, err := mrSvc.ListCommits(ctx, input.ProjectID, input.Ref, limit)
 		if err != nil {
 			log.Printf("[tool] list_pipeline_commits: error: %v", err)
 			return errResult(err), nil, nil
@@ -358,3 +562,11 @@ func errResult(err error) *mcp.CallToolResult {
 		IsError: true,
 	}
 }
+
+// orDefault returns limit, or def when the caller left it out.
+func orDefault(limit, def int) int {
+	if limit > 0 {
+		return limit
+	}
+	return def
+}
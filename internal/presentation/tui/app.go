@@ -3,14 +3,18 @@ package tui
 import (
 	"context"
 	"fmt"
-	"io"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/application/service"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/bootstrap"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/infrastructure/cache"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/infrastructure/config"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/infrastructure/notify"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/presentation/tui/components"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/presentation/tui/keymap"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/presentation/tui/styles"
@@ -28,6 +32,14 @@ const (
 	viewMRDetail
 	viewMRCreate
 	viewCommits
+	viewPipelineCreate
+)
+
+// logPollInterval is how often a running job's trace is tailed. Failed
+// polls back off up to maxLogPollInterval.
+const (
+	logPollInterval    = 2 * time.Second
+	maxLogPollInterval = time.Minute
 )
 
 type App struct {
@@ -35,6 +47,7 @@ type App struct {
 	pipelineSvc      *service.PipelineService
 	jobSvc           *service.JobService
 	mrSvc            *service.MergeRequestService
+	rateMonitor      RateMonitor
 	currentView      viewID
 	breadcrumb       components.Breadcrumb
 	projectsView     views.ProjectsView
@@ -45,23 +58,42 @@ type App struct {
 	mrDetailView     views.MRDetailView
 	mrCreateView     views.MRCreateView
 	commitsView      views.CommitsView
+	pipelineCreateView views.PipelineCreateView
 	confirmDialog    *components.ConfirmDialog
+	mergeDialog      *components.MergeDialog
 	selectedProject  *entity.Project
 	selectedPipeline *entity.Pipeline
+	parentPipelines  []pipelineFrame // pipelines above selectedPipeline, reached via trigger jobs
 	selectedMR       *entity.MergeRequest
+	mrLimit          int // merge requests fetched per project, grows on "load more"
+	commitLimit      int
+	logSeq           int // bumped on every job selection to drop stale log chunks
+	logFailures      int // consecutive failed polls of the log being tailed
+	tickSeq          int // bumped when the refresh schedule is reset
+	bulkSeq          int // bumped per bulk action to drop steps of an abandoned one
+	ctxSeq           int // bumped on every context switch to drop data of the previous instance
+	switching        bool // a context switch is resolving in the background
+	bulk             *bulkRun
+	notifier         *notify.Notifier // nil when notifications are off
+	idleRefreshes    int // consecutive refreshes that brought no changes
+	lastFingerprint  uint64
 	width            int
 	height           int
 	err              error
+	warning          string // partial failures from the last multi-project load
+	snapshots        *cache.SnapshotStore
+	viewed           *cache.ViewedStore
+	snapshot         cache.Snapshot
+	stale            bool      // showing the on-disk snapshot, not yet refreshed
+	offline          bool      // GitLab unreachable; read-only until it is back
+	dataTime         time.Time // when the data on screen was fetched
 	loadingStatus    string
 	loading          bool
 }
 
-func NewApp(cfg *config.Config, ps *service.PipelineService, js *service.JobService, mrs *service.MergeRequestService) App {
-	return App{
-		cfg:               cfg,
-		pipelineSvc:       ps,
-		jobSvc:            js,
-		mrSvc:             mrs,
+func NewApp(env *bootstrap.Env) App {
+	a := App{
+		cfg:               env.Config,
 		currentView:       viewPipelines,
 		breadcrumb:        components.NewBreadcrumb(),
 		projectsView:      views.NewProjectsView(),
@@ -72,37 +104,131 @@ func NewApp(cfg *config.Config, ps *service.PipelineService, js *service.JobServ
 		mrDetailView:      views.NewMRDetailView(),
 		mrCreateView:      views.NewMRCreateView(),
 		commitsView:       views.NewCommitsView(),
+		pipelineCreateView: views.NewPipelineCreateView(),
+		mrLimit:           listPage,
+		commitLimit:       listPage,
+	}
+	// token_command helpers must not read the keyboard bubbletea is reading
+	a.cfg.Detach()
+	a.useEnv(env)
+	return a
+}
+
+// useEnv points the app at the services of a GitLab instance and shows
+// its last known state, if any.
+func (a *App) useEnv(env *bootstrap.Env) {
+	a.pipelineSvc = env.Pipelines
+	a.jobSvc = env.Jobs
+	a.mrSvc = env.MRs
+	a.rateMonitor = env.Limiter
+	a.notifier = notify.New(env.Config.Notifications, os.Stderr)
+	a.snapshots = env.Snapshots
+	a.viewed = env.Viewed
+	a.mrDetailView.SetCollapse(env.Config.Collapsed)
+	a.snapshot = cache.Snapshot{}
+	a.stale = false
+	a.offline = false
+	a.dataTime = time.Time{}
+	if env.Snapshot != nil {
+		a.applySnapshot(env.Snapshot)
 	}
 }
 
-type projectsLoadedMsg struct{ projects []entity.Project }
-type pipelinesLoadedMsg struct{ pipelines []entity.Pipeline }
+type projectsLoadedMsg struct {
+	projects []entity.Project
+	partial  *entity.PartialError
+	seq      int
+}
+type pipelinesLoadedMsg struct {
+	pipelines []entity.Pipeline
+	seq       int
+}
 type jobsLoadedMsg struct{ jobs []entity.Job }
-type logLoadedMsg struct {
-	content string
-	jobName string
+type logChunkMsg struct {
+	seq       int
+	projectID int
+	jobID     int
+	chunk     *entity.LogChunk
+}
+type logPollMsg struct {
+	seq       int
+	projectID int
+	jobID     int
+	offset    int64
+}
+type logTailFailedMsg struct {
+	logPollMsg // the poll to retry
+	err        error
 }
 type jobActionDoneMsg struct {
 	job *entity.Job
 	err error
 }
-type mrsLoadedMsg struct{ mrs []entity.MergeRequest }
+type mrsLoadedMsg struct {
+	mrs      []entity.MergeRequest
+	projects []entity.Project // resolved on the way, kept for the snapshot
+	partial  *entity.PartialError
+	seq      int
+	limit    int
+	more     bool // some project has more merge requests than the limit
+}
 type mrDetailLoadedMsg struct{ mr *entity.MergeRequest }
-type mrDiffsLoadedMsg struct{ diffs []entity.MRDiff }
-type mrNotesLoadedMsg struct{ notes []entity.MRNote }
-type commitsLoadedMsg struct{ commits []entity.Commit }
+type mrDiffsLoadedMsg struct {
+	diffs         []entity.MRDiff
+	viewedVersion string
+	viewed        []string
+}
+type mrDiscussionsLoadedMsg struct{ discussions []entity.Discussion }
+type commitsLoadedMsg struct {
+	commits []entity.Commit
+	limit   int
+}
 type mrCreatedMsg struct {
 	mr  *entity.MergeRequest
 	err error
 }
+type pipelineCreatedMsg struct {
+	pipeline *entity.Pipeline
+	err      error
+}
+type pipelineActionDoneMsg struct {
+	pipeline *entity.Pipeline
+	err      error
+}
+type jobNeedsLoadedMsg struct {
+	pipelineID int
+	needs      map[string][]string
+	err        error
+}
 type mrApprovedMsg struct{ err error }
 type mrMergedMsg struct {
 	mr  *entity.MergeRequest
 	err error
 }
+type mrCommentedMsg struct {
+	projectID, mrIID int
+	err              error
+}
+type mrResolvedMsg struct {
+	projectID, mrIID int
+	err              error
+}
 type loadingStatusMsg struct{ text string }
 type errMsg struct{ err error }
-type tickMsg time.Time
+type contextSwitchedMsg struct {
+	env *bootstrap.Env
+	err error
+	seq int
+}
+
+// loadMoreFailedMsg reports a failed "load more" of a list, fetched with
+// the given limit.
+type loadMoreFailedMsg struct {
+	view  viewID
+	limit int
+	err   error
+}
+type tickMsg struct{ seq int }
 
 func (a App) Init() tea.Cmd {
 	a.loading = true
@@ -111,40 +237,78 @@ func (a App) Init() tea.Cmd {
 }
 
 func (a App) tick() tea.Cmd {
-	return tea.Tick(a.cfg.RefreshInterval, func(t time.Time) tea.Msg { return tickMsg(t) })
+	seq := a.tickSeq
+	return tea.Tick(a.refreshInterval(), func(time.Time) tea.Msg { return tickMsg{seq: seq} })
 }
 
 func (a App) loadProjects() tea.Cmd {
+	seq := a.ctxSeq
 	return func() tea.Msg {
 		projects, err := a.pipelineSvc.LoadProjects(context.Background(), a.cfg.Projects)
+		partial, err := entity.AsPartial(err)
 		if err != nil {
 			return errMsg{err}
 		}
-		return projectsLoadedMsg{projects}
+		return projectsLoadedMsg{projects: projects, partial: partial, seq: seq}
 	}
 }
 
 func (a App) loadAllPipelines() tea.Cmd {
 	projects := a.cfg.Projects
 	limit := a.cfg.PipelineLimit
+	seq := a.ctxSeq
 	return func() tea.Msg {
 		pls, err := a.pipelineSvc.LoadAllPipelines(context.Background(), projects, limit)
+		partial, err := entity.AsPartial(err)
 		if err != nil {
 			return errMsg{err}
 		}
-		return allPipelinesLoadedMsg{pls}
+		return allPipelinesLoadedMsg{pipelines: pls, partial: partial, seq: seq}
 	}
 }
 
-type allPipelinesLoadedMsg struct{ pipelines []entity.Pipeline }
+type allPipelinesLoadedMsg struct {
+	pipelines []entity.Pipeline
+	partial   *entity.PartialError
+	seq       int
+}
+
+// setWarning shows partial failures in the status line, or clears it.
+func (a *App) setWarning(partial *entity.PartialError) {
+	if partial == nil {
+		a.warning = ""
+		return
+	}
+	paths := make([]string, len(partial.Failed))
+	for i, f := range partial.Failed {
+		paths[i] = f.Path
+	}
+	a.warning = fmt.Sprintf("%d of %d projects failed to load: %s",
+		len(partial.Failed), partial.Total, strings.Join(paths, ", "))
+}
 
 func (a App) loadPipelines(projectID int) tea.Cmd {
+	seq := a.ctxSeq
 	return func() tea.Msg {
 		pls, err := a.pipelineSvc.ListPipelines(context.Background(), projectID)
 		if err != nil {
 			return errMsg{err}
 		}
-		return pipelinesLoadedMsg{pls}
+		return pipelinesLoadedMsg{pipelines: pls, seq: seq}
+	}
+}
+
+// sendNotifications delivers pipeline status alerts in the background.
+// OSC and bell sequences go to stderr, which is still the terminal but
+// isn't written by the renderer.
+func (a App) sendNotifications(events []notify.Event) tea.Cmd {
+	if len(events) == 0 {
+		return nil
+	}
+	n := a.notifier
+	return func() tea.Msg {
+		n.Send(context.Background(), events)
+		return nil
 	}
 }
 
@@ -158,24 +322,35 @@ func (a App) loadJobs(projectID, pipelineID int) tea.Cmd {
 	}
 }
 
-func (a App) loadLog(projectID, jobID int, jobName string) tea.Cmd {
+// loadJobNeeds fetches the needs: edges for the job graph of the selected
+// pipeline.
+func (a App) loadJobNeeds() tea.Cmd {
+	pl := a.selectedPipeline
+	if pl == nil {
+		return nil
+	}
 	return func() tea.Msg {
-		rc, err := a.jobSvc.GetJobLog(context.Background(), projectID, jobID)
-		if err != nil {
-			return errMsg{err}
-		}
-		defer rc.Close()
-		data, err := io.ReadAll(rc)
+		needs, err := a.pipelineSvc.JobNeeds(context.Background(), pl.ProjectPath, pl.IID)
+		return jobNeedsLoadedMsg{pipelineID: pl.ID, needs: needs, err: err}
+	}
+}
+
+func (a App) tailLog(seq, projectID, jobID int, offset int64) tea.Cmd {
+	return func() tea.Msg {
+		chunk, err := a.jobSvc.TailJobLog(context.Background(), projectID, jobID, offset)
 		if err != nil {
-			return errMsg{err}
-		}
-		return logLoadedMsg{
-			content: string(data),
-			jobName: jobName,
+			return logTailFailedMsg{logPollMsg{seq: seq, projectID: projectID, jobID: jobID, offset: offset}, err}
 		}
+		return logChunkMsg{seq: seq, projectID: projectID, jobID: jobID, chunk: chunk}
 	}
 }
 
+func (a App) scheduleLogPoll(seq, projectID, jobID int, offset int64, delay time.Duration) tea.Cmd {
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		return logPollMsg{seq: seq, projectID: projectID, jobID: jobID, offset: offset}
+	})
+}
+
 func (a App) doJobAction(action string, projectID, jobID int) tea.Cmd {
 	return func() tea.Msg {
 		var job *entity.Job
@@ -196,35 +371,55 @@ func (a App) doJobAction(action string, projectID, jobID int) tea.Cmd {
 	}
 }
 
+// listPage is how many merge requests or commits the lists fetch at first
+// and add on each "load more".
+const listPage = 50
+
 func (a App) loadMRs(projectID int) tea.Cmd {
+	limit := a.mrLimit
+	seq := a.ctxSeq
 	return func() tea.Msg {
-		mrs, err := a.mrSvc.ListMRs(context.Background(), projectID, "opened")
+		mrs, err := a.mrSvc.ListMRs(context.Background(), projectID, "opened", limit)
 		if err != nil {
 			return errMsg{err}
 		}
-		return mrsLoadedMsg{mrs}
+		return mrsLoadedMsg{mrs: mrs, seq: seq, limit: limit, more: len(mrs) >= limit}
 	}
 }
 
 func (a App) loadAllMRs() tea.Cmd {
+	limit := a.mrLimit
+	seq := a.ctxSeq
 	return func() tea.Msg {
 		// Resolve project IDs via GetByPath (fast, exact match)
-		projects, err := a.pipelineSvc.LoadProjects(context.Background(), a.cfg.Projects)
+		projects, err := a.pipelineSvc.ResolveProjects(context.Background(), a.cfg.Projects)
+		resolvePartial, err := entity.AsPartial(err)
 		if err != nil {
 			return errMsg{err}
 		}
-		var allMRs []entity.MergeRequest
-		for _, p := range projects {
-			mrs, err := a.mrSvc.ListMRs(context.Background(), p.ID, "opened")
-			if err != nil {
-				continue
+		allMRs, err := a.mrSvc.ListProjectsMRs(context.Background(), projects, "opened", limit)
+		listPartial, err := entity.AsPartial(err)
+		if err != nil {
+			return errMsg{err}
+		}
+		// Merge failures from both stages so the status line covers every project
+		var partial *entity.PartialError
+		if resolvePartial != nil || listPartial != nil {
+			partial = &entity.PartialError{Total: len(a.cfg.Projects)}
+			if resolvePartial != nil {
+				partial.Failed = append(partial.Failed, resolvePartial.Failed...)
 			}
-			for i := range mrs {
-				mrs[i].ProjectPath = p.PathWithNS
+			if listPartial != nil {
+				partial.Failed = append(partial.Failed, listPartial.Failed...)
 			}
-			allMRs = append(allMRs, mrs...)
 		}
-		return mrsLoadedMsg{allMRs}
+		perProject := make(map[int]int)
+		more := false
+		for _, mr := range allMRs {
+			perProject[mr.ProjectID]++
+			more = more || perProject[mr.ProjectID] >= limit
+		}
+		return mrsLoadedMsg{mrs: allMRs, projects: projects, partial: partial, seq: seq, limit: limit, more: more}
 	}
 }
 
@@ -244,27 +439,76 @@ func (a App) loadMRDiffs(projectID, mrIID int) tea.Cmd {
 		if err != nil {
 			return errMsg{err}
 		}
-		return mrDiffsLoadedMsg{diffs}
+		msg := mrDiffsLoadedMsg{diffs: diffs}
+		if a.viewed != nil {
+			msg.viewedVersion, msg.viewed = a.viewed.Load(projectID, mrIID)
+		}
+		return msg
 	}
 }
 
-func (a App) loadMRNotes(projectID, mrIID int) tea.Cmd {
+func (a App) loadMRDiscussions(projectID, mrIID int) tea.Cmd {
 	return func() tea.Msg {
-		notes, err := a.mrSvc.ListNotes(context.Background(), projectID, mrIID)
+		discussions, err := a.mrSvc.ListDiscussions(context.Background(), projectID, mrIID)
 		if err != nil {
 			return errMsg{err}
 		}
-		return mrNotesLoadedMsg{notes}
+		return mrDiscussionsLoadedMsg{discussions}
 	}
 }
 
 func (a App) loadCommits(projectID int, ref string) tea.Cmd {
+	limit := a.commitLimit
 	return func() tea.Msg {
-		commits, err := a.mrSvc.ListCommits(context.Background(), projectID, ref)
+		commits, err := a.mrSvc.ListCommits(context.Background(), projectID, ref, limit)
 		if err != nil {
 			return errMsg{err}
 		}
-		return commitsLoadedMsg{commits}
+		return commitsLoadedMsg{commits: commits, limit: limit}
+	}
+}
+
+// loadMore runs the load of a list with a raised limit and turns its error
+// into a loadMoreFailedMsg.
+func loadMore(load tea.Cmd, view viewID, limit int) tea.Cmd {
+	return func() tea.Msg {
+		msg := load()
+		if e, ok := msg.(errMsg); ok {
+			return loadMoreFailedMsg{view: view, limit: limit, err: e.err}
+		}
+		return msg
+	}
+}
+
+func (a App) doPipelineAction(action string, projectID, pipelineID int) tea.Cmd {
+	return func() tea.Msg {
+		var pl *entity.Pipeline
+		var err error
+		switch action {
+		case "retry_pipeline":
+			pl, err = a.pipelineSvc.RetryPipeline(context.Background(), projectID, pipelineID)
+		case "cancel_pipeline":
+			pl, err = a.pipelineSvc.CancelPipeline(context.Background(), projectID, pipelineID)
+		}
+		return pipelineActionDoneMsg{pipeline: pl, err: err}
+	}
+}
+
+func (a App) doCreatePipeline(projectPath, ref string, variables []entity.PipelineVariable) tea.Cmd {
+	return func() tea.Msg {
+		projects, err := a.pipelineSvc.ResolveProjects(context.Background(), []string{projectPath})
+		if err != nil || len(projects) == 0 {
+			if err == nil {
+				err = fmt.Errorf("project %q not found", projectPath)
+			}
+			return pipelineCreatedMsg{err: err}
+		}
+		pl, err := a.pipelineSvc.CreatePipeline(context.Background(), projects[0].ID, ref, variables)
+		if err != nil {
+			return pipelineCreatedMsg{err: err}
+		}
+		pl.ProjectPath = projects[0].PathWithNS
+		return pipelineCreatedMsg{pipeline: pl}
 	}
 }
 
@@ -275,27 +519,76 @@ func (a App) doApproveMR(projectID, mrIID int) tea.Cmd {
 	}
 }
 
-func (a App) doMergeMR(projectID, mrIID int) tea.Cmd {
+func (a App) doMergeMR(projectID, mrIID int, opts entity.MergeOptions) tea.Cmd {
 	return func() tea.Msg {
-		mr, err := a.mrSvc.MergeMR(context.Background(), projectID, mrIID)
+		mr, err := a.mrSvc.MergeMR(context.Background(), projectID, mrIID, opts)
 		return mrMergedMsg{mr: mr, err: err}
 	}
 }
 
 
+// doComment posts a general comment, a comment on a diff line or a reply,
+// depending on what the view filled in.
+func (a App) doComment(c views.MRCommentMsg) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		var err error
+		switch {
+		case c.DiscussionID != "":
+			_, err = a.mrSvc.ReplyToDiscussion(ctx, c.MR.ProjectID, c.MR.IID, c.DiscussionID, c.Body)
+		case c.Position != nil:
+			_, err = a.mrSvc.AddDiffNote(ctx, c.MR.ProjectID, c.MR.IID, c.Body, *c.Position)
+		default:
+			_, err = a.mrSvc.AddNote(ctx, c.MR.ProjectID, c.MR.IID, c.Body)
+		}
+		return mrCommentedMsg{projectID: c.MR.ProjectID, mrIID: c.MR.IID, err: err}
+	}
+}
+
+func (a App) doResolve(r views.MRResolveMsg) tea.Cmd {
+	return func() tea.Msg {
+		_, err := a.mrSvc.ResolveDiscussion(context.Background(), r.MR.ProjectID, r.MR.IID, r.DiscussionID, r.Resolved)
+		return mrResolvedMsg{projectID: r.MR.ProjectID, mrIID: r.MR.IID, err: err}
+	}
+}
+
 func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Any keypress means someone is watching: drop the idle backoff and
+	// reschedule the pending tick at the normal pace
+	if _, ok := msg.(tea.KeyMsg); ok && a.idleRefreshes >= idleBackoff1 {
+		a.idleRefreshes = 0
+		a.tickSeq++
+		model, cmd := a.Update(msg)
+		return model, tea.Batch(cmd, model.(App).tick())
+	}
+	if a.mergeDialog != nil {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			d, result := a.mergeDialog.Update(keyMsg)
+			a.mergeDialog = &d
+			if result != nil {
+				a.mergeDialog = nil
+				if result.Confirmed {
+					return a, a.doMergeMR(result.ProjectID, result.MRIID, result.Opts)
+				}
+			}
+			return a, nil
+		}
+	}
 	if a.confirmDialog != nil {
 		if keyMsg, ok := msg.(tea.KeyMsg); ok {
 			d, result := a.confirmDialog.Update(keyMsg)
 			a.confirmDialog = &d
 			if result != nil {
 				a.confirmDialog = nil
+				if result.Confirmed && len(result.Targets) > 0 {
+					return a, a.startBulk(result.Action, result.Targets)
+				}
 				if result.Confirmed {
 					switch result.Action {
 					case "approve_mr":
 						return a, a.doApproveMR(result.ProjectID, result.JobID)
-					case "merge_mr":
-						return a, a.doMergeMR(result.ProjectID, result.JobID)
+					case "retry_pipeline", "cancel_pipeline":
+						return a, a.doPipelineAction(result.Action, result.ProjectID, result.JobID)
 					default:
 						return a, a.doJobAction(result.Action, result.ProjectID, result.JobID)
 					}
@@ -312,6 +605,7 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.height = msg.Height
 		a.pipelinesView.SetHeight(msg.Height)
 		a.jobsView.SetHeight(msg.Height)
+		a.jobsView.SetWidth(msg.Width)
 		a.mergeRequestsView.SetHeight(msg.Height)
 		a.commitsView.SetHeight(msg.Height)
 		a.logView, _ = a.logView.Update(msg)
@@ -335,6 +629,10 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "esc":
 			return a, a.goBack()
 		case "tab":
+			// The MR detail view uses Tab for its own Diffs/Comments tabs
+			if a.currentView == viewMRDetail {
+				return a, a.delegateToView(msg)
+			}
 			return a, a.nextView()
 		case "shift+tab":
 			return a, a.prevView()
@@ -349,6 +647,8 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "5":
 			return a, a.switchToView(viewMRs)
+		case "C":
+			return a, a.nextContext()
 		}
 		// Normalize key for views
 		normalizedMsg := tea.KeyMsg(tea.Key{Type: msg.Type, Runes: []rune(key)})
@@ -356,49 +656,144 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			normalizedMsg = tea.KeyMsg(tea.Key{Type: tea.KeyRunes, Runes: []rune(key)})
 		}
 		return a, a.delegateToView(normalizedMsg)
+	case contextSwitchedMsg:
+		if msg.seq != a.ctxSeq {
+			return a, nil
+		}
+		a.switching = false
+		a.loading = false
+		a.loadingStatus = ""
+		if msg.err != nil {
+			a.err = msg.err // a.cfg still holds the previous context
+			return a, nil
+		}
+		return a, a.useContext(msg.env)
 	case projectsLoadedMsg:
+		if msg.seq != a.ctxSeq {
+			return a, nil
+		}
 		a.err = nil
 		a.loading = false
 		a.loadingStatus = ""
 		a.projectsView.Projects = msg.projects
+		a.setWarning(msg.partial)
+		a.markFresh()
+		a.snapshot.Projects = msg.projects
+		return a, a.saveSnapshot()
 	case allPipelinesLoadedMsg:
+		if msg.seq != a.ctxSeq {
+			return a, nil
+		}
 		a.err = nil
 		a.loading = false
 		a.loadingStatus = ""
 		a.pipelinesView.Limit = a.cfg.PipelineLimit
 		a.pipelinesView.SetPipelines(msg.pipelines)
+		a.setWarning(msg.partial)
+		a.noteRefresh(pipelinesFingerprint(msg.pipelines))
+		a.markFresh()
+		a.snapshot.Pipelines = msg.pipelines
+		return a, tea.Batch(a.saveSnapshot(), a.sendNotifications(a.notifier.Observe(msg.pipelines, msg.partial)))
 	case pipelinesLoadedMsg:
+		if msg.seq != a.ctxSeq {
+			return a, nil
+		}
 		a.err = nil
 		a.loading = false
 		a.loadingStatus = ""
 		a.pipelinesView.Pipelines = msg.pipelines
+		a.noteRefresh(pipelinesFingerprint(msg.pipelines))
 	case jobsLoadedMsg:
+		// Drop a late response for the pipeline the user just left
+		if len(msg.jobs) > 0 && a.selectedPipeline != nil && msg.jobs[0].PipelineID != a.selectedPipeline.ID {
+			return a, nil
+		}
 		a.err = nil
 		a.loading = false
 		a.loadingStatus = ""
 		a.jobsView.Jobs = msg.jobs
+		a.noteRefresh(jobsFingerprint(msg.jobs))
 		if a.jobsView.Cursor >= len(msg.jobs) {
 			a.jobsView.Cursor = max(0, len(msg.jobs)-1)
 		}
-	case logLoadedMsg:
+		if a.selectedPipeline != nil && a.jobsView.WantsNeeds(a.selectedPipeline.ID) {
+			return a, a.loadJobNeeds()
+		}
+	case views.JobNeedsRequestMsg:
+		return a, a.loadJobNeeds()
+	case jobNeedsLoadedMsg:
+		if a.selectedPipeline == nil || msg.pipelineID != a.selectedPipeline.ID {
+			return a, nil
+		}
+		if msg.err != nil {
+			// The graph still works without edges, e.g. with GraphQL disabled
+			a.err = fmt.Errorf("loading job needs: %w", msg.err)
+		}
+		a.jobsView.SetNeeds(msg.pipelineID, msg.needs)
+	case logChunkMsg:
+		if msg.seq != a.logSeq {
+			return a, nil
+		}
 		a.err = nil
-		a.loading = false
-		a.loadingStatus = ""
-		a.logView, _ = a.logView.Update(views.LogContentMsg{
-			Content: msg.content,
-			JobName: msg.jobName,
+		a.logFailures = 0
+		// A manual or scheduled job is tailed again once it is started and reopened
+		running := !msg.chunk.JobStatus.IsTerminal() && !msg.chunk.JobStatus.IsWaiting()
+		a.logView, _ = a.logView.Update(views.LogAppendMsg{
+			Content: msg.chunk.Data,
+			Running: running,
 		})
+		if running {
+			return a, a.scheduleLogPoll(msg.seq, msg.projectID, msg.jobID, msg.chunk.Offset, logPollInterval)
+		}
+	case logTailFailedMsg:
+		if msg.seq != a.logSeq {
+			return a, nil
+		}
+		// Keep tailing through transient errors, backing off while they last
+		a.err = msg.err
+		a.logFailures++
+		delay := min(logPollInterval<<min(a.logFailures, 5), maxLogPollInterval)
+		return a, a.scheduleLogPoll(msg.seq, msg.projectID, msg.jobID, msg.offset, delay)
+	case logPollMsg:
+		// Stop tailing once the user has left the log or opened another job
+		if msg.seq != a.logSeq || a.currentView != viewLog {
+			return a, nil
+		}
+		return a, a.tailLog(msg.seq, msg.projectID, msg.jobID, msg.offset)
 	case jobActionDoneMsg:
 		if msg.err != nil {
 			a.err = msg.err
 		} else if a.selectedPipeline != nil {
 			return a, a.loadJobs(a.selectedPipeline.ProjectID, a.selectedPipeline.ID)
 		}
+	case bulkStepMsg:
+		return a, a.onBulkStep(msg)
+	case views.JobBulkActionMsg:
+		a.confirmJobBulk(msg)
+	case views.PipelineBulkActionMsg:
+		a.confirmPipelineBulk(msg)
+	case pipelineActionDoneMsg:
+		if msg.err != nil {
+			a.err = msg.err
+			return a, nil
+		}
+		a.err = nil
+		return a, a.refreshCurrentView()
 	case mrsLoadedMsg:
+		if msg.seq != a.ctxSeq || msg.limit != a.mrLimit {
+			return a, nil // from the previous context, or started before "load more"
+		}
 		a.err = nil
 		a.loading = false
 		a.loadingStatus = ""
 		a.mergeRequestsView.SetMRs(msg.mrs)
+		a.mergeRequestsView.HasMore = msg.more
+		a.setWarning(msg.partial)
+		a.noteRefresh(mrsFingerprint(msg.mrs))
+		a.markFresh()
+		a.snapshot.MRs = msg.mrs
+		a.snapshot.Projects = mergeResolved(a.snapshot.Projects, msg.projects)
+		return a, a.saveSnapshot()
 	case mrDetailLoadedMsg:
 		a.err = nil
 		a.loading = false
@@ -406,15 +801,36 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.mrDetailView.SetMR(msg.mr)
 	case mrDiffsLoadedMsg:
 		a.err = nil
+		a.mrDetailView.SetViewed(msg.viewedVersion, msg.viewed)
 		a.mrDetailView.SetDiffs(msg.diffs)
-	case mrNotesLoadedMsg:
+	case mrDiscussionsLoadedMsg:
 		a.err = nil
-		a.mrDetailView.SetNotes(msg.notes)
+		a.mrDetailView.SetDiscussions(msg.discussions)
+	case views.MRLoadMoreMsg:
+		a.mrLimit += listPage
+		return a, loadMore(a.loadAllMRs(), viewMRs, a.mrLimit)
 	case commitsLoadedMsg:
+		if msg.limit != a.commitLimit {
+			return a, nil
+		}
 		a.err = nil
 		a.loading = false
 		a.loadingStatus = ""
-		a.commitsView.Commits = msg.commits
+		a.commitsView.SetCommits(msg.commits, len(msg.commits) >= msg.limit)
+	case views.CommitsLoadMoreMsg:
+		a.commitLimit += listPage
+		return a, loadMore(a.loadCommits(a.commitsView.ProjectID, a.commitsView.Ref), viewCommits, a.commitLimit)
+	case loadMoreFailedMsg:
+		// Go back to the previous limit so that moving down asks again
+		switch {
+		case msg.view == viewMRs && msg.limit == a.mrLimit:
+			a.mrLimit -= listPage
+			a.mergeRequestsView.LoadMoreFailed()
+		case msg.view == viewCommits && msg.limit == a.commitLimit:
+			a.commitLimit -= listPage
+			a.commitsView.LoadMoreFailed()
+		}
+		return a.Update(errMsg{msg.err})
 	case mrApprovedMsg:
 		if msg.err != nil {
 			a.err = msg.err
@@ -428,13 +844,17 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, a.loadMRDetail(a.selectedMR.ProjectID, a.selectedMR.IID)
 		}
 	case views.MRCreateSubmitMsg:
+		if a.offline {
+			a.err = errOffline
+			return a, nil
+		}
 		a.loading = true
 		a.loadingStatus = "Creating merge request..."
 		projectPath := msg.ProjectPath
 		opts := msg.Opts
 		return a, func() tea.Msg {
 			// Resolve project ID from path
-			projects, err := a.pipelineSvc.LoadProjects(context.Background(), []string{projectPath})
+			projects, err := a.pipelineSvc.ResolveProjects(context.Background(), []string{projectPath})
 			if err != nil || len(projects) == 0 {
 				if err == nil {
 					err = fmt.Errorf("project %q not found", projectPath)
@@ -444,6 +864,32 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			mr, err := a.mrSvc.CreateMR(context.Background(), projects[0].ID, opts)
 			return mrCreatedMsg{mr: mr, err: err}
 		}
+	case views.PipelineCreateSubmitMsg:
+		if a.offline {
+			a.err = errOffline
+			return a, nil
+		}
+		a.loading = true
+		a.loadingStatus = fmt.Sprintf("Starting pipeline on %s...", msg.Ref)
+		return a, a.doCreatePipeline(msg.ProjectPath, msg.Ref, msg.Variables)
+	case views.PipelineCreateCancelMsg:
+		a.currentView = viewPipelines
+		a.breadcrumb.Parts = nil
+	case pipelineCreatedMsg:
+		a.loading = false
+		a.loadingStatus = ""
+		if msg.err != nil {
+			a.err = msg.err
+			a.currentView = viewPipelines
+			a.breadcrumb.Parts = nil
+			return a, nil
+		}
+		a.err = nil
+		a.selectedPipeline = msg.pipeline
+		a.parentPipelines = nil
+		a.currentView = viewJobs
+		a.breadcrumb.Parts = a.jobsBreadcrumb()
+		return a, a.loadJobs(msg.pipeline.ProjectID, msg.pipeline.ID)
 	case views.MRCreateCancelMsg:
 		a.currentView = viewMRs
 		a.breadcrumb.Parts = nil
@@ -464,22 +910,32 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return a, tea.Batch(
 				a.loadMRDetail(msg.mr.ProjectID, msg.mr.IID),
 				a.loadMRDiffs(msg.mr.ProjectID, msg.mr.IID),
-				a.loadMRNotes(msg.mr.ProjectID, msg.mr.IID),
+				a.loadMRDiscussions(msg.mr.ProjectID, msg.mr.IID),
 			)
 		}
 	case loadingStatusMsg:
 		a.loadingStatus = msg.text
 	case errMsg:
-		a.err = msg.err
 		a.loading = false
 		a.loadingStatus = ""
+		if a.goOffline(msg.err) {
+			// Keep showing the last known data instead of an error
+			a.err = nil
+			return a, nil
+		}
+		a.err = msg.err
 	case tickMsg:
+		if msg.seq != a.tickSeq {
+			return a, nil
+		}
 		var cmds []tea.Cmd
 		cmds = append(cmds, a.tick())
 		if !a.loading {
-			a.loading = true
-			a.loadingStatus = fmt.Sprintf("Refreshing %d projects...", len(a.cfg.Projects))
-			cmds = append(cmds, a.refreshCurrentView())
+			if cmd := a.refreshCurrentView(); cmd != nil {
+				a.loading = true
+				a.loadingStatus = fmt.Sprintf("Refreshing %d projects...", len(a.cfg.Projects))
+				cmds = append(cmds, cmd)
+			}
 		}
 		return a, tea.Batch(cmds...)
 	case views.PipelineLimitCycleMsg:
@@ -503,11 +959,11 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		field := msg.Field
 		query := msg.Query
 		return a, func() tea.Msg {
-			projects, err := a.pipelineSvc.LoadProjects(context.Background(), []string{projectPath})
+			projects, err := a.pipelineSvc.ResolveProjects(context.Background(), []string{projectPath})
 			if err != nil || len(projects) == 0 {
 				return views.MRBranchSearchResultMsg{Field: field}
 			}
-			branches, err := a.pipelineSvc.ListBranches(context.Background(), projects[0].ID, query)
+			branches, err := a.pipelineSvc.ListBranches(context.Background(), projects[0].ID, query, 20)
 			if err != nil {
 				return views.MRBranchSearchResultMsg{Field: field}
 			}
@@ -516,9 +972,26 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case views.MRBranchSearchResultMsg:
 		a.mrCreateView, _ = a.mrCreateView.Update(msg)
 		return a, nil
+	case views.PipelineRefSearchMsg:
+		projectPath := msg.ProjectPath
+		query := msg.Query
+		return a, func() tea.Msg {
+			projects, err := a.pipelineSvc.ResolveProjects(context.Background(), []string{projectPath})
+			if err != nil || len(projects) == 0 {
+				return views.PipelineRefSearchResultMsg{Query: query}
+			}
+			branches, err := a.pipelineSvc.ListBranches(context.Background(), projects[0].ID, query, 20)
+			if err != nil {
+				return views.PipelineRefSearchResultMsg{Query: query}
+			}
+			return views.PipelineRefSearchResultMsg{Branches: branches, Query: query}
+		}
+	case views.PipelineRefSearchResultMsg:
+		a.pipelineCreateView, _ = a.pipelineCreateView.Update(msg)
+		return a, nil
 	case views.ProjectSearchMsg:
 		return a, func() tea.Msg {
-			results, err := a.pipelineSvc.SearchProjects(context.Background(), msg.Query)
+			results, err := a.pipelineSvc.SearchProjects(context.Background(), msg.Query, 10)
 			if err != nil {
 				return errMsg{err}
 			}
@@ -547,23 +1020,26 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.breadcrumb.Parts = []string{msg.Project.PathWithNS}
 		return a, a.loadAllPipelines()
 	case views.PipelineSelectedMsg:
+		if a.selectedPipeline == nil || a.selectedPipeline.ID != msg.Pipeline.ID {
+			a.jobsView.ClearMarks()
+		}
 		a.selectedPipeline = &msg.Pipeline
+		a.parentPipelines = nil
 		a.currentView = viewJobs
-		a.breadcrumb.Parts = []string{
-			msg.Pipeline.ProjectPath,
-			fmt.Sprintf("#%d", msg.Pipeline.ID),
-		}
+		a.breadcrumb.Parts = a.jobsBreadcrumb()
 		return a, a.loadJobs(msg.Pipeline.ProjectID, msg.Pipeline.ID)
 	case views.JobSelectedMsg:
 		a.currentView = viewLog
-		a.breadcrumb.Parts = []string{
-			a.selectedPipeline.ProjectPath,
-			fmt.Sprintf("#%d", a.selectedPipeline.ID),
-			msg.Job.Name,
-		}
-		return a, a.loadLog(msg.Job.ProjectID, msg.Job.ID, msg.Job.Name)
+		a.breadcrumb.Parts = append(a.jobsBreadcrumb(), msg.Job.Name)
+		a.logSeq++
+		a.logFailures = 0
+		a.logView.Reset(msg.Job.Name)
+		return a, a.tailLog(a.logSeq, msg.Job.ProjectID, msg.Job.ID, 0)
+	case views.JobDownstreamMsg:
+		return a, a.openDownstream(msg.Job)
 	case views.MRSelectedMsg:
 		a.selectedMR = &msg.MR
+		a.mrDetailView.SetMR(a.selectedMR)
 		a.currentView = viewMRDetail
 		a.breadcrumb.Parts = []string{
 			msg.MR.ProjectPath,
@@ -572,16 +1048,45 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, tea.Batch(
 			a.loadMRDetail(msg.MR.ProjectID, msg.MR.IID),
 			a.loadMRDiffs(msg.MR.ProjectID, msg.MR.IID),
-			a.loadMRNotes(msg.MR.ProjectID, msg.MR.IID),
+			a.loadMRDiscussions(msg.MR.ProjectID, msg.MR.IID),
 		)
+	case views.MRCommentMsg:
+		if a.offline {
+			a.mrDetailView.CommentDone(errOffline)
+			return a, nil
+		}
+		return a, a.doComment(msg)
+	case mrCommentedMsg:
+		a.mrDetailView.CommentDone(msg.err)
+		if msg.err == nil {
+			return a, a.loadMRDiscussions(msg.projectID, msg.mrIID)
+		}
+	case views.MRViewedMsg:
+		return a, a.saveViewed(msg)
+	case views.MRResolveMsg:
+		if a.offline {
+			a.err = errOffline
+			return a, nil
+		}
+		return a, a.doResolve(msg)
+	case mrResolvedMsg:
+		if msg.err != nil {
+			a.err = msg.err
+			return a, nil
+		}
+		return a, a.loadMRDiscussions(msg.projectID, msg.mrIID)
 	case views.MRRefreshMsg:
 		a.mrDetailView.ForceReset()
 		return a, tea.Batch(
 			a.loadMRDetail(msg.MR.ProjectID, msg.MR.IID),
 			a.loadMRDiffs(msg.MR.ProjectID, msg.MR.IID),
-			a.loadMRNotes(msg.MR.ProjectID, msg.MR.IID),
+			a.loadMRDiscussions(msg.MR.ProjectID, msg.MR.IID),
 		)
 	case views.MRApproveMsg:
+		if a.offline {
+			a.err = errOffline
+			return a, nil
+		}
 		confirm := components.NewConfirmDialog(
 			fmt.Sprintf("Approve MR !%d?", msg.MR.IID),
 			"approve_mr",
@@ -590,14 +1095,33 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		)
 		a.confirmDialog = &confirm
 	case views.MRMergeMsg:
+		if a.offline {
+			a.err = errOffline
+			return a, nil
+		}
+		dialog := components.NewMergeDialog(msg.MR)
+		a.mergeDialog = &dialog
+	case views.PipelineActionMsg:
+		if a.offline {
+			a.err = errOffline
+			return a, nil
+		}
+		question := "Retry all failed jobs of pipeline #%d (%s, %s)?"
+		if msg.Action == "cancel" {
+			question = "Cancel pipeline #%d (%s, %s)?"
+		}
 		confirm := components.NewConfirmDialog(
-			fmt.Sprintf("Merge MR !%d?", msg.MR.IID),
-			"merge_mr",
-			msg.MR.ProjectID,
-			msg.MR.IID,
+			fmt.Sprintf(question, msg.Pipeline.ID, msg.Pipeline.ProjectPath, msg.Pipeline.Ref),
+			msg.Action+"_pipeline",
+			msg.Pipeline.ProjectID,
+			msg.Pipeline.ID,
 		)
 		a.confirmDialog = &confirm
 	case views.JobActionMsg:
+		if a.offline {
+			a.err = errOffline
+			return a, nil
+		}
 		actionLabel := map[string]string{
 			"play":   "Run",
 			"retry":  "Retry",
@@ -620,10 +1144,16 @@ func (a *App) isViewInputMode() bool {
 		return a.projectsView.IsInputMode()
 	case viewPipelines:
 		return a.pipelinesView.IsInputMode()
+	case viewLog:
+		return a.logView.IsInputMode()
 	case viewMRs:
 		return a.mergeRequestsView.IsInputMode()
 	case viewMRCreate:
 		return a.mrCreateView.IsInputMode()
+	case viewPipelineCreate:
+		return a.pipelineCreateView.IsInputMode()
+	case viewMRDetail:
+		return a.mrDetailView.IsInputMode()
 	}
 	return false
 }
@@ -646,12 +1176,29 @@ func (a *App) delegateToView(msg tea.KeyMsg) tea.Cmd {
 			if len(pls) > 0 {
 				pl := pls[a.pipelinesView.Cursor]
 				a.currentView = viewCommits
+				a.commitsView.ProjectID = pl.ProjectID
 				a.commitsView.Ref = pl.Ref
 				a.commitsView.Cursor = 0
+				a.commitsView.SetCommits(nil, false)
+				a.commitLimit = listPage
 				a.breadcrumb.Parts = []string{pl.ProjectPath, pl.Ref, "commits"}
 				return a.loadCommits(pl.ProjectID, pl.Ref)
 			}
 		}
+		if msg.String() == "n" && !a.pipelinesView.IsInputMode() {
+			if a.offline {
+				a.err = errOffline
+				return nil
+			}
+			var project, ref string
+			if pl, ok := a.pipelinesView.Selected(); ok {
+				project, ref = pl.ProjectPath, pl.Ref
+			}
+			a.pipelineCreateView.Activate(a.cfg.Projects, project, ref)
+			a.currentView = viewPipelineCreate
+			a.breadcrumb.Parts = []string{"Run pipeline"}
+			return nil
+		}
 		a.pipelinesView, cmd = a.pipelinesView.Update(msg)
 	case viewJobs:
 		a.jobsView, cmd = a.jobsView.Update(msg)
@@ -659,6 +1206,10 @@ func (a *App) delegateToView(msg tea.KeyMsg) tea.Cmd {
 		a.logView, cmd = a.logView.Update(msg)
 	case viewMRs:
 		if msg.String() == "n" && !a.mergeRequestsView.IsInputMode() {
+			if a.offline {
+				a.err = errOffline
+				return nil
+			}
 			a.mrCreateView.Activate(a.cfg.Projects)
 			a.currentView = viewMRCreate
 			a.breadcrumb.Parts = []string{"New MR"}
@@ -673,6 +1224,8 @@ func (a *App) delegateToView(msg tea.KeyMsg) tea.Cmd {
 		a.mergeRequestsView, cmd = a.mergeRequestsView.Update(msg)
 	case viewMRCreate:
 		a.mrCreateView, cmd = a.mrCreateView.Update(msg)
+	case viewPipelineCreate:
+		a.pipelineCreateView, cmd = a.pipelineCreateView.Update(msg)
 	case viewMRDetail:
 		a.mrDetailView, cmd = a.mrDetailView.Update(msg)
 	case viewCommits:
@@ -693,7 +1246,7 @@ func (a *App) tabIndex() int {
 	}
 	// Sub-views map to their parent for tab purposes
 	switch a.currentView {
-	case viewJobs, viewLog, viewCommits:
+	case viewJobs, viewLog, viewCommits, viewPipelineCreate:
 		return 1 // Pipelines
 	case viewMRDetail, viewMRCreate:
 		return 2 // MRs
@@ -715,6 +1268,7 @@ func (a *App) prevView() tea.Cmd {
 
 func (a *App) switchToView(v viewID) tea.Cmd {
 	a.currentView = v
+	a.clearBulkResult()
 	switch v {
 	case viewProjects:
 		a.breadcrumb.Parts = nil
@@ -728,10 +1282,7 @@ func (a *App) switchToView(v viewID) tea.Cmd {
 		return a.loadAllPipelines()
 	case viewJobs:
 		if a.selectedPipeline != nil {
-			a.breadcrumb.Parts = []string{
-				a.selectedPipeline.ProjectPath,
-				fmt.Sprintf("#%d", a.selectedPipeline.ID),
-			}
+			a.breadcrumb.Parts = a.jobsBreadcrumb()
 			return a.loadJobs(a.selectedPipeline.ProjectID, a.selectedPipeline.ID)
 		}
 		a.currentView = viewPipelines
@@ -754,19 +1305,73 @@ func (a *App) switchToView(v viewID) tea.Cmd {
 	return nil
 }
 
+// nextContext switches to the next configured GitLab context. Resolving
+// its token and opening its caches can take a while, so that happens in the
+// background; a.cfg is only changed once it succeeded.
+func (a *App) nextContext() tea.Cmd {
+	names := a.cfg.ContextNames()
+	if len(names) < 2 || a.switching {
+		return nil
+	}
+	next := names[(slices.Index(names, a.cfg.ActiveContext())+1)%len(names)]
+	cfg := a.cfg.Clone()
+	seq := a.ctxSeq
+	a.switching = true
+	a.loading = true
+	a.loadingStatus = fmt.Sprintf("Switching to %s...", next)
+	return func() tea.Msg {
+		if err := cfg.UseContext(next); err != nil {
+			return contextSwitchedMsg{err: err, seq: seq}
+		}
+		env, err := bootstrap.New(cfg)
+		return contextSwitchedMsg{env: env, err: err, seq: seq}
+	}
+}
+
+// useContext makes the context env was built for the active one, drops all
+// data of the previous instance and reloads.
+func (a *App) useContext(env *bootstrap.Env) tea.Cmd {
+	a.cfg.AdoptContext(env.Config)
+	a.cfg.CurrentContext = a.cfg.ActiveContext()
+	env.Config = a.cfg
+	_ = a.cfg.Save(config.DefaultPath())
+
+	a.logSeq++
+	a.bulkSeq++
+	a.ctxSeq++
+	a.bulk = nil
+	a.jobsView.ClearMarks()
+	a.pipelinesView.ClearMarks()
+	a.selectedProject = nil
+	a.selectedPipeline = nil
+	a.parentPipelines = nil
+	a.selectedMR = nil
+	a.err = nil
+	a.warning = ""
+	a.idleRefreshes = 0
+	a.projectsView.Projects = nil
+	a.pipelinesView.SetPipelines(nil)
+	a.jobsView.Jobs = nil
+	a.mergeRequestsView.Reset()
+	a.mrLimit = listPage
+	a.useEnv(env)
+	return a.switchToView(viewPipelines)
+}
+
 func (a *App) goBack() tea.Cmd {
+	a.clearBulkResult()
 	switch a.currentView {
 	case viewPipelines:
 		return a.switchToView(viewProjects)
 	case viewJobs:
+		if cmd, ok := a.openParentPipeline(); ok {
+			return cmd
+		}
 		a.currentView = viewPipelines
 		a.breadcrumb.Parts = nil
 	case viewLog:
 		a.currentView = viewJobs
-		a.breadcrumb.Parts = []string{
-			a.selectedPipeline.ProjectPath,
-			fmt.Sprintf("#%d", a.selectedPipeline.ID),
-		}
+		a.breadcrumb.Parts = a.jobsBreadcrumb()
 	case viewMRs:
 		return a.switchToView(viewProjects)
 	case viewMRDetail:
@@ -775,7 +1380,7 @@ func (a *App) goBack() tea.Cmd {
 	case viewMRCreate:
 		a.currentView = viewMRs
 		a.breadcrumb.Parts = nil
-	case viewCommits:
+	case viewCommits, viewPipelineCreate:
 		a.currentView = viewPipelines
 		a.breadcrumb.Parts = nil
 	}
@@ -793,12 +1398,7 @@ func (a App) refreshCurrentView() tea.Cmd {
 			return a.loadJobs(a.selectedPipeline.ProjectID, a.selectedPipeline.ID)
 		}
 	case viewLog:
-		if a.selectedPipeline != nil {
-			job := a.jobsView.SelectedJob()
-			if job != nil {
-				return a.loadLog(job.ProjectID, job.ID, job.Name)
-			}
-		}
+		// the log is tailed by its own poll loop
 	case viewMRs:
 		return a.loadAllMRs()
 	case viewMRDetail:
@@ -806,7 +1406,7 @@ func (a App) refreshCurrentView() tea.Cmd {
 			return tea.Batch(
 				a.loadMRDetail(a.selectedMR.ProjectID, a.selectedMR.IID),
 				a.loadMRDiffs(a.selectedMR.ProjectID, a.selectedMR.IID),
-				a.loadMRNotes(a.selectedMR.ProjectID, a.selectedMR.IID),
+				a.loadMRDiscussions(a.selectedMR.ProjectID, a.selectedMR.IID),
 			)
 		}
 	case viewCommits:
@@ -834,18 +1434,46 @@ func (a App) View() string {
 		label := fmt.Sprintf(" %s:%s ", td.key, td.name)
 		if td.id == a.currentView || (a.currentView == viewMRDetail && td.id == viewMRs) ||
 			(a.currentView == viewMRCreate && td.id == viewMRs) ||
-			(a.currentView == viewCommits && td.id == viewPipelines) {
+			((a.currentView == viewCommits || a.currentView == viewPipelineCreate) && td.id == viewPipelines) {
 			tabs += styles.ActiveTab.Render(label)
 		} else {
 			tabs += styles.InactiveTab.Render(label)
 		}
 	}
+	if name := a.cfg.ActiveContext(); name != "" {
+		tabs += styles.HelpDesc.Render("  ⎈ " + name)
+	}
 	bc := a.breadcrumb.View()
 	header := tabs + "\n" + bc + "\n"
 
 	errStr := ""
+	statusLines := 0
 	if a.err != nil {
 		errStr = styles.StatusFailed.Render(fmt.Sprintf("  Error: %v", a.err)) + "\n"
+		statusLines++
+	}
+	if stale := a.staleStatus(); stale != "" {
+		errStr += styles.HelpDesc.Render(stale) + "\n"
+		statusLines++
+	}
+	for i, line := range a.bulkStatus() {
+		if a.width > 0 && len([]rune(line)) > a.width {
+			line = string([]rune(line)[:a.width-1]) + "…"
+		}
+		style := styles.HelpDesc
+		if i > 0 {
+			style = styles.StatusFailed
+		}
+		errStr += style.Render(line) + "\n"
+		statusLines++
+	}
+	if a.warning != "" {
+		warn := "  ⚠ " + a.warning
+		if a.width > 0 && len([]rune(warn)) > a.width {
+			warn = string([]rune(warn)[:a.width-1]) + "…"
+		}
+		errStr += styles.StatusManual.Render(warn) + "\n"
+		statusLines++
 	}
 
 	// Footer: hotkey hints
@@ -866,6 +1494,10 @@ func (a App) View() string {
 			{Key: "↑↓", Desc: "navigate"},
 			{Key: "fn↑↓", Desc: "page"},
 			{Key: "Enter", Desc: "jobs"},
+			{Key: "Space", Desc: "mark"},
+			{Key: "n", Desc: "run"},
+			{Key: "r", Desc: "retry"},
+			{Key: "x", Desc: "cancel"},
 			{Key: "c", Desc: "commits"},
 			{Key: "/", Desc: "filter"},
 			{Key: "l", Desc: "limit"},
@@ -875,15 +1507,27 @@ func (a App) View() string {
 	case viewJobs:
 		hints = []components.HotkeyHint{
 			{Key: "↑↓", Desc: "navigate"},
-			{Key: "Enter", Desc: "log"},
+			{Key: "Enter", Desc: "log/downstream"},
+			{Key: "Space", Desc: "mark"},
+			{Key: "s", Desc: "mark stage"},
 			{Key: "r", Desc: "run/retry"},
 			{Key: "c", Desc: "cancel"},
+			{Key: "v", Desc: "graph"},
 			{Key: "Esc", Desc: "back"},
 			{Key: "q", Desc: "quit"},
 		}
+		if a.jobsView.IsGraph() {
+			hints[0] = components.HotkeyHint{Key: "←↑↓→", Desc: "navigate"}
+			hints[6] = components.HotkeyHint{Key: "v", Desc: "list"}
+		}
 	case viewLog:
 		hints = []components.HotkeyHint{
 			{Key: "↑↓", Desc: "scroll"},
+			{Key: "/", Desc: "search"},
+			{Key: "n/N", Desc: "next/prev"},
+			{Key: "[ ]", Desc: "sections"},
+			{Key: "Space", Desc: "fold"},
+			{Key: "z", Desc: "fold all"},
 			{Key: "Esc", Desc: "back"},
 			{Key: "q", Desc: "quit"},
 		}
@@ -904,16 +1548,65 @@ func (a App) View() string {
 			{Key: "Ctrl+S", Desc: "submit"},
 			{Key: "Esc", Desc: "cancel"},
 		}
+	case viewPipelineCreate:
+		hints = []components.HotkeyHint{
+			{Key: "Tab/↑↓", Desc: "navigate"},
+			{Key: "Ctrl+N", Desc: "add variable"},
+			{Key: "Ctrl+S", Desc: "run"},
+			{Key: "Esc", Desc: "cancel"},
+		}
 	case viewMRDetail:
+		if a.mrDetailView.IsInputMode() {
+			hints = []components.HotkeyHint{
+				{Key: "Enter", Desc: "send"},
+				{Key: "Ctrl+J", Desc: "newline"},
+				{Key: "Esc", Desc: "cancel"},
+			}
+			break
+		}
+		if a.mrDetailView.TreeFocused() {
+			hints = []components.HotkeyHint{
+				{Key: "↑↓", Desc: "move"},
+				{Key: "⏎", Desc: "open file"},
+				{Key: "Space", Desc: "fold/unfold"},
+				{Key: "v", Desc: "viewed"},
+				{Key: "t", Desc: "hide tree"},
+				{Key: "Esc", Desc: "back"},
+			}
+			break
+		}
 		hints = []components.HotkeyHint{
-			{Key: "↑↓", Desc: "scroll"},
+			{Key: "↑↓", Desc: "move"},
 			{Key: "Tab", Desc: "diff/comments"},
-			{Key: "r", Desc: "refresh"},
-			{Key: "a", Desc: "approve"},
-			{Key: "m", Desc: "merge"},
-			{Key: "Esc", Desc: "back"},
-			{Key: "q", Desc: "quit"},
 		}
+		if a.mrDetailView.CommentsTab() {
+			hints = append(hints,
+				components.HotkeyHint{Key: "c/⏎", Desc: "comment/reply"},
+				components.HotkeyHint{Key: "x", Desc: "resolve"},
+				components.HotkeyHint{Key: "n", Desc: "next unresolved"},
+			)
+		} else {
+			hints = append(hints,
+				components.HotkeyHint{Key: "c", Desc: "comment on line"},
+				components.HotkeyHint{Key: "t", Desc: "files"},
+				components.HotkeyHint{Key: "v", Desc: "viewed"},
+			)
+			if a.mrDetailView.SplitDiff() {
+				hints = append(hints,
+					components.HotkeyHint{Key: "←→", Desc: "side"},
+					components.HotkeyHint{Key: "s", Desc: "unified"},
+				)
+			} else {
+				hints = append(hints, components.HotkeyHint{Key: "s", Desc: "side by side"})
+			}
+		}
+		hints = append(hints,
+			components.HotkeyHint{Key: "r", Desc: "refresh"},
+			components.HotkeyHint{Key: "a", Desc: "approve"},
+			components.HotkeyHint{Key: "m", Desc: "merge"},
+			components.HotkeyHint{Key: "Esc", Desc: "back"},
+			components.HotkeyHint{Key: "q", Desc: "quit"},
+		)
 	case viewCommits:
 		hints = []components.HotkeyHint{
 			{Key: "↑↓", Desc: "navigate"},
@@ -921,6 +1614,12 @@ func (a App) View() string {
 			{Key: "q", Desc: "quit"},
 		}
 	}
+	if len(a.cfg.Contexts) > 1 && (a.currentView == viewProjects || a.currentView == viewPipelines) {
+		hints = append(hints[:len(hints)-1], components.HotkeyHint{Key: "C", Desc: "context"}, hints[len(hints)-1])
+	}
+	if d := a.refreshInterval(); d > a.cfg.RefreshInterval {
+		hints = append(hints, components.HotkeyHint{Key: "⏱", Desc: "refresh " + d.String()})
+	}
 	footer := components.NewStatusBar(hints).View()
 
 	// Content
@@ -941,19 +1640,27 @@ func (a App) View() string {
 		content = a.mergeRequestsView.View()
 	case viewMRCreate:
 		content = a.mrCreateView.View()
+	case viewPipelineCreate:
+		content = a.pipelineCreateView.View()
 	case viewMRDetail:
 		content = a.mrDetailView.View()
 	case viewCommits:
 		content = a.commitsView.View()
 	}
 	// Fixed layout: header top, content middle, footer bottom
-	// confirmDialog takes 4 lines when active, replacing footer area
+	// confirmDialog takes 4 lines when active, replacing footer area;
+	// mergeDialog takes as many as it renders
 	confirmLines := 0
+	dialog := ""
 	if a.confirmDialog != nil {
 		confirmLines = 4
+		dialog = a.confirmDialog.View()
+	} else if a.mergeDialog != nil {
+		dialog = a.mergeDialog.View()
+		confirmLines = strings.Count(dialog, "\n")
 	}
 	// headerLines=2 (tabs + breadcrumb), footerLines=1, padding=2
-	contentHeight := a.height - 5 - confirmLines
+	contentHeight := a.height - 5 - confirmLines - statusLines
 	if contentHeight < 1 {
 		contentHeight = 1
 	}
@@ -968,8 +1675,8 @@ func (a App) View() string {
 	}
 	content = strings.Join(contentLines, "\n")
 
-	if a.confirmDialog != nil {
-		return header + errStr + content + "\n" + a.confirmDialog.View() + "\n" + footer
+	if dialog != "" {
+		return header + errStr + content + "\n" + dialog + "\n" + footer
 	}
 	return header + errStr + content + "\n" + footer
 }
@@ -0,0 +1,180 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/valueobject"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/presentation/tui/components"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/presentation/tui/views"
+)
+
+// bulkRun is a confirmed action applied to several jobs or pipelines. Items
+// run one after another so progress can be shown and the shared rate
+// limiter isn't flooded.
+type bulkRun struct {
+	seq     int
+	label   string // "Retrying pipelines", for the status line
+	targets []components.ConfirmTarget
+	next    int      // index of the item in flight
+	failed  []string // "label: error" per failed item
+}
+
+func (b *bulkRun) finished() bool { return b.next >= len(b.targets) }
+
+type bulkStepMsg struct {
+	seq int
+	err error
+}
+
+var bulkLabels = map[string]string{
+	"bulk_run_jobs":         "Running jobs",
+	"bulk_cancel_jobs":      "Canceling jobs",
+	"bulk_retry_pipelines":  "Retrying pipelines",
+	"bulk_cancel_pipelines": "Canceling pipelines",
+}
+
+// confirmJobBulk asks before running or canceling the marked jobs. Jobs the
+// action doesn't apply to are skipped.
+func (a *App) confirmJobBulk(msg views.JobBulkActionMsg) {
+	var targets []components.ConfirmTarget
+	for _, j := range msg.Jobs {
+		action := ""
+		switch {
+		case msg.Action == "cancel" && j.Status.CanCancel():
+			action = "cancel"
+		case msg.Action == "run" && j.Status.IsActionable():
+			action = "retry"
+			if j.Status == valueobject.JobManual {
+				action = "play"
+			}
+		}
+		if action != "" {
+			targets = append(targets, components.ConfirmTarget{Action: action, ProjectID: j.ProjectID, ID: j.ID, Label: j.Name})
+		}
+	}
+	verb := map[string]string{"run": "Run/retry", "cancel": "Cancel"}[msg.Action]
+	a.confirmBulk(fmt.Sprintf("%s %%d of %d marked jobs?", verb, len(msg.Jobs)), "bulk_"+msg.Action+"_jobs", targets)
+}
+
+// confirmPipelineBulk asks before retrying or canceling the marked pipelines.
+func (a *App) confirmPipelineBulk(msg views.PipelineBulkActionMsg) {
+	var targets []components.ConfirmTarget
+	for _, pl := range msg.Pipelines {
+		if (msg.Action == "retry" && pl.Status.CanRetry()) || (msg.Action == "cancel" && pl.Status.CanCancel()) {
+			targets = append(targets, components.ConfirmTarget{
+				Action:    msg.Action + "_pipeline",
+				ProjectID: pl.ProjectID,
+				ID:        pl.ID,
+				Label:     fmt.Sprintf("#%d", pl.ID),
+			})
+		}
+	}
+	verb := map[string]string{"retry": "Retry", "cancel": "Cancel"}[msg.Action]
+	a.confirmBulk(fmt.Sprintf("%s %%d of %d marked pipelines?", verb, len(msg.Pipelines)), "bulk_"+msg.Action+"_pipelines", targets)
+}
+
+// confirmBulk opens the confirm dialog; question has a %d for the number of
+// items the action applies to.
+func (a *App) confirmBulk(question, action string, targets []components.ConfirmTarget) {
+	if a.offline {
+		a.err = errOffline
+		return
+	}
+	if a.bulk != nil && !a.bulk.finished() {
+		a.err = fmt.Errorf("%s is still in progress", strings.ToLower(a.bulk.label))
+		return
+	}
+	if len(targets) == 0 {
+		a.err = fmt.Errorf("the action applies to none of the marked items")
+		return
+	}
+	confirm := components.NewBulkConfirmDialog(fmt.Sprintf(question, len(targets)), action, targets)
+	a.confirmDialog = &confirm
+}
+
+func (a *App) startBulk(action string, targets []components.ConfirmTarget) tea.Cmd {
+	a.bulkSeq++
+	a.bulk = &bulkRun{seq: a.bulkSeq, label: bulkLabels[action], targets: targets}
+	return a.bulkStep()
+}
+
+func (a App) bulkStep() tea.Cmd {
+	seq := a.bulk.seq
+	t := a.bulk.targets[a.bulk.next]
+	return func() tea.Msg {
+		ctx := context.Background()
+		var err error
+		switch t.Action {
+		case "play":
+			_, err = a.jobSvc.PlayJob(ctx, t.ProjectID, t.ID)
+		case "retry":
+			_, err = a.jobSvc.RetryJob(ctx, t.ProjectID, t.ID)
+		case "cancel":
+			_, err = a.jobSvc.CancelJob(ctx, t.ProjectID, t.ID)
+		case "retry_pipeline":
+			_, err = a.pipelineSvc.RetryPipeline(ctx, t.ProjectID, t.ID)
+		case "cancel_pipeline":
+			_, err = a.pipelineSvc.CancelPipeline(ctx, t.ProjectID, t.ID)
+		}
+		return bulkStepMsg{seq: seq, err: err}
+	}
+}
+
+// onBulkStep records the outcome of one item and starts the next, or
+// clears the marks and refreshes once all are done.
+func (a *App) onBulkStep(msg bulkStepMsg) tea.Cmd {
+	if a.bulk == nil || msg.seq != a.bulk.seq {
+		return nil
+	}
+	if msg.err != nil {
+		t := a.bulk.targets[a.bulk.next]
+		a.bulk.failed = append(a.bulk.failed, fmt.Sprintf("%s: %v", t.Label, msg.err))
+	}
+	a.bulk.next++
+	if !a.bulk.finished() {
+		return a.bulkStep()
+	}
+	a.jobsView.ClearMarks()
+	a.pipelinesView.ClearMarks()
+	return a.refreshCurrentView()
+}
+
+// bulkMaxErrorLines caps how many per-item errors the status area lists.
+const bulkMaxErrorLines = 5
+
+// bulkStatus returns the progress line of a running bulk action, or the
+// summary of a finished one followed by a line per failed item.
+func (a App) bulkStatus() []string {
+	b := a.bulk
+	if b == nil {
+		return nil
+	}
+	total := len(b.targets)
+	if !b.finished() {
+		s := fmt.Sprintf("  ⟳ %s %d/%d…", b.label, b.next+1, total)
+		if len(b.failed) > 0 {
+			s += fmt.Sprintf(" (%d failed)", len(b.failed))
+		}
+		return []string{s}
+	}
+	lines := []string{fmt.Sprintf("  ✓ %s: %d of %d done", b.label, total-len(b.failed), total)}
+	for i, f := range b.failed {
+		if i == bulkMaxErrorLines {
+			lines = append(lines, fmt.Sprintf("    … and %d more", len(b.failed)-i))
+			break
+		}
+		lines = append(lines, "    ✗ "+f)
+	}
+	return lines
+}
+
+// clearBulkResult drops the summary of a finished bulk action when the
+// user moves on.
+func (a *App) clearBulkResult() {
+	if a.bulk != nil && a.bulk.finished() {
+		a.bulk = nil
+	}
+}
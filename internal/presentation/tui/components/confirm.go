@@ -8,11 +8,21 @@ import (
 	"github.com/bearlogin/gitlab-awesome-cli/internal/presentation/tui/styles"
 )
 
+// ConfirmTarget is one item of a bulk action; Action may differ per item,
+// e.g. play for manual jobs and retry for failed ones.
+type ConfirmTarget struct {
+	Action    string
+	ProjectID int
+	ID        int
+	Label     string
+}
+
 type ConfirmResult struct {
 	Confirmed bool
 	Action    string
 	JobID     int
 	ProjectID int
+	Targets   []ConfirmTarget // set for bulk actions
 }
 
 type ConfirmDialog struct {
@@ -20,6 +30,7 @@ type ConfirmDialog struct {
 	Action    string
 	JobID     int
 	ProjectID int
+	Targets   []ConfirmTarget
 	focused   int
 }
 
@@ -27,6 +38,15 @@ func NewConfirmDialog(message, action string, projectID, jobID int) ConfirmDialo
 	return ConfirmDialog{Message: message, Action: action, JobID: jobID, ProjectID: projectID}
 }
 
+// NewBulkConfirmDialog asks once for an action on several items.
+func NewBulkConfirmDialog(message, action string, targets []ConfirmTarget) ConfirmDialog {
+	return ConfirmDialog{Message: message, Action: action, Targets: targets}
+}
+
+func (d ConfirmDialog) result(confirmed bool) *ConfirmResult {
+	return &ConfirmResult{Confirmed: confirmed, Action: d.Action, JobID: d.JobID, ProjectID: d.ProjectID, Targets: d.Targets}
+}
+
 func (d ConfirmDialog) Update(msg tea.Msg) (ConfirmDialog, *ConfirmResult) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -37,11 +57,11 @@ func (d ConfirmDialog) Update(msg tea.Msg) (ConfirmDialog, *ConfirmResult) {
 		case "right", "l":
 			d.focused = 1
 		case "enter":
-			return d, &ConfirmResult{Confirmed: d.focused == 0, Action: d.Action, JobID: d.JobID, ProjectID: d.ProjectID}
+			return d, d.result(d.focused == 0)
 		case "y":
-			return d, &ConfirmResult{Confirmed: true, Action: d.Action, JobID: d.JobID, ProjectID: d.ProjectID}
+			return d, d.result(true)
 		case "n", "esc":
-			return d, &ConfirmResult{Confirmed: false, Action: d.Action, JobID: d.JobID, ProjectID: d.ProjectID}
+			return d, d.result(false)
 		}
 	}
 	return d, nil
@@ -0,0 +1,186 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/valueobject"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/presentation/tui/styles"
+)
+
+const (
+	mergeFieldAutoMerge = iota
+	mergeFieldSquash
+	mergeFieldSquashMessage
+	mergeFieldRemoveSource
+	mergeFieldMergeMessage
+	mergeFieldSHA
+	mergeFieldCount
+)
+
+type MergeResult struct {
+	Confirmed bool
+	ProjectID int
+	MRIID     int
+	Opts      entity.MergeOptions
+}
+
+// MergeDialog confirms a merge and lets the user pick how it is done. It
+// starts from what the author chose on the MR, turns on auto-merge while
+// the head pipeline is still running, and guards against pushes that land
+// after the dialog opened.
+type MergeDialog struct {
+	mr        entity.MergeRequest
+	autoMerge bool
+	squash    bool
+	remove    bool
+	guardSHA  bool
+	squashMsg string
+	mergeMsg  string
+	cursor    int
+}
+
+func NewMergeDialog(mr entity.MergeRequest) MergeDialog {
+	return MergeDialog{
+		mr:        mr,
+		autoMerge: valueobject.PipelineStatus(mr.PipelineStatus).IsActive(),
+		squash:    mr.Squash,
+		remove:    mr.RemoveSource,
+		guardSHA:  mr.SHA != "",
+	}
+}
+
+func (d MergeDialog) result(confirmed bool) *MergeResult {
+	r := &MergeResult{Confirmed: confirmed, ProjectID: d.mr.ProjectID, MRIID: d.mr.IID}
+	r.Opts = entity.MergeOptions{
+		WhenPipelineSucceeds: d.autoMerge,
+		Squash:               d.squash,
+		MergeCommitMessage:   strings.TrimSpace(d.mergeMsg),
+		RemoveSourceBranch:   d.remove,
+	}
+	if d.squash {
+		r.Opts.SquashCommitMessage = strings.TrimSpace(d.squashMsg)
+	}
+	if d.guardSHA {
+		r.Opts.SHA = d.mr.SHA
+	}
+	return r
+}
+
+// text returns the message field under the cursor, or nil on a checkbox.
+func (d *MergeDialog) text() *string {
+	switch d.cursor {
+	case mergeFieldSquashMessage:
+		return &d.squashMsg
+	case mergeFieldMergeMessage:
+		return &d.mergeMsg
+	}
+	return nil
+}
+
+func (d *MergeDialog) toggle() {
+	switch d.cursor {
+	case mergeFieldAutoMerge:
+		d.autoMerge = !d.autoMerge
+	case mergeFieldSquash:
+		d.squash = !d.squash
+	case mergeFieldRemoveSource:
+		d.remove = !d.remove
+	case mergeFieldSHA:
+		d.guardSHA = d.mr.SHA != "" && !d.guardSHA
+	}
+}
+
+func (d MergeDialog) Update(msg tea.Msg) (MergeDialog, *MergeResult) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return d, nil
+	}
+	text := d.text()
+	switch key := keyMsg.String(); key {
+	case "esc":
+		return d, d.result(false)
+	case "enter", "ctrl+s":
+		return d, d.result(true)
+	case "tab", "down":
+		d.cursor = (d.cursor + 1) % mergeFieldCount
+	case "shift+tab", "up":
+		d.cursor = (d.cursor + mergeFieldCount - 1) % mergeFieldCount
+	case "backspace":
+		if text != nil && *text != "" {
+			r := []rune(*text)
+			*text = string(r[:len(r)-1])
+		}
+	case " ":
+		if text != nil {
+			*text += " "
+		} else {
+			d.toggle()
+		}
+	default:
+		if text != nil && keyMsg.Type == tea.KeyRunes {
+			*text += string(keyMsg.Runes)
+		}
+	}
+	return d, nil
+}
+
+func (d MergeDialog) View() string {
+	check := func(on bool) string {
+		if on {
+			return "[x]"
+		}
+		return "[ ]"
+	}
+	pipeline := ""
+	if d.mr.PipelineStatus != "" {
+		st := valueobject.PipelineStatus(d.mr.PipelineStatus)
+		pipeline = styles.HelpDesc.Render(fmt.Sprintf("  (pipeline %s %s)", st.Symbol(), st))
+	}
+	sha := "Only if the source branch is still at " + shortSHA(d.mr.SHA)
+	if d.mr.SHA == "" {
+		sha = "Only if the source branch hasn't moved (head unknown)"
+	}
+	rows := [mergeFieldCount]string{
+		check(d.autoMerge) + " Merge when pipeline succeeds" + pipeline,
+		check(d.squash) + " Squash commits",
+		"    Squash message: " + d.field(mergeFieldSquashMessage, d.squashMsg, "default"),
+		check(d.remove) + " Delete source branch",
+		"    Merge message:  " + d.field(mergeFieldMergeMessage, d.mergeMsg, "default"),
+		check(d.guardSHA) + " " + sha,
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n  Merge MR !%d (%s → %s)?\n", d.mr.IID, d.mr.SourceBranch, d.mr.TargetBranch)
+	for i, row := range rows {
+		cursor := "  "
+		if i == d.cursor {
+			cursor = "▸ "
+		}
+		if i == mergeFieldSquashMessage && !d.squash {
+			row = styles.HelpDesc.Render(row)
+		}
+		b.WriteString("  " + cursor + row + "\n")
+	}
+	b.WriteString(styles.HelpDesc.Render("  ↑↓ move  Space toggle  Enter merge  Esc cancel") + "\n")
+	return b.String()
+}
+
+func (d MergeDialog) field(i int, value, placeholder string) string {
+	if i == d.cursor {
+		return value + "█"
+	}
+	if value == "" {
+		return styles.HelpDesc.Render(placeholder)
+	}
+	return value
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
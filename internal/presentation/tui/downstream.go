@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+)
+
+// pipelineFrame is a parent pipeline left by drilling into a downstream
+// one, with the job the cursor was on.
+type pipelineFrame struct {
+	pipeline entity.Pipeline
+	cursor   int
+}
+
+// openDownstream shows the jobs of the pipeline a bridge job started.
+func (a *App) openDownstream(job entity.Job) tea.Cmd {
+	d := job.Downstream
+	if d == nil {
+		a.err = fmt.Errorf("trigger job %q hasn't started a pipeline yet", job.Name)
+		return nil
+	}
+	if a.selectedPipeline == nil {
+		return nil
+	}
+	a.parentPipelines = append(a.parentPipelines, pipelineFrame{pipeline: *a.selectedPipeline, cursor: a.jobsView.Cursor})
+	path := d.ProjectPath
+	if path == "" {
+		path = a.selectedPipeline.ProjectPath
+	}
+	a.selectedPipeline = &entity.Pipeline{
+		ID:          d.ID,
+		IID:         d.IID,
+		ProjectID:   d.ProjectID,
+		ProjectPath: path,
+		Ref:         d.Ref,
+		Status:      d.Status,
+		WebURL:      d.WebURL,
+	}
+	a.showPipelineJobs(0)
+	return a.loadJobs(d.ProjectID, d.ID)
+}
+
+// openParentPipeline goes back up from a downstream pipeline. It reports
+// false at the top of the chain.
+func (a *App) openParentPipeline() (tea.Cmd, bool) {
+	n := len(a.parentPipelines)
+	if n == 0 {
+		return nil, false
+	}
+	frame := a.parentPipelines[n-1]
+	a.parentPipelines = a.parentPipelines[:n-1]
+	a.selectedPipeline = &frame.pipeline
+	a.showPipelineJobs(frame.cursor)
+	return a.loadJobs(frame.pipeline.ProjectID, frame.pipeline.ID), true
+}
+
+// showPipelineJobs switches the Jobs view to the selected pipeline while
+// its jobs load.
+func (a *App) showPipelineJobs(cursor int) {
+	a.jobsView.Jobs = nil
+	a.jobsView.Cursor = cursor
+	a.jobsView.ClearMarks()
+	a.currentView = viewJobs
+	a.breadcrumb.Parts = a.jobsBreadcrumb()
+}
+
+// jobsBreadcrumb names the selected pipeline, prefixed by its parents when
+// it was reached through trigger jobs: "group/app > #100 → #101".
+func (a App) jobsBreadcrumb() []string {
+	if a.selectedPipeline == nil {
+		return nil
+	}
+	chain := make([]entity.Pipeline, 0, len(a.parentPipelines)+1)
+	for _, f := range a.parentPipelines {
+		chain = append(chain, f.pipeline)
+	}
+	chain = append(chain, *a.selectedPipeline)
+
+	root := chain[0]
+	steps := []string{fmt.Sprintf("#%d", root.ID)}
+	for i, pl := range chain[1:] {
+		if pl.ProjectPath != chain[i].ProjectPath {
+			steps = append(steps, fmt.Sprintf("%s #%d", pl.ProjectPath, pl.ID))
+		} else {
+			steps = append(steps, fmt.Sprintf("#%d", pl.ID))
+		}
+	}
+	return []string{root.ProjectPath, strings.Join(steps, " → ")}
+}
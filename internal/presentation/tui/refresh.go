@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+)
+
+// RateMonitor reports how close the GitLab client is to its rate limit,
+// from 0 (plenty of budget) to 1 (throttled).
+type RateMonitor interface {
+	Pressure() float64
+}
+
+// Number of consecutive refreshes without changes before polling slows down.
+const (
+	idleBackoff1 = 3
+	idleBackoff2 = 6
+)
+
+// refreshInterval returns the delay until the next auto-refresh. The
+// configured interval is stretched when the rate limit budget is running
+// low or when recent refreshes brought nothing new, capped at
+// max_refresh_interval.
+func (a App) refreshInterval() time.Duration {
+	factor := 1
+	if a.rateMonitor != nil {
+		switch p := a.rateMonitor.Pressure(); {
+		case p >= 0.9:
+			factor = 4
+		case p >= 0.75:
+			factor = 2
+		}
+	}
+	switch {
+	case a.idleRefreshes >= idleBackoff2:
+		factor *= 4
+	case a.idleRefreshes >= idleBackoff1:
+		factor *= 2
+	}
+	d := a.cfg.RefreshInterval * time.Duration(factor)
+	if a.cfg.MaxRefreshInterval > 0 && d > a.cfg.MaxRefreshInterval {
+		d = a.cfg.MaxRefreshInterval
+	}
+	return d
+}
+
+// noteRefresh counts refreshes whose data didn't change since the last one.
+func (a *App) noteRefresh(fingerprint uint64) {
+	if fingerprint == a.lastFingerprint {
+		a.idleRefreshes++
+		return
+	}
+	a.lastFingerprint = fingerprint
+	a.idleRefreshes = 0
+}
+
+func pipelinesFingerprint(pipelines []entity.Pipeline) uint64 {
+	h := fnv.New64a()
+	for _, p := range pipelines {
+		fmt.Fprintf(h, "p%d:%s;", p.ID, p.Status)
+	}
+	return h.Sum64()
+}
+
+func jobsFingerprint(jobs []entity.Job) uint64 {
+	h := fnv.New64a()
+	for _, j := range jobs {
+		fmt.Fprintf(h, "j%d:%s;", j.ID, j.Status)
+	}
+	return h.Sum64()
+}
+
+func mrsFingerprint(mrs []entity.MergeRequest) uint64 {
+	h := fnv.New64a()
+	for _, mr := range mrs {
+		fmt.Fprintf(h, "m%d:%d;", mr.ID, mr.UpdatedAt.Unix())
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,143 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/infrastructure/cache"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/presentation/tui/views"
+)
+
+// errOffline is shown when a write action is attempted without a connection.
+var errOffline = errors.New("offline: actions are disabled until GitLab is reachable")
+
+// applySnapshot renders the last known state right away, marked as stale
+// until fresh data arrives. Every successful load is saved back to disk.
+func (a *App) applySnapshot(snap *cache.Snapshot) {
+	a.snapshot = *snap
+	a.stale = true
+	a.dataTime = snap.SavedAt
+
+	configured := func(path string) bool { return slices.Contains(a.cfg.Projects, path) }
+	var pls []entity.Pipeline
+	for _, p := range snap.Pipelines {
+		if configured(p.ProjectPath) {
+			pls = append(pls, p)
+		}
+	}
+	var mrs []entity.MergeRequest
+	for _, mr := range snap.MRs {
+		if configured(mr.ProjectPath) {
+			mrs = append(mrs, mr)
+		}
+	}
+	var projects []entity.Project
+	for _, p := range snap.Projects {
+		if configured(p.PathWithNS) {
+			projects = append(projects, p)
+		}
+	}
+	a.pipelinesView.Limit = a.cfg.PipelineLimit
+	a.pipelinesView.SetPipelines(pls)
+	if len(mrs) > 0 {
+		a.mergeRequestsView.SetMRs(mrs)
+	}
+	a.projectsView.Projects = projects
+}
+
+// mergeResolved updates the IDs and paths of saved projects from a
+// resolve-only load, which has no pipeline counts, keeping the counts the
+// last Projects load saved. Projects not saved yet are added without counts.
+func mergeResolved(saved, resolved []entity.Project) []entity.Project {
+	merged := slices.Clone(saved)
+	for _, r := range resolved {
+		i := slices.IndexFunc(merged, func(p entity.Project) bool { return p.PathWithNS == r.PathWithNS })
+		if i < 0 {
+			merged = append(merged, r)
+			continue
+		}
+		merged[i].ID, merged[i].Name, merged[i].WebURL = r.ID, r.Name, r.WebURL
+	}
+	return merged
+}
+
+// markFresh records that the data on screen just came from GitLab.
+func (a *App) markFresh() {
+	a.stale = false
+	a.offline = false
+	a.dataTime = time.Now()
+}
+
+// saveSnapshot writes the current snapshot in the background.
+func (a App) saveSnapshot() tea.Cmd {
+	if a.snapshots == nil {
+		return nil
+	}
+	store := a.snapshots
+	snap := a.snapshot
+	snap.SavedAt = time.Now()
+	return func() tea.Msg {
+		if err := store.Save(snap); err != nil {
+			log.Printf("[cache] snapshot save failed: %v", err)
+		}
+		return nil
+	}
+}
+
+// saveViewed stores the viewed files of an MR in the background. They are
+// kept locally, so this works offline too.
+func (a App) saveViewed(m views.MRViewedMsg) tea.Cmd {
+	if a.viewed == nil {
+		return nil
+	}
+	store := a.viewed
+	return func() tea.Msg {
+		if err := store.Save(m.ProjectID, m.MRIID, m.Version, m.Files); err != nil {
+			log.Printf("[cache] viewed files save failed: %v", err)
+		}
+		return nil
+	}
+}
+
+// goOffline switches to read-only mode if err means GitLab is unreachable
+// and there is data to fall back on. It reports whether it did.
+func (a *App) goOffline(err error) bool {
+	if a.dataTime.IsZero() || !isNetworkError(err) {
+		return false
+	}
+	if !a.offline {
+		log.Printf("[tui] offline: %v", err)
+	}
+	a.offline = true
+	return true
+}
+
+// isNetworkError reports whether err is a connection failure or timeout
+// rather than an error returned by GitLab.
+func isNetworkError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// staleStatus describes where the data on screen comes from, or is empty
+// when it is fresh.
+func (a App) staleStatus() string {
+	since := a.dataTime.Format("15:04")
+	if a.dataTime.Before(time.Now().Add(-24 * time.Hour)) {
+		since = a.dataTime.Format("Jan 2 15:04")
+	}
+	switch {
+	case a.offline:
+		return fmt.Sprintf("  ⚡ offline — read-only, showing data from %s", since)
+	case a.stale:
+		return fmt.Sprintf("  ◌ cached data from %s, refreshing...", since)
+	}
+	return ""
+}
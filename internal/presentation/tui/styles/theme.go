@@ -29,4 +29,19 @@ var (
 	DiffDel      = lipgloss.NewStyle().Foreground(lipgloss.Color("210")).Background(lipgloss.Color("52"))
 	DiffHunk     = lipgloss.NewStyle().Foreground(Cyan).Bold(true)
 	DiffFilePath = lipgloss.NewStyle().Foreground(White).Bold(true).Background(lipgloss.Color("237")).Padding(0, 1)
+	// Words that changed within a modified line
+	DiffAddWord = lipgloss.NewStyle().Foreground(lipgloss.Color("157")).Background(lipgloss.Color("28"))
+	DiffDelWord = lipgloss.NewStyle().Foreground(lipgloss.Color("224")).Background(lipgloss.Color("88"))
+
+	// Syntax highlighting in diffs; only the foreground is used so the
+	// diff background shows through
+	SyntaxKeyword = lipgloss.Color("141")
+	SyntaxType    = lipgloss.Color("81")
+	SyntaxString  = lipgloss.Color("186")
+	SyntaxComment = lipgloss.Color("244")
+	SyntaxNumber  = lipgloss.Color("215")
+
+	// Search styles
+	SearchMatch   = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(Yellow)
+	SearchCurrent = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("0")).Background(Cyan)
 )
@@ -0,0 +1,337 @@
+// Package syntax is a small highlighter for diff lines. It knows just
+// enough of each language — keywords, types, strings, comments and
+// numbers — to make code readable in a terminal without pulling in a full
+// lexer library.
+package syntax
+
+import (
+	"path"
+	"strings"
+	"unicode"
+)
+
+// Class is what a rune of a line is part of.
+type Class uint8
+
+const (
+	Plain Class = iota
+	Keyword
+	Type
+	String
+	Comment
+	Number
+)
+
+// Lang describes one language.
+type Lang struct {
+	Name         string
+	keywords     map[string]bool
+	types        map[string]bool
+	lineComments []string
+	blockComment [2]string // empty when the language has none
+	quotes       string    // characters that open a string
+	rawQuote     rune      // string delimiter that may span lines, e.g. Go's `
+}
+
+// State carries what is still open at the end of a line: a block comment or
+// a multi-line raw string. The zero value is the start of a file.
+type State struct {
+	inComment bool
+	inRaw     bool
+}
+
+func words(s string) map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
+
+var cLike = [2]string{"/*", "*/"}
+
+var langs = map[string]*Lang{
+	"go": {
+		Name: "Go",
+		keywords: words(`break case chan const continue default defer else fallthrough for func go goto
+			if import interface map package range return select struct switch type var nil true false iota`),
+		types: words(`bool byte complex64 complex128 error float32 float64 int int8 int16 int32 int64
+			rune string uint uint8 uint16 uint32 uint64 uintptr any comparable`),
+		lineComments: []string{"//"}, blockComment: cLike, quotes: `"'`, rawQuote: '`',
+	},
+	"ts": {
+		Name: "TypeScript",
+		keywords: words(`abstract as async await break case catch class const continue debugger declare default
+			delete do else enum export extends false finally for from function get if implements import in
+			instanceof interface is keyof let namespace new null of private protected public readonly return
+			set static super switch this throw true try type typeof undefined var void while with yield`),
+		types:        words(`any boolean never number object string symbol unknown bigint Promise Array Record`),
+		lineComments: []string{"//"}, blockComment: cLike, quotes: `"'`, rawQuote: '`',
+	},
+	"py": {
+		Name: "Python",
+		keywords: words(`and as assert async await break class continue def del elif else except False finally
+			for from global if import in is lambda None nonlocal not or pass raise return True try while with yield self`),
+		types:        words(`int float str bytes bool list dict set tuple object`),
+		lineComments: []string{"#"}, quotes: `"'`,
+	},
+	"rs": {
+		Name: "Rust",
+		keywords: words(`as async await break const continue crate dyn else enum extern false fn for if impl in
+			let loop match mod move mut pub ref return self Self static struct super trait true type unsafe use where while`),
+		types: words(`bool char f32 f64 i8 i16 i32 i64 i128 isize str u8 u16 u32 u64 u128 usize
+			String Vec Option Result Box`),
+		lineComments: []string{"//"}, blockComment: cLike, quotes: `"`,
+	},
+	"java": {
+		Name: "Java",
+		keywords: words(`abstract assert break case catch class const continue default do else enum extends final
+			finally for goto if implements import instanceof interface native new null package private protected
+			public return static strictfp super switch synchronized this throw throws transient true false try
+			var void volatile while fun val when object companion data sealed override open internal lateinit`),
+		types:        words(`boolean byte char double float int long short String Integer Long Boolean Object List Map Unit Any`),
+		lineComments: []string{"//"}, blockComment: cLike, quotes: `"'`,
+	},
+	"c": {
+		Name: "C",
+		keywords: words(`auto break case catch class const constexpr continue default delete do else enum explicit
+			extern false for friend goto if inline namespace new nullptr operator private protected public
+			return sizeof static struct switch template this throw true try typedef typename union using
+			virtual volatile while NULL`),
+		types: words(`bool char double float int long short signed unsigned void size_t int8_t int16_t int32_t
+			int64_t uint8_t uint16_t uint32_t uint64_t std string vector auto`),
+		lineComments: []string{"//"}, blockComment: cLike, quotes: `"'`,
+	},
+	"cs": {
+		Name: "C#",
+		keywords: words(`abstract as async await base break case catch class const continue default delegate do
+			else enum event explicit extern false finally fixed for foreach get if implicit in interface internal
+			is lock namespace new null operator out override params private protected public readonly ref return
+			sealed set sizeof static struct switch this throw true try typeof using var virtual void while`),
+		types:        words(`bool byte char decimal double float int long object sbyte short string uint ulong ushort Task`),
+		lineComments: []string{"//"}, blockComment: cLike, quotes: `"'`,
+	},
+	"rb": {
+		Name: "Ruby",
+		keywords: words(`alias and begin break case class def defined? do else elsif end ensure false for if in
+			module next nil not or redo rescue retry return self super then true undef unless until when while yield`),
+		lineComments: []string{"#"}, quotes: `"'`,
+	},
+	"php": {
+		Name: "PHP",
+		keywords: words(`abstract and array as break case catch class clone const continue declare default do echo
+			else elseif empty extends false final finally fn for foreach function global if implements include
+			instanceof interface isset match namespace new null or private protected public readonly require
+			return static switch this throw trait true try use var while yield`),
+		types:        words(`int float string bool array object mixed void`),
+		lineComments: []string{"//", "#"}, blockComment: cLike, quotes: `"'`,
+	},
+	"swift": {
+		Name: "Swift",
+		keywords: words(`as associatedtype break case catch class continue default defer do else enum extension
+			false fileprivate for func guard if import in init inout internal is let nil open operator private
+			protocol public repeat rethrows return self Self static struct subscript super switch throw throws
+			true try typealias var where while async await`),
+		types:        words(`Bool Character Double Float Int String Array Dictionary Set Optional Any`),
+		lineComments: []string{"//"}, blockComment: cLike, quotes: `"`,
+	},
+	"sh": {
+		Name: "Shell",
+		keywords: words(`case do done elif else esac fi for function if in local return select then until while
+			export readonly echo exit set unset source`),
+		lineComments: []string{"#"}, quotes: `"'`,
+	},
+	"yaml": {
+		Name:         "YAML",
+		keywords:     words(`true false null yes no on off`),
+		lineComments: []string{"#"}, quotes: `"'`,
+	},
+	"json": {
+		Name:     "JSON",
+		keywords: words(`true false null`),
+		quotes:   `"`,
+	},
+	"sql": {
+		Name: "SQL",
+		keywords: words(`select from where and or not insert into values update set delete create table alter
+			drop index join left right inner outer on group by order having limit offset as distinct null is
+			primary key foreign references default union all case when then else end begin commit rollback
+			SELECT FROM WHERE AND OR NOT INSERT INTO VALUES UPDATE SET DELETE CREATE TABLE ALTER DROP INDEX
+			JOIN LEFT RIGHT INNER OUTER ON GROUP BY ORDER HAVING LIMIT OFFSET AS DISTINCT NULL IS PRIMARY KEY
+			FOREIGN REFERENCES DEFAULT UNION ALL CASE WHEN THEN ELSE END BEGIN COMMIT ROLLBACK`),
+		types:        words(`int integer bigint text varchar boolean timestamp date INT INTEGER BIGINT TEXT VARCHAR BOOLEAN TIMESTAMP DATE`),
+		lineComments: []string{"--"}, blockComment: cLike, quotes: `'"`,
+	},
+}
+
+var extensions = map[string]string{
+	".go": "go",
+	".ts": "ts", ".tsx": "ts", ".js": "ts", ".jsx": "ts", ".mjs": "ts", ".cjs": "ts", ".vue": "ts",
+	".py": "py",
+	".rs": "rs",
+	".java": "java", ".kt": "java", ".kts": "java", ".scala": "java", ".groovy": "java", ".gradle": "java",
+	".c": "c", ".h": "c", ".cc": "c", ".cpp": "c", ".cxx": "c", ".hpp": "c", ".m": "c",
+	".cs": "cs",
+	".rb": "rb", ".rake": "rb",
+	".php": "php",
+	".swift": "swift",
+	".sh": "sh", ".bash": "sh", ".zsh": "sh",
+	".yml": "yaml", ".yaml": "yaml", ".toml": "yaml",
+	".json": "json",
+	".sql": "sql",
+}
+
+var filenames = map[string]string{
+	"Dockerfile": "sh", "Makefile": "sh", "Gemfile": "rb", "Rakefile": "rb", "Jenkinsfile": "java",
+}
+
+// ForPath picks the language from a file name, or returns nil for files it
+// doesn't know, which are shown without highlighting.
+func ForPath(p string) *Lang {
+	base := path.Base(p)
+	if key, ok := filenames[base]; ok {
+		return langs[key]
+	}
+	return langs[extensions[strings.ToLower(path.Ext(base))]]
+}
+
+// Classify returns the class of every rune of line. st carries block
+// comments and raw strings from the previous line and is updated for the
+// next one.
+func (l *Lang) Classify(line string, st *State) []Class {
+	r := []rune(line)
+	classes := make([]Class, len(r))
+	if l == nil {
+		return classes
+	}
+	i := 0
+	fill := func(from, to int, c Class) {
+		for ; from < to && from < len(r); from++ {
+			classes[from] = c
+		}
+	}
+	if st.inComment {
+		end := index(r, 0, l.blockComment[1])
+		if end < 0 {
+			fill(0, len(r), Comment)
+			return classes
+		}
+		i = end + len([]rune(l.blockComment[1]))
+		fill(0, i, Comment)
+		st.inComment = false
+	}
+	if st.inRaw {
+		end := indexRune(r, 0, l.rawQuote)
+		if end < 0 {
+			fill(0, len(r), String)
+			return classes
+		}
+		i = end + 1
+		fill(0, i, String)
+		st.inRaw = false
+	}
+	for i < len(r) {
+		c := r[i]
+		switch {
+		case l.startsLineComment(r, i):
+			fill(i, len(r), Comment)
+			return classes
+		case l.blockComment[0] != "" && hasPrefix(r, i, l.blockComment[0]):
+			end := index(r, i+len([]rune(l.blockComment[0])), l.blockComment[1])
+			if end < 0 {
+				fill(i, len(r), Comment)
+				st.inComment = true
+				return classes
+			}
+			j := end + len([]rune(l.blockComment[1]))
+			fill(i, j, Comment)
+			i = j
+		case l.rawQuote != 0 && c == l.rawQuote:
+			end := indexRune(r, i+1, l.rawQuote)
+			if end < 0 {
+				fill(i, len(r), String)
+				st.inRaw = true
+				return classes
+			}
+			fill(i, end+1, String)
+			i = end + 1
+		case strings.ContainsRune(l.quotes, c):
+			j := i + 1
+			for j < len(r) && r[j] != c {
+				if r[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(r))
+			fill(i, j, String)
+			i = j
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(r) && (unicode.IsDigit(r[j]) || unicode.IsLetter(r[j]) || r[j] == '.' || r[j] == '_') {
+				j++
+			}
+			fill(i, j, Number)
+			i = j
+		case isIdent(c):
+			j := i
+			for j < len(r) && (isIdent(r[j]) || unicode.IsDigit(r[j]) || r[j] == '?') {
+				j++
+			}
+			word := string(r[i:j])
+			if !l.keywords[word] && strings.HasSuffix(word, "?") {
+				j--
+				word = word[:len(word)-1]
+			}
+			switch {
+			case l.keywords[word]:
+				fill(i, j, Keyword)
+			case l.types[word]:
+				fill(i, j, Type)
+			}
+			i = j
+		default:
+			i++
+		}
+	}
+	return classes
+}
+
+func (l *Lang) startsLineComment(r []rune, i int) bool {
+	for _, p := range l.lineComments {
+		if hasPrefix(r, i, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func isIdent(c rune) bool { return unicode.IsLetter(c) || c == '_' || c == '$' }
+
+func hasPrefix(r []rune, i int, p string) bool {
+	for _, c := range p {
+		if i >= len(r) || r[i] != c {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+func index(r []rune, from int, p string) int {
+	for i := from; i < len(r); i++ {
+		if hasPrefix(r, i, p) {
+			return i
+		}
+	}
+	return -1
+}
+
+func indexRune(r []rune, from int, c rune) int {
+	for i := from; i < len(r); i++ {
+		if r[i] == c {
+			return i
+		}
+	}
+	return -1
+}
@@ -9,15 +9,39 @@ import (
 )
 
 type CommitsView struct {
-	Commits []entity.Commit
-	Cursor  int
-	offset  int
-	height  int
-	Ref     string
+	Commits     []entity.Commit
+	Cursor      int
+	offset      int
+	height      int
+	ProjectID   int
+	Ref         string
+	HasMore     bool // the ref has more commits than were fetched
+	loadingMore bool
 }
 
+// CommitsLoadMoreMsg asks for more commits when the cursor reaches the end
+// of the list.
+type CommitsLoadMoreMsg struct{}
+
 func NewCommitsView() CommitsView { return CommitsView{height: 20} }
 
+func (v *CommitsView) SetCommits(commits []entity.Commit, more bool) {
+	v.Commits = commits
+	v.HasMore = more
+	v.loadingMore = false
+}
+
+// LoadMoreFailed lets the user ask again after a failed "load more".
+func (v *CommitsView) LoadMoreFailed() { v.loadingMore = false }
+
+func (v *CommitsView) loadMore() tea.Cmd {
+	if !v.HasMore || v.loadingMore || v.Cursor < len(v.Commits)-1 {
+		return nil
+	}
+	v.loadingMore = true
+	return func() tea.Msg { return CommitsLoadMoreMsg{} }
+}
+
 func (v *CommitsView) SetHeight(h int) {
 	v.height = h - 6
 	if v.height < 5 {
@@ -48,12 +72,14 @@ func (v CommitsView) Update(msg tea.Msg) (CommitsView, tea.Cmd) {
 				v.Cursor++
 				v.ensureVisible()
 			}
+			return v, v.loadMore()
 		case "home", "g":
 			v.Cursor = 0
 			v.ensureVisible()
 		case "end", "G":
 			v.Cursor = max(0, len(v.Commits)-1)
 			v.ensureVisible()
+			return v, v.loadMore()
 		case "pgup", "ctrl+u":
 			v.Cursor -= v.height / 2
 			if v.Cursor < 0 {
@@ -66,6 +92,7 @@ func (v CommitsView) Update(msg tea.Msg) (CommitsView, tea.Cmd) {
 				v.Cursor = max(0, len(v.Commits)-1)
 			}
 			v.ensureVisible()
+			return v, v.loadMore()
 		}
 	}
 	return v, nil
@@ -109,7 +136,12 @@ func (v CommitsView) View() string {
 	if total == 0 {
 		s += styles.HelpDesc.Render("  Loading commits...") + "\n"
 	}
-	if total > v.height {
+	switch {
+	case v.loadingMore:
+		s += styles.HelpDesc.Render(fmt.Sprintf("\n  %d/%d  loading more...", v.Cursor+1, total)) + "\n"
+	case v.HasMore:
+		s += styles.HelpDesc.Render(fmt.Sprintf("\n  %d/%d+  ↓ more", v.Cursor+1, total)) + "\n"
+	case total > v.height:
 		s += styles.HelpDesc.Render(fmt.Sprintf("\n  %d/%d", v.Cursor+1, total)) + "\n"
 	}
 	return s
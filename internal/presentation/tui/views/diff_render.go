@@ -0,0 +1,396 @@
+package views
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/presentation/tui/styles"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/presentation/tui/syntax"
+)
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// maxWordDiff bounds the token comparison of a changed line pair; longer
+// pairs are shown without word highlighting.
+const maxWordDiff = 10000
+
+// diffRow is one line of the Diffs tab. Code lines carry their line
+// numbers so a comment can be anchored to them, and are classified for
+// highlighting up front because rendering happens on every cursor move.
+type diffRow struct {
+	file    int // index into the diffs
+	kind    byte
+	text    string
+	oldLine int
+	newLine int
+	code    []rune         // text after the +/-/space prefix, tabs expanded
+	classes []syntax.Class // one per rune of code
+	changed []bool         // runes that differ from the paired line, nil if unpaired
+}
+
+// Row kinds besides the '+', '-' and ' ' of code lines.
+const (
+	rowBlank = 0
+	rowFile  = 'f'
+	rowHunk  = '@'
+)
+
+func (r diffRow) commentable() bool {
+	return r.kind == '+' || r.kind == '-' || r.kind == ' '
+}
+
+// fileDiffRows turns the diff of file i into rows, numbering lines from
+// the hunk headers.
+func fileDiffRows(i int, d entity.MRDiff) []diffRow {
+	rows := []diffRow{{file: i, kind: rowFile, text: diffLabel(d)}}
+	lang := syntax.ForPath(d.NewPath)
+	var oldState, newState syntax.State
+	var oldLine, newLine int
+	for _, line := range strings.Split(d.Diff, "\n") {
+		row := diffRow{file: i, text: line}
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "@@"):
+			row.kind = rowHunk
+			if m := hunkHeader.FindStringSubmatch(line); m != nil {
+				oldLine, _ = strconv.Atoi(m[1])
+				newLine, _ = strconv.Atoi(m[2])
+			}
+			oldState, newState = syntax.State{}, syntax.State{}
+		case line[0] == '+':
+			row.kind, row.newLine = '+', newLine
+			newLine++
+			row.code = []rune(expandTabs(line[1:]))
+			row.classes = lang.Classify(string(row.code), &newState)
+		case line[0] == '-':
+			row.kind, row.oldLine = '-', oldLine
+			oldLine++
+			row.code = []rune(expandTabs(line[1:]))
+			row.classes = lang.Classify(string(row.code), &oldState)
+		case line[0] == ' ':
+			row.kind, row.oldLine, row.newLine = ' ', oldLine, newLine
+			oldLine++
+			newLine++
+			row.code = []rune(expandTabs(line[1:]))
+			row.classes = lang.Classify(string(row.code), &newState)
+			oldState = newState
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows, diffRow{file: i})
+	markWordChanges(rows)
+	return rows
+}
+
+func expandTabs(s string) string { return strings.ReplaceAll(s, "\t", "    ") }
+
+// markWordChanges pairs each block of removed lines with the block of added
+// lines right after it, line by line, and marks the words that differ.
+func markWordChanges(rows []diffRow) {
+	for i := 0; i < len(rows); {
+		if rows[i].kind != '-' {
+			i++
+			continue
+		}
+		dels := i
+		for i < len(rows) && rows[i].kind == '-' {
+			i++
+		}
+		adds := i
+		for i < len(rows) && rows[i].kind == '+' {
+			i++
+		}
+		for k := 0; dels+k < adds && adds+k < i; k++ {
+			rows[dels+k].changed, rows[adds+k].changed = wordDiff(rows[dels+k].code, rows[adds+k].code)
+		}
+	}
+}
+
+// wordDiff marks the runes of a and b outside their longest common
+// sequence of words. It returns nils when the lines have too little in
+// common for the highlight to help.
+func wordDiff(a, b []rune) (ca, cb []bool) {
+	ta, tb := tokenize(a), tokenize(b)
+	if len(ta)*len(tb) > maxWordDiff {
+		return nil, nil
+	}
+	// lcs[i][j] is the common length of ta[i:] and tb[j:]
+	lcs := make([][]int, len(ta)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(tb)+1)
+	}
+	for i := len(ta) - 1; i >= 0; i-- {
+		for j := len(tb) - 1; j >= 0; j-- {
+			if string(a[ta[i][0]:ta[i][1]]) == string(b[tb[j][0]:tb[j][1]]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	ca, cb = make([]bool, len(a)), make([]bool, len(b))
+	for i := range ca {
+		ca[i] = true
+	}
+	for i := range cb {
+		cb[i] = true
+	}
+	same := 0
+	for i, j := 0, 0; i < len(ta) && j < len(tb); {
+		switch {
+		case string(a[ta[i][0]:ta[i][1]]) == string(b[tb[j][0]:tb[j][1]]):
+			for k := ta[i][0]; k < ta[i][1]; k++ {
+				ca[k] = false
+				if a[k] != ' ' {
+					same++
+				}
+			}
+			for k := tb[j][0]; k < tb[j][1]; k++ {
+				cb[k] = false
+			}
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	if 3*same < min(nonSpace(a), nonSpace(b)) {
+		return nil, nil
+	}
+	return ca, cb
+}
+
+// tokenize splits a line into words, runs of spaces and single symbols,
+// as [start, end) rune offsets.
+func tokenize(r []rune) [][2]int {
+	var tokens [][2]int
+	for i := 0; i < len(r); {
+		j := i + 1
+		switch {
+		case isWordRune(r[i]):
+			for j < len(r) && isWordRune(r[j]) {
+				j++
+			}
+		case r[i] == ' ':
+			for j < len(r) && r[j] == ' ' {
+				j++
+			}
+		}
+		tokens = append(tokens, [2]int{i, j})
+		i = j
+	}
+	return tokens
+}
+
+func isWordRune(c rune) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c > 0x7f
+}
+
+func nonSpace(r []rune) int {
+	n := 0
+	for _, c := range r {
+		if c != ' ' {
+			n++
+		}
+	}
+	return n
+}
+
+func diffLabel(d entity.MRDiff) string {
+	switch {
+	case d.NewFile:
+		return d.NewPath + " (new)"
+	case d.DeletedFile:
+		return d.NewPath + " (deleted)"
+	case d.RenamedFile:
+		return fmt.Sprintf("%s → %s (renamed)", d.OldPath, d.NewPath)
+	}
+	return d.NewPath
+}
+
+func lineNo(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func codeStyle(kind byte, changed bool) lipgloss.Style {
+	switch {
+	case kind == '+' && changed:
+		return styles.DiffAddWord
+	case kind == '+':
+		return styles.DiffAdd
+	case kind == '-' && changed:
+		return styles.DiffDelWord
+	case kind == '-':
+		return styles.DiffDel
+	}
+	return lipgloss.NewStyle()
+}
+
+func classColor(c syntax.Class) (lipgloss.Color, bool) {
+	switch c {
+	case syntax.Keyword:
+		return styles.SyntaxKeyword, true
+	case syntax.Type:
+		return styles.SyntaxType, true
+	case syntax.String:
+		return styles.SyntaxString, true
+	case syntax.Comment:
+		return styles.SyntaxComment, true
+	case syntax.Number:
+		return styles.SyntaxNumber, true
+	}
+	return "", false
+}
+
+// renderCode colours the code of a row by syntax class over the diff
+// background. A positive width truncates and pads it to that many cells.
+func renderCode(row diffRow, width int) string {
+	code := row.code
+	used := 0
+	if width > 0 {
+		for i, c := range code {
+			w := lipgloss.Width(string(c))
+			if used+w > width {
+				code = code[:i]
+				break
+			}
+			used += w
+		}
+	}
+	var b strings.Builder
+	for i := 0; i < len(code); {
+		class, changed := row.classes[i], row.changed != nil && row.changed[i]
+		j := i + 1
+		for j < len(code) && row.classes[j] == class && (row.changed != nil && row.changed[j]) == changed {
+			j++
+		}
+		st := codeStyle(row.kind, changed)
+		if color, ok := classColor(class); ok {
+			st = st.Foreground(color)
+		}
+		b.WriteString(st.Render(string(code[i:j])))
+		i = j
+	}
+	if width > used {
+		b.WriteString(codeStyle(row.kind, false).Render(strings.Repeat(" ", width-used)))
+	}
+	return b.String()
+}
+
+func renderDiffRow(row diffRow, selected bool) string {
+	switch {
+	case row.kind == rowFile:
+		return styles.DiffFilePath.Render(row.text)
+	case row.kind == rowBlank && row.text == "":
+		return ""
+	}
+	gutter := fmt.Sprintf("%5s %5s ", lineNo(row.oldLine), lineNo(row.newLine))
+	if selected {
+		return styles.Selected.Render(gutter + row.text)
+	}
+	gutter = styles.HelpDesc.Render(gutter)
+	switch row.kind {
+	case rowHunk:
+		return gutter + styles.DiffHunk.Render(row.text)
+	case rowBlank:
+		return gutter + styles.HelpDesc.Render(row.text)
+	}
+	return gutter + codeStyle(row.kind, false).Render(string(row.kind)) + renderCode(row, 0)
+}
+
+// splitRow is one line of the side-by-side view: indexes into the rows for
+// each side, -1 for an empty side. Headers, hunks and context lines use
+// the same row on both sides; full marks rows drawn across both.
+type splitRow struct {
+	left, right int
+	full        bool
+}
+
+// splitLayout pairs removed and added lines side by side. index maps each
+// row to the split row that shows it.
+func splitLayout(rows []diffRow) (split []splitRow, index []int) {
+	index = make([]int, len(rows))
+	for i := 0; i < len(rows); {
+		switch rows[i].kind {
+		case '-', '+':
+			dels := i
+			for i < len(rows) && rows[i].kind == '-' {
+				i++
+			}
+			adds := i
+			for i < len(rows) && rows[i].kind == '+' {
+				i++
+			}
+			nDel, nAdd := adds-dels, i-adds
+			for k := 0; k < max(nDel, nAdd); k++ {
+				sr := splitRow{left: -1, right: -1}
+				if k < nDel {
+					sr.left = dels + k
+					index[dels+k] = len(split)
+				}
+				if k < nAdd {
+					sr.right = adds + k
+					index[adds+k] = len(split)
+				}
+				split = append(split, sr)
+			}
+		default:
+			index[i] = len(split)
+			split = append(split, splitRow{left: i, right: i, full: rows[i].kind != ' '})
+			i++
+		}
+	}
+	return split, index
+}
+
+// renderSplitRow draws one line of the side-by-side view, each side
+// sideWidth cells wide.
+func renderSplitRow(rows []diffRow, sr splitRow, sideWidth, selected int) string {
+	if sr.full {
+		row := rows[sr.left]
+		switch {
+		case row.kind == rowFile:
+			return styles.DiffFilePath.Render(row.text)
+		case row.kind == rowHunk && sr.left == selected:
+			return styles.Selected.Render(row.text)
+		case row.kind == rowHunk:
+			return styles.DiffHunk.Render(row.text)
+		}
+		return styles.HelpDesc.Render(row.text)
+	}
+	left := renderSide(rows, sr.left, false, sideWidth, sr.left == selected)
+	right := renderSide(rows, sr.right, true, sideWidth, sr.right == selected)
+	return left + styles.HelpDesc.Render("│") + right
+}
+
+func renderSide(rows []diffRow, i int, newSide bool, width int, selected bool) string {
+	if i < 0 {
+		return strings.Repeat(" ", width)
+	}
+	row := rows[i]
+	n := row.oldLine
+	if newSide {
+		n = row.newLine
+	}
+	gutter := fmt.Sprintf("%5s ", lineNo(n))
+	codeWidth := max(1, width-len(gutter)-1)
+	if selected {
+		plain := row
+		plain.kind, plain.classes, plain.changed = ' ', make([]syntax.Class, len(row.code)), nil
+		return styles.Selected.Render(gutter + string(row.kind) + stripANSI(renderCode(plain, codeWidth)))
+	}
+	return styles.HelpDesc.Render(gutter) + codeStyle(row.kind, false).Render(string(row.kind)) + renderCode(row, codeWidth)
+}
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func stripANSI(s string) string { return ansiEscape.ReplaceAllString(s, "") }
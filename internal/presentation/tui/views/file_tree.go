@@ -0,0 +1,247 @@
+package views
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/presentation/tui/styles"
+)
+
+// treeMinWidth is the narrowest terminal the file tree is shown on.
+const treeMinWidth = 90
+
+// fileNode is a directory or a changed file in the file tree of the Diffs
+// tab. A directory whose only child is another directory is merged with
+// it, as GitLab does, so deep paths don't eat the sidebar.
+type fileNode struct {
+	name     string
+	file     int // index into the diffs, -1 for a directory
+	children []*fileNode
+	folded   bool // directory closed in the tree
+}
+
+func (n *fileNode) dir() bool { return n.file < 0 }
+
+// fileEntry is a line of the tree as shown, with folded directories
+// skipped.
+type fileEntry struct {
+	node  *fileNode
+	depth int
+}
+
+func buildFileTree(diffs []entity.MRDiff) []*fileNode {
+	root := &fileNode{file: -1}
+	for i, d := range diffs {
+		parts := strings.Split(d.NewPath, "/")
+		n := root
+		for _, dir := range parts[:len(parts)-1] {
+			n = n.subdir(dir)
+		}
+		n.children = append(n.children, &fileNode{name: parts[len(parts)-1], file: i})
+	}
+	root.tidy()
+	return root.children
+}
+
+func (n *fileNode) subdir(name string) *fileNode {
+	for _, c := range n.children {
+		if c.dir() && c.name == name {
+			return c
+		}
+	}
+	c := &fileNode{name: name, file: -1}
+	n.children = append(n.children, c)
+	return c
+}
+
+// tidy merges single-directory chains and sorts directories before files.
+func (n *fileNode) tidy() {
+	for _, c := range n.children {
+		for c.dir() && len(c.children) == 1 && c.children[0].dir() {
+			c.name += "/" + c.children[0].name
+			c.children = c.children[0].children
+		}
+		c.tidy()
+	}
+	sort.SliceStable(n.children, func(i, j int) bool {
+		a, b := n.children[i], n.children[j]
+		if a.dir() != b.dir() {
+			return a.dir()
+		}
+		return a.name < b.name
+	})
+}
+
+func treeEntries(nodes []*fileNode, depth int, out []fileEntry) []fileEntry {
+	for _, n := range nodes {
+		out = append(out, fileEntry{node: n, depth: depth})
+		if n.dir() && !n.folded {
+			out = treeEntries(n.children, depth+1, out)
+		}
+	}
+	return out
+}
+
+func (v MRDetailView) treeVisible() bool {
+	return v.tab == mrTabDiffs && !v.treeHidden && len(v.diffs) > 1 && v.width >= treeMinWidth
+}
+
+// treeWidth is the width of the sidebar including its border.
+func (v MRDetailView) treeWidth() int { return max(24, min(40, v.width/4)) }
+
+// fileEntryIndex returns the tree line of file i, or -1 while it is inside
+// a folded directory.
+func fileEntryIndex(entries []fileEntry, i int) int {
+	for k, e := range entries {
+		if e.node.file == i {
+			return k
+		}
+	}
+	return -1
+}
+
+// currentFile is the file the diff cursor is in.
+func (v MRDetailView) currentFile() int {
+	if v.diffCursor < len(v.rows) {
+		return v.rows[v.diffCursor].file
+	}
+	return -1
+}
+
+// moveTree handles a key while the file tree has focus and reports whether
+// it used it.
+func (v *MRDetailView) moveTree(key string) bool {
+	entries := treeEntries(v.tree, 0, nil)
+	if len(entries) == 0 {
+		return false
+	}
+	v.treeCursor = max(0, min(v.treeCursor, len(entries)-1))
+	e := entries[v.treeCursor]
+	page := max(1, v.viewport.Height-1)
+	switch key {
+	case "up", "k":
+		v.treeCursor = max(0, v.treeCursor-1)
+	case "down", "j":
+		v.treeCursor = min(len(entries)-1, v.treeCursor+1)
+	case "pgup":
+		v.treeCursor = max(0, v.treeCursor-page)
+	case "pgdown":
+		v.treeCursor = min(len(entries)-1, v.treeCursor+page)
+	case "home", "g":
+		v.treeCursor = 0
+	case "end", "G":
+		v.treeCursor = len(entries) - 1
+	case "left", "h":
+		if e.node.dir() && !e.node.folded {
+			e.node.folded = true
+			break
+		}
+		for k := v.treeCursor - 1; k >= 0; k-- {
+			if entries[k].depth < e.depth {
+				v.treeCursor = k
+				break
+			}
+		}
+	case "right", "l":
+		if e.node.dir() {
+			e.node.folded = false
+		}
+	case " ":
+		if e.node.dir() {
+			e.node.folded = !e.node.folded
+		} else if v.foldReason(e.node.file) != "" {
+			v.opened[e.node.file] = !v.opened[e.node.file]
+			v.layoutRows()
+		}
+	case "enter":
+		if e.node.dir() {
+			e.node.folded = !e.node.folded
+			break
+		}
+		if v.foldReason(e.node.file) != "" && !v.opened[e.node.file] {
+			v.opened[e.node.file] = true
+			v.layoutRows()
+		}
+		v.treeFocus = false
+		v.jumpToFile(e.node.file)
+		return true
+	default:
+		return false
+	}
+	v.rebuildContent()
+	return true
+}
+
+// jumpToFile puts the cursor on the first line of file i and scrolls its
+// header to the top.
+func (v *MRDetailView) jumpToFile(i int) {
+	if i >= len(v.fileStart) {
+		return
+	}
+	v.diffCursor = v.nextCodeRow(v.fileStart[i], 1)
+	v.rebuildContent()
+	if v.ready {
+		v.viewport.SetYOffset(v.bodyStart + v.rowLine(v.fileStart[i]))
+	}
+}
+
+// treeView renders the sidebar, height lines tall. It scrolls to keep the
+// tree cursor, or the current file when the diff has focus, in view.
+func (v MRDetailView) treeView(height int) string {
+	width := v.treeWidth() - 1
+	entries := treeEntries(v.tree, 0, nil)
+	target := v.treeCursor
+	if !v.treeFocus {
+		target = fileEntryIndex(entries, v.currentFile())
+	}
+	offset := 0
+	if len(entries) > height {
+		offset = max(0, min(target-height/2, len(entries)-height))
+	}
+	lines := make([]string, height)
+	for k := range lines {
+		line := strings.Repeat(" ", width)
+		if i := offset + k; i < len(entries) {
+			line = v.treeLine(entries[i], width, i == target)
+		}
+		lines[k] = line + styles.HelpDesc.Render("│")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (v MRDetailView) treeLine(e fileEntry, width int, selected bool) string {
+	indent := strings.Repeat(" ", 2*e.depth)
+	n := e.node
+	if n.dir() {
+		icon := "▾ "
+		if n.folded {
+			icon = "▸ "
+		}
+		text := fit(indent+icon+n.name+"/", width)
+		if selected && v.treeFocus {
+			return styles.Selected.Render(text)
+		}
+		return styles.HelpDesc.Render(text)
+	}
+
+	icon := "  "
+	if v.isViewed(v.diffs[n.file].NewPath) {
+		icon = "✓ "
+	}
+	add, del := fmt.Sprintf("+%d", v.stats[n.file][0]), fmt.Sprintf("-%d", v.stats[n.file][1])
+	counts := len(add) + len(del) + 2
+	name := fit(n.name, max(1, width-len(indent)-2-counts))
+	if selected && v.treeFocus {
+		return styles.Selected.Render(indent + icon + name + " " + add + " " + del)
+	}
+	switch {
+	case selected:
+		name = styles.HelpKey.Render(name)
+	case v.folded(n.file) != "":
+		name = styles.HelpDesc.Render(name)
+	}
+	return indent + styles.StatusSuccess.Render(icon) + name + " " +
+		styles.StatusSuccess.Render(add) + " " + styles.StatusFailed.Render(del)
+}
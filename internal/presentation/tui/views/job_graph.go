@@ -0,0 +1,433 @@
+package views
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/valueobject"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/presentation/tui/styles"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Graph cell sizes in terminal columns.
+const (
+	graphCellWidth   = 24 // job box including its brackets
+	graphGutterWidth = 5  // space between stage columns where edges are drawn
+)
+
+// JobNeedsRequestMsg asks the app to load the needs: edges of the current
+// pipeline for the graph.
+type JobNeedsRequestMsg struct{}
+
+// graphLayout places jobs in stage columns, in the order GitLab runs them.
+type graphLayout struct {
+	stages  []string
+	columns [][]int // indexes into JobsView.Jobs
+}
+
+func (v JobsView) layout() graphLayout {
+	first := make(map[string]int) // lowest job ID per stage
+	byStage := make(map[string][]int)
+	for i, j := range v.Jobs {
+		if id, ok := first[j.Stage]; !ok || j.ID < id {
+			first[j.Stage] = j.ID
+		}
+		byStage[j.Stage] = append(byStage[j.Stage], i)
+	}
+	var l graphLayout
+	for stage := range byStage {
+		l.stages = append(l.stages, stage)
+	}
+	sort.Slice(l.stages, func(a, b int) bool { return first[l.stages[a]] < first[l.stages[b]] })
+	for _, stage := range l.stages {
+		col := byStage[stage]
+		sort.Slice(col, func(a, b int) bool { return v.Jobs[col[a]].Name < v.Jobs[col[b]].Name })
+		l.columns = append(l.columns, col)
+	}
+	return l
+}
+
+// find returns the column and row of job index i.
+func (l graphLayout) find(i int) (col, row int) {
+	for c, jobs := range l.columns {
+		for r, idx := range jobs {
+			if idx == i {
+				return c, r
+			}
+		}
+	}
+	return 0, 0
+}
+
+// IsGraph reports whether jobs are drawn as a stage graph.
+func (v JobsView) IsGraph() bool { return v.graph }
+
+// WantsNeeds reports whether the graph is shown without needs loaded for
+// the pipeline.
+func (v JobsView) WantsNeeds(pipelineID int) bool {
+	return v.graph && v.needsFor != pipelineID
+}
+
+// SetNeeds stores the needs: edges of a pipeline, keyed by job name.
+func (v *JobsView) SetNeeds(pipelineID int, needs map[string][]string) {
+	v.needsFor = pipelineID
+	v.needs = needs
+}
+
+func (v *JobsView) SetWidth(w int) { v.width = w }
+
+func (v *JobsView) toggleGraph() tea.Cmd {
+	v.graph = !v.graph
+	v.offset = 0
+	v.ensureVisible()
+	if !v.graph || len(v.Jobs) == 0 || !v.WantsNeeds(v.Jobs[0].PipelineID) {
+		return nil
+	}
+	return func() tea.Msg { return JobNeedsRequestMsg{} }
+}
+
+// graphKey moves the cursor through the graph: ↑↓ within a stage, ←→
+// between stages. It reports false for keys the list handles the same way.
+func (v *JobsView) graphKey(key string) bool {
+	if len(v.Jobs) == 0 {
+		return false
+	}
+	l := v.layout()
+	col, row := l.find(v.Cursor)
+	switch key {
+	case "up", "k":
+		row--
+	case "down", "j", " ":
+		if key == " " {
+			job := v.Jobs[v.Cursor]
+			v.markWhere(func(j entity.Job) bool { return j.ID == job.ID })
+		}
+		row++
+	case "left", "h":
+		col--
+	case "right", "l":
+		col++
+	case "home", "g":
+		row = 0
+	case "end", "G":
+		row = len(l.columns[col]) - 1
+	case "pgup", "ctrl+u", "pgdown", "ctrl+d":
+		return true
+	default:
+		return false
+	}
+	col = max(0, min(col, len(l.columns)-1))
+	row = max(0, min(row, len(l.columns[col])-1))
+	v.Cursor = l.columns[col][row]
+	return true
+}
+
+// jobIndexes resolves a needs: entry to jobs. Parallel and matrix jobs are
+// listed by their base name, e.g. "rspec" for "rspec 1/3".
+func (v JobsView) jobIndexes(name string) []int {
+	var idx []int
+	for i, j := range v.Jobs {
+		if j.Name == name || strings.HasPrefix(j.Name, name+" ") || strings.HasPrefix(j.Name, name+":") {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// related returns, for the job at index i, which jobs it needs and which
+// jobs need it.
+func (v JobsView) related(i int) (needs, neededBy map[int]bool) {
+	needs, neededBy = make(map[int]bool), make(map[int]bool)
+	for _, n := range v.needs[v.Jobs[i].Name] {
+		for _, idx := range v.jobIndexes(n) {
+			needs[idx] = true
+		}
+	}
+	for name, ns := range v.needs {
+		for _, n := range ns {
+			for _, idx := range v.jobIndexes(n) {
+				if idx == i {
+					for _, dep := range v.jobIndexes(name) {
+						neededBy[dep] = true
+					}
+				}
+			}
+		}
+	}
+	return needs, neededBy
+}
+
+// Edge directions of a gutter cell, combined into box-drawing glyphs.
+const (
+	edgeUp = 1 << iota
+	edgeDown
+	edgeLeft
+	edgeRight
+	edgeArrow
+)
+
+var edgeGlyphs = map[int]string{
+	edgeLeft | edgeRight:                     "─",
+	edgeLeft:                                 "─",
+	edgeRight:                                "─",
+	edgeUp | edgeDown:                        "│",
+	edgeDown | edgeRight:                     "┌",
+	edgeDown | edgeLeft:                      "┐",
+	edgeUp | edgeRight:                       "└",
+	edgeUp | edgeLeft:                        "┘",
+	edgeUp | edgeDown | edgeRight:            "├",
+	edgeUp | edgeDown | edgeLeft:             "┤",
+	edgeLeft | edgeRight | edgeDown:          "┬",
+	edgeLeft | edgeRight | edgeUp:            "┴",
+	edgeUp | edgeDown | edgeLeft | edgeRight: "┼",
+}
+
+// gutter draws needs: edges from jobs in column c to jobs in column c+1.
+// Needs that skip a stage aren't drawn; the detail line lists them.
+func (v JobsView) gutter(l graphLayout, c int) [][]int {
+	rows := max(len(l.columns[c]), len(l.columns[c+1]))
+	grid := make([][]int, rows)
+	for r := range grid {
+		grid[r] = make([]int, graphGutterWidth)
+	}
+	src := make(map[int]int) // job index → row in column c
+	for r, idx := range l.columns[c] {
+		src[idx] = r
+	}
+	const trunk = 2
+	for d, idx := range l.columns[c+1] {
+		for _, n := range v.needs[v.Jobs[idx].Name] {
+			for _, need := range v.jobIndexes(n) {
+				s, ok := src[need]
+				if !ok {
+					continue
+				}
+				for x := 0; x < trunk; x++ {
+					grid[s][x] |= edgeLeft | edgeRight
+				}
+				grid[s][trunk] |= edgeLeft
+				switch {
+				case d > s:
+					grid[s][trunk] |= edgeDown
+					grid[d][trunk] |= edgeUp
+				case d < s:
+					grid[s][trunk] |= edgeUp
+					grid[d][trunk] |= edgeDown
+				}
+				for r := min(s, d) + 1; r < max(s, d); r++ {
+					grid[r][trunk] |= edgeUp | edgeDown
+				}
+				grid[d][trunk] |= edgeRight
+				for x := trunk + 1; x < graphGutterWidth-1; x++ {
+					grid[d][x] |= edgeLeft | edgeRight
+				}
+				grid[d][graphGutterWidth-1] |= edgeArrow
+			}
+		}
+	}
+	return grid
+}
+
+func renderGutterRow(cells []int) string {
+	var b strings.Builder
+	for _, c := range cells {
+		switch {
+		case c&edgeArrow != 0:
+			b.WriteString("▶")
+		case c == 0:
+			b.WriteString(" ")
+		default:
+			b.WriteString(edgeGlyphs[c])
+		}
+	}
+	return styles.HelpDesc.Render(b.String())
+}
+
+// fit pads or truncates s to width terminal cells.
+func fit(s string, width int) string {
+	if lipgloss.Width(s) > width {
+		r := []rune(s)
+		for len(r) > 0 && lipgloss.Width(string(r))+1 > width {
+			r = r[:len(r)-1]
+		}
+		return string(r) + "…"
+	}
+	return s + strings.Repeat(" ", width-lipgloss.Width(s))
+}
+
+func (v JobsView) jobCell(i int, needs, neededBy map[int]bool) string {
+	j := v.Jobs[i]
+	mark := " "
+	if v.marked[j.ID] {
+		mark = "◆"
+	}
+	text := "[" + mark + fit(j.Status.Symbol()+" "+j.Name, graphCellWidth-3) + "]"
+	switch {
+	case i == v.Cursor:
+		return styles.Selected.Render(text)
+	case needs[i] || neededBy[i]:
+		return jobStatusStyle(j.Status).Bold(true).Underline(true).Render(text)
+	}
+	return jobStatusStyle(j.Status).Render(text)
+}
+
+func downstreamCell(j entity.Job) string {
+	d := j.Downstream
+	text := "[" + fit(fmt.Sprintf("%s ⇢ #%d %s", d.Status.Symbol(), d.ID, j.Name), graphCellWidth-2) + "]"
+	return statusStyle(d.Status).Render(text)
+}
+
+// graphView draws stages as columns of job boxes with needs: edges between
+// neighbouring stages, and the pipelines started by bridge jobs last.
+func (v JobsView) graphView() string {
+	if len(v.Jobs) == 0 {
+		return "\n" + styles.HelpDesc.Render("  Loading jobs...") + "\n"
+	}
+	l := v.layout()
+	curCol, curRow := l.find(v.Cursor)
+
+	var downstream []entity.Job
+	for _, j := range v.Jobs {
+		if j.Downstream != nil {
+			downstream = append(downstream, j)
+		}
+	}
+	totalCols := len(l.columns)
+	if len(downstream) > 0 {
+		totalCols++
+	}
+
+	// Scroll horizontally to keep the cursor's stage on screen
+	visibleCols := totalCols
+	if v.width > 0 {
+		visibleCols = max(1, (v.width-2+graphGutterWidth)/(graphCellWidth+graphGutterWidth))
+	}
+	firstCol := 0
+	if curCol >= visibleCols {
+		firstCol = curCol - visibleCols + 1
+	}
+	lastCol := min(totalCols, firstCol+visibleCols)
+
+	// Scroll vertically to keep the cursor's row on screen; the stage
+	// header and detail lines take the rest of the height
+	visibleRows := max(1, v.height-4)
+	firstRow := 0
+	if curRow >= visibleRows {
+		firstRow = curRow - visibleRows + 1
+	}
+	rows := 0
+	for _, col := range l.columns {
+		rows = max(rows, len(col))
+	}
+	rows = max(rows, len(downstream))
+	lastRow := min(rows, firstRow+visibleRows)
+
+	needs, neededBy := v.related(v.Cursor)
+	blankCell := strings.Repeat(" ", graphCellWidth)
+	blankGutter := strings.Repeat(" ", graphGutterWidth)
+
+	var gutters [][][]int
+	for c := 0; c+1 < len(l.columns); c++ {
+		gutters = append(gutters, v.gutter(l, c))
+	}
+
+	header := "  "
+	for c := firstCol; c < lastCol; c++ {
+		name := "downstream"
+		if c < len(l.stages) {
+			name = l.stages[c]
+		}
+		header += styles.HelpKey.Render(fit(name, graphCellWidth))
+		if c+1 < lastCol {
+			header += blankGutter
+		}
+	}
+	s := "\n" + header + "\n"
+
+	for r := firstRow; r < lastRow; r++ {
+		line := "  "
+		for c := firstCol; c < lastCol; c++ {
+			switch {
+			case c == len(l.columns):
+				if r < len(downstream) {
+					line += downstreamCell(downstream[r])
+				} else {
+					line += blankCell
+				}
+			case r < len(l.columns[c]):
+				line += v.jobCell(l.columns[c][r], needs, neededBy)
+			default:
+				line += blankCell
+			}
+			if c+1 < lastCol {
+				if c < len(gutters) && r < len(gutters[c]) {
+					line += renderGutterRow(gutters[c][r])
+				} else {
+					line += blankGutter
+				}
+			}
+		}
+		s += line + "\n"
+	}
+
+	var scroll []string
+	if firstCol > 0 {
+		scroll = append(scroll, fmt.Sprintf("‹ %d stages", firstCol))
+	}
+	if lastCol < totalCols {
+		scroll = append(scroll, fmt.Sprintf("%d stages ›", totalCols-lastCol))
+	}
+	if firstRow > 0 || lastRow < rows {
+		scroll = append(scroll, fmt.Sprintf("rows %d-%d of %d", firstRow+1, lastRow, rows))
+	}
+	if len(scroll) > 0 {
+		s += styles.HelpDesc.Render("  "+strings.Join(scroll, " · ")) + "\n"
+	}
+	s += "\n" + v.graphDetail(needs, neededBy) + "\n"
+
+	if n := len(v.Marked()); n > 0 {
+		s += "\n" + styles.Selected.Render(fmt.Sprintf("  ◆ %d marked", n)) +
+			styles.HelpDesc.Render("  r run/retry · c cancel · u clear") + "\n"
+	}
+	return s
+}
+
+// graphDetail describes the job under the cursor and its needs: edges.
+func (v JobsView) graphDetail(needs, neededBy map[int]bool) string {
+	j := v.Jobs[v.Cursor]
+	st := jobStatusStyle(j.Status)
+	s := fmt.Sprintf("  %s %s/%s %s", st.Render(j.Status.Symbol()), j.Stage, j.Name, st.Render(string(j.Status)))
+	if j.Duration > 0 {
+		s += fmt.Sprintf(" %.0fs", j.Duration)
+	}
+	if d := j.Downstream; d != nil {
+		s += styles.HelpKey.Render(fmt.Sprintf("  [⏎ pipeline #%d %s]", d.ID, d.Status))
+	}
+	names := func(set map[int]bool) string {
+		var out []string
+		for i := range set {
+			out = append(out, v.Jobs[i].Name)
+		}
+		sort.Strings(out)
+		return strings.Join(out, ", ")
+	}
+	if len(needs) > 0 {
+		s += styles.HelpDesc.Render("  needs: " + names(needs))
+	}
+	if len(neededBy) > 0 {
+		s += styles.HelpDesc.Render("  needed by: " + names(neededBy))
+	}
+	switch {
+	case j.Downstream != nil:
+	case j.Status == valueobject.JobManual:
+		s += styles.HelpKey.Render("  [r:run]")
+	case j.Status == valueobject.JobFailed:
+		s += styles.HelpKey.Render("  [r:retry]")
+	case j.Status.CanCancel():
+		s += styles.HelpKey.Render("  [c:cancel]")
+	}
+	return s
+}
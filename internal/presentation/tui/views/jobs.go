@@ -15,6 +15,12 @@ type JobsView struct {
 	Cursor int
 	offset int
 	height int
+	width  int
+	marked map[int]bool // job IDs selected for a bulk action
+
+	graph    bool                // draw stages as columns instead of a list
+	needs    map[string][]string // needs: edges by job name, for the graph
+	needsFor int                 // pipeline the needs were loaded for
 }
 
 func NewJobsView() JobsView { return JobsView{height: 20} }
@@ -44,15 +50,67 @@ func (v *JobsView) SelectedJob() *entity.Job {
 }
 
 type JobSelectedMsg struct{ Job entity.Job }
+
+// JobDownstreamMsg opens the pipeline started by a bridge job.
+type JobDownstreamMsg struct{ Job entity.Job }
 type JobActionMsg struct {
 	Action string
 	Job    entity.Job
 }
 
+// JobBulkActionMsg applies an action to all marked jobs: "run" plays manual
+// jobs and retries failed ones, "cancel" cancels running ones.
+type JobBulkActionMsg struct {
+	Action string
+	Jobs   []entity.Job
+}
+
+// Marked returns the marked jobs that are still in the list.
+func (v JobsView) Marked() []entity.Job {
+	var jobs []entity.Job
+	for _, j := range v.Jobs {
+		if v.marked[j.ID] {
+			jobs = append(jobs, j)
+		}
+	}
+	return jobs
+}
+
+func (v *JobsView) ClearMarks() { v.marked = nil }
+
+// markWhere marks every job matching fn, or unmarks them all if they
+// already are.
+func (v *JobsView) markWhere(fn func(entity.Job) bool) {
+	all := true
+	for _, j := range v.Jobs {
+		if fn(j) && !v.marked[j.ID] {
+			all = false
+			break
+		}
+	}
+	if v.marked == nil {
+		v.marked = make(map[int]bool)
+	}
+	for _, j := range v.Jobs {
+		if fn(j) {
+			if all {
+				delete(v.marked, j.ID)
+			} else {
+				v.marked[j.ID] = true
+			}
+		}
+	}
+}
+
 func (v JobsView) Update(msg tea.Msg) (JobsView, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if v.graph && v.graphKey(msg.String()) {
+			return v, nil
+		}
 		switch msg.String() {
+		case "v":
+			return v, v.toggleGraph()
 		case "up", "k":
 			if v.Cursor > 0 {
 				v.Cursor--
@@ -83,9 +141,32 @@ func (v JobsView) Update(msg tea.Msg) (JobsView, tea.Cmd) {
 			v.ensureVisible()
 		case "enter":
 			if len(v.Jobs) > 0 && v.Cursor < len(v.Jobs) {
-				return v, func() tea.Msg { return JobSelectedMsg{Job: v.Jobs[v.Cursor]} }
+				job := v.Jobs[v.Cursor]
+				if job.Bridge {
+					return v, func() tea.Msg { return JobDownstreamMsg{Job: job} }
+				}
+				return v, func() tea.Msg { return JobSelectedMsg{Job: job} }
 			}
+		case " ":
+			if job := v.SelectedJob(); job != nil {
+				v.markWhere(func(j entity.Job) bool { return j.ID == job.ID })
+				if v.Cursor < len(v.Jobs)-1 {
+					v.Cursor++
+					v.ensureVisible()
+				}
+			}
+		case "s":
+			if job := v.SelectedJob(); job != nil {
+				v.markWhere(func(j entity.Job) bool { return j.Stage == job.Stage })
+			}
+		case "a":
+			v.markWhere(func(entity.Job) bool { return true })
+		case "u":
+			v.ClearMarks()
 		case "r":
+			if marked := v.Marked(); len(marked) > 0 {
+				return v, func() tea.Msg { return JobBulkActionMsg{Action: "run", Jobs: marked} }
+			}
 			if len(v.Jobs) > 0 && v.Cursor < len(v.Jobs) {
 				job := v.Jobs[v.Cursor]
 				if job.Status == valueobject.JobManual {
@@ -96,6 +177,9 @@ func (v JobsView) Update(msg tea.Msg) (JobsView, tea.Cmd) {
 				}
 			}
 		case "c":
+			if marked := v.Marked(); len(marked) > 0 {
+				return v, func() tea.Msg { return JobBulkActionMsg{Action: "cancel", Jobs: marked} }
+			}
 			if len(v.Jobs) > 0 && v.Cursor < len(v.Jobs) {
 				job := v.Jobs[v.Cursor]
 				if job.Status.CanCancel() {
@@ -123,6 +207,9 @@ func jobStatusStyle(status valueobject.JobStatus) lipgloss.Style {
 }
 
 func (v JobsView) View() string {
+	if v.graph {
+		return v.graphView()
+	}
 	s := "\n"
 	total := len(v.Jobs)
 	if v.Cursor >= total && total > 0 {
@@ -143,6 +230,7 @@ func (v JobsView) View() string {
 		if idx == v.Cursor {
 			cursor = "▸ "
 		}
+		cursor += markGlyph(v.marked[j.ID])
 		st := jobStatusStyle(j.Status)
 		symbol := st.Render(j.Status.Symbol())
 		status := st.Render(string(j.Status))
@@ -151,7 +239,9 @@ func (v JobsView) View() string {
 			dur = fmt.Sprintf("%.0fs", j.Duration)
 		}
 		hint := ""
-		if j.Status == valueobject.JobManual {
+		if d := j.Downstream; d != nil {
+			hint = styles.HelpKey.Render(fmt.Sprintf(" [⏎ pipeline #%d %s]", d.ID, d.Status))
+		} else if j.Status == valueobject.JobManual {
 			hint = styles.HelpKey.Render(" [r:run]")
 		} else if j.Status == valueobject.JobFailed {
 			hint = styles.HelpKey.Render(" [r:retry]")
@@ -168,5 +258,9 @@ func (v JobsView) View() string {
 	if total > v.height {
 		s += styles.HelpDesc.Render(fmt.Sprintf("\n  %d/%d", v.Cursor+1, total)) + "\n"
 	}
+	if n := len(v.Marked()); n > 0 {
+		s += "\n" + styles.Selected.Render(fmt.Sprintf("  ◆ %d marked", n)) +
+			styles.HelpDesc.Render("  r run/retry · c cancel · u clear") + "\n"
+	}
 	return s
 }
@@ -1,39 +1,419 @@
 package views
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/joblog"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/presentation/tui/styles"
 )
 
 type LogView struct {
-	viewport viewport.Model
-	content  string
-	ready    bool
-	jobName  string
+	viewport  viewport.Model
+	parser    *joblog.Parser
+	parsed    *joblog.Log
+	collapsed []bool // per section, indexed like parsed.Sections
+	section   int    // selected section for fold/unfold, -1 when none
+	ready     bool
+	jobName   string
+	running   bool
+
+	// search state
+	searching bool
+	Query     string
+	regexMode bool
+	searchErr string
+	matches   []int // indices into parsed.Lines
+	match     int   // current entry in matches
 }
 
-func NewLogView() LogView { return LogView{} }
+func NewLogView() LogView { return LogView{section: -1} }
+
+func (v LogView) IsInputMode() bool { return v.searching }
 
 type LogContentMsg struct {
 	Content string
 	JobName string
 }
 
+// LogAppendMsg appends a chunk of trace to the current log without
+// resetting the scroll position. Running reports whether more is expected.
+type LogAppendMsg struct {
+	Content string
+	Running bool
+}
+
+// logLayout maps parsed lines to rendered rows; -1 marks folded lines.
+type logLayout struct {
+	lineRows   []int
+	headerRows []int
+}
+
+// Reset clears the log before streaming a new job into it.
+func (v *LogView) Reset(jobName string) {
+	v.parser = nil
+	v.parsed = nil
+	v.collapsed = nil
+	v.section = -1
+	v.jobName = jobName
+	v.running = true
+	v.searching = false
+	v.Query = ""
+	v.searchErr = ""
+	v.matches = nil
+	v.match = 0
+	if v.ready {
+		v.viewport.SetContent("")
+		v.viewport.GotoTop()
+	}
+}
+
+// appendTrace parses the next chunk of the trace; only the lines it
+// touches are parsed and searched again. Sections only ever get appended,
+// so fold state of already known sections is kept.
+func (v *LogView) appendTrace(chunk string) {
+	if v.parser == nil {
+		v.parser = &joblog.Parser{}
+	}
+	from := v.parser.Write(chunk)
+	v.parsed = v.parser.Log()
+	v.collapsed = v.collapsed[:min(len(v.collapsed), len(v.parsed.Sections))]
+	for i := len(v.collapsed); i < len(v.parsed.Sections); i++ {
+		v.collapsed = append(v.collapsed, v.parsed.Sections[i].Collapsed)
+	}
+	v.findMatchesFrom(from)
+}
+
+// searchPattern compiles the current query. Plain queries are matched
+// case-insensitively; regex queries are used as typed.
+func (v *LogView) searchPattern() (*regexp.Regexp, error) {
+	if v.regexMode {
+		return regexp.Compile(v.Query)
+	}
+	return regexp.Compile("(?i)" + regexp.QuoteMeta(v.Query))
+}
+
+func (v *LogView) findMatches() { v.findMatchesFrom(0) }
+
+// findMatchesFrom searches lines from the given one on, keeping the
+// matches before it.
+func (v *LogView) findMatchesFrom(from int) {
+	for len(v.matches) > 0 && v.matches[len(v.matches)-1] >= from {
+		v.matches = v.matches[:len(v.matches)-1]
+	}
+	v.searchErr = ""
+	if v.Query == "" || v.parsed == nil {
+		v.matches = nil
+		return
+	}
+	re, err := v.searchPattern()
+	if err != nil {
+		v.matches = nil
+		v.searchErr = "invalid regex"
+		return
+	}
+	for i := from; i < len(v.parsed.Lines); i++ {
+		if re.MatchString(joblog.StripANSI(v.parsed.Lines[i].Text)) {
+			v.matches = append(v.matches, i)
+		}
+	}
+	if v.match >= len(v.matches) {
+		v.match = max(0, len(v.matches)-1)
+	}
+}
+
+// highlight returns the plain text of a line with every match marked.
+func (v *LogView) highlight(text string, current bool) string {
+	re, err := v.searchPattern()
+	if err != nil {
+		return text
+	}
+	plain := joblog.StripANSI(text)
+	style := styles.SearchMatch
+	if current {
+		style = styles.SearchCurrent
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringIndex(plain, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+		b.WriteString(plain[last:loc[0]])
+		b.WriteString(style.Render(plain[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	b.WriteString(plain[last:])
+	return b.String()
+}
+
+// render produces the viewport content along with the row of every line
+// and section header.
+func (v *LogView) render() (string, logLayout) {
+	if v.parsed == nil {
+		return "", logLayout{}
+	}
+	layout := logLayout{
+		lineRows:   make([]int, len(v.parsed.Lines)),
+		headerRows: make([]int, len(v.parsed.Sections)),
+	}
+	for i := range layout.headerRows {
+		layout.headerRows[i] = -1
+	}
+	matched := make(map[int]bool, len(v.matches))
+	for _, m := range v.matches {
+		matched[m] = true
+	}
+	currentLine := -1
+	if len(v.matches) > 0 {
+		currentLine = v.matches[v.match]
+	}
+
+	var b strings.Builder
+	row := 0
+	for i, ln := range v.parsed.Lines {
+		if v.parsed.Hidden(i, v.collapsed) {
+			layout.lineRows[i] = -1
+			continue
+		}
+		layout.lineRows[i] = row
+		text := ln.Text
+		if matched[i] {
+			text = v.highlight(text, i == currentLine)
+		}
+		if ln.Header {
+			sec := v.parsed.Sections[ln.Section]
+			arrow := "▾ "
+			if v.collapsed[ln.Section] {
+				arrow = "▸ "
+			}
+			marker := "  "
+			if ln.Section == v.section {
+				marker = styles.HelpKey.Render("❯ ")
+			}
+			dur := ""
+			if d := sec.Duration(); d > 0 {
+				dur = "  " + styles.HelpDesc.Render(joblog.FormatDuration(d))
+			} else if sec.End.IsZero() && v.running {
+				dur = "  " + styles.StatusRunning.Render("…")
+			}
+			text = marker + arrow + text + "\x1b[0m" + dur
+			layout.headerRows[ln.Section] = row
+		} else if strings.Contains(text, "\x1b[") {
+			// don't let an unterminated colour bleed into the next row
+			text += "\x1b[0m"
+		}
+		b.WriteString(text)
+		b.WriteByte('\n')
+		row++
+	}
+	return b.String(), layout
+}
+
+func (v *LogView) refresh() {
+	if !v.ready {
+		return
+	}
+	content, _ := v.render()
+	v.viewport.SetContent(content)
+}
+
+// moveSection selects the next/previous visible section header and scrolls to it.
+func (v *LogView) moveSection(delta int) {
+	if v.parsed == nil || len(v.parsed.Sections) == 0 {
+		return
+	}
+	_, layout := v.render()
+	rows := layout.headerRows
+	i := v.section
+	for {
+		i += delta
+		if i < 0 || i >= len(rows) {
+			return
+		}
+		if rows[i] >= 0 {
+			break
+		}
+	}
+	v.section = i
+	v.refresh()
+	if v.ready {
+		v.viewport.SetYOffset(rows[i])
+	}
+}
+
+func (v *LogView) toggleSection() {
+	if v.section < 0 || v.section >= len(v.collapsed) {
+		return
+	}
+	v.collapsed[v.section] = !v.collapsed[v.section]
+	v.refresh()
+}
+
+// toggleAll collapses every section, or expands all if they already are.
+func (v *LogView) toggleAll() {
+	allCollapsed := true
+	for _, c := range v.collapsed {
+		if !c {
+			allCollapsed = false
+			break
+		}
+	}
+	for i := range v.collapsed {
+		v.collapsed[i] = !allCollapsed
+	}
+	v.refresh()
+}
+
+// showMatch unfolds the sections around the current match and scrolls it
+// into the middle of the viewport.
+func (v *LogView) showMatch() {
+	if len(v.matches) == 0 || v.parsed == nil {
+		v.refresh()
+		return
+	}
+	line := v.matches[v.match]
+	s := v.parsed.Lines[line].Section
+	if v.parsed.Lines[line].Header && s >= 0 {
+		s = v.parsed.Sections[s].Parent
+	}
+	for ; s >= 0; s = v.parsed.Sections[s].Parent {
+		v.collapsed[s] = false
+	}
+	content, layout := v.render()
+	if !v.ready {
+		return
+	}
+	v.viewport.SetContent(content)
+	v.viewport.SetYOffset(max(0, layout.lineRows[line]-v.viewport.Height/2))
+}
+
+// jumpFromCursor picks the first match at or below the top of the viewport,
+// so incremental search moves forward from where the user is reading.
+func (v *LogView) jumpFromCursor() {
+	if len(v.matches) == 0 {
+		v.refresh()
+		return
+	}
+	_, layout := v.render()
+	top := v.viewport.YOffset
+	v.match = 0
+	for i, m := range v.matches {
+		if r := layout.lineRows[m]; r >= top || r < 0 {
+			v.match = i
+			break
+		}
+	}
+	v.showMatch()
+}
+
+func (v *LogView) nextMatch(delta int) {
+	if len(v.matches) == 0 {
+		return
+	}
+	v.match = (v.match + delta + len(v.matches)) % len(v.matches)
+	v.showMatch()
+}
+
+func (v LogView) updateSearch(msg tea.KeyMsg) (LogView, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		v.searching = false
+	case "esc":
+		v.searching = false
+		v.Query = ""
+		v.findMatches()
+		v.refresh()
+	case "ctrl+r":
+		v.regexMode = !v.regexMode
+		v.findMatches()
+		v.jumpFromCursor()
+	case "backspace":
+		if len(v.Query) > 0 {
+			r := []rune(v.Query)
+			v.Query = string(r[:len(r)-1])
+			v.findMatches()
+			v.jumpFromCursor()
+		}
+	default:
+		switch msg.Type {
+		case tea.KeySpace:
+			v.Query += " "
+		case tea.KeyRunes:
+			v.Query += string(msg.Runes)
+		default:
+			return v, nil
+		}
+		v.findMatches()
+		v.jumpFromCursor()
+	}
+	return v, nil
+}
+
 func (v LogView) Update(msg tea.Msg) (LogView, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		v.viewport = viewport.New(msg.Width, msg.Height-4)
-		v.viewport.SetContent(v.content)
 		v.ready = true
+		v.refresh()
 	case LogContentMsg:
-		v.content = msg.Content
+		v.parser = nil
+		v.collapsed = nil
 		v.jobName = msg.JobName
+		v.running = false
+		v.appendTrace(msg.Content)
 		if v.ready {
-			v.viewport.SetContent(v.content)
+			v.refresh()
 			v.viewport.GotoBottom()
 		}
+	case LogAppendMsg:
+		v.running = msg.Running
+		if msg.Content == "" {
+			v.refresh()
+			return v, nil
+		}
+		// Follow the tail only if the user hasn't scrolled up
+		follow := !v.ready || v.parsed == nil || v.viewport.AtBottom()
+		v.appendTrace(msg.Content)
+		if v.ready {
+			v.refresh()
+			if follow {
+				v.viewport.GotoBottom()
+			}
+		}
+		return v, nil
+	case tea.KeyMsg:
+		if v.searching {
+			return v.updateSearch(msg)
+		}
+		switch msg.String() {
+		case "/":
+			v.searching = true
+			v.Query = ""
+			v.findMatches()
+			v.refresh()
+			return v, nil
+		case "n":
+			v.nextMatch(1)
+			return v, nil
+		case "N":
+			v.nextMatch(-1)
+			return v, nil
+		case "]":
+			v.moveSection(1)
+			return v, nil
+		case "[":
+			v.moveSection(-1)
+			return v, nil
+		case " ":
+			v.toggleSection()
+			return v, nil
+		case "z":
+			v.toggleAll()
+			return v, nil
+		}
 	}
 	if v.ready {
 		var cmd tea.Cmd
@@ -43,8 +423,34 @@ func (v LogView) Update(msg tea.Msg) (LogView, tea.Cmd) {
 	return v, nil
 }
 
+func (v LogView) searchStatus() string {
+	mode := ""
+	if v.regexMode {
+		mode = " [regex]"
+	}
+	count := ""
+	switch {
+	case v.searchErr != "":
+		count = styles.StatusFailed.Render("  " + v.searchErr)
+	case v.Query != "" && len(v.matches) == 0:
+		count = styles.HelpDesc.Render("  no matches")
+	case len(v.matches) > 0:
+		count = styles.HelpDesc.Render(fmt.Sprintf("  %d/%d", v.match+1, len(v.matches)))
+	}
+	if v.searching {
+		return styles.HelpKey.Render("  Search"+mode+": ") + v.Query + "█" + count
+	}
+	if v.Query != "" {
+		return styles.HelpKey.Render("  Search"+mode+": ") + styles.HelpDesc.Render(v.Query) + count
+	}
+	return ""
+}
+
 func (v LogView) View() string {
 	if !v.ready { return styles.HelpDesc.Render("  Loading log...") }
 	header := styles.Title.Render("Log: " + v.jobName)
-	return strings.Join([]string{header, "", v.viewport.View()}, "\n")
+	if v.running {
+		header += styles.StatusRunning.Render("● live")
+	}
+	return strings.Join([]string{header, v.searchStatus(), v.viewport.View()}, "\n")
 }
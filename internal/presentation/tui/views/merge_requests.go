@@ -21,6 +21,8 @@ type MergeRequestsView struct {
 	filtering     bool
 	loaded        bool
 	LoadingStatus string
+	HasMore       bool // GitLab has more merge requests than were fetched
+	loadingMore   bool
 }
 
 func NewMergeRequestsView() MergeRequestsView { return MergeRequestsView{height: 20} }
@@ -29,6 +31,10 @@ func (v MergeRequestsView) IsInputMode() bool { return v.filtering }
 
 type MRSelectedMsg struct{ MR entity.MergeRequest }
 
+// MRLoadMoreMsg asks for more merge requests when the cursor reaches the end
+// of the list.
+type MRLoadMoreMsg struct{}
+
 func (v *MergeRequestsView) SetHeight(h int) {
 	v.height = h - 6
 	if v.height < 5 {
@@ -74,6 +80,8 @@ func (v *MergeRequestsView) Reset() {
 	v.MRs = nil
 	v.filtered = nil
 	v.loaded = false
+	v.HasMore = false
+	v.loadingMore = false
 	v.Cursor = 0
 	v.offset = 0
 }
@@ -81,9 +89,23 @@ func (v *MergeRequestsView) Reset() {
 func (v *MergeRequestsView) SetMRs(mrs []entity.MergeRequest) {
 	v.MRs = mrs
 	v.loaded = true
+	v.loadingMore = false
 	v.applyFilter()
 }
 
+// LoadMoreFailed lets the user ask again after a failed "load more".
+func (v *MergeRequestsView) LoadMoreFailed() { v.loadingMore = false }
+
+// loadMore asks for the next page once the cursor sits on the last merge
+// request.
+func (v *MergeRequestsView) loadMore() tea.Cmd {
+	if !v.HasMore || v.loadingMore || v.Cursor < len(v.filtered)-1 {
+		return nil
+	}
+	v.loadingMore = true
+	return func() tea.Msg { return MRLoadMoreMsg{} }
+}
+
 func (v MergeRequestsView) Update(msg tea.Msg) (MergeRequestsView, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -115,12 +137,14 @@ func (v MergeRequestsView) Update(msg tea.Msg) (MergeRequestsView, tea.Cmd) {
 				v.Cursor++
 				v.ensureVisible()
 			}
+			return v, v.loadMore()
 		case "home", "g":
 			v.Cursor = 0
 			v.ensureVisible()
 		case "end", "G":
 			v.Cursor = max(0, len(v.filtered)-1)
 			v.ensureVisible()
+			return v, v.loadMore()
 		case "pgup", "ctrl+u":
 			v.Cursor -= v.height / 2
 			if v.Cursor < 0 {
@@ -133,6 +157,7 @@ func (v MergeRequestsView) Update(msg tea.Msg) (MergeRequestsView, tea.Cmd) {
 				v.Cursor = max(0, len(v.filtered)-1)
 			}
 			v.ensureVisible()
+			return v, v.loadMore()
 		case "enter":
 			if len(v.filtered) > 0 && v.Cursor < len(v.filtered) {
 				return v, func() tea.Msg { return MRSelectedMsg{MR: v.filtered[v.Cursor]} }
@@ -200,6 +225,9 @@ func (v MergeRequestsView) View() string {
 		if mr.Draft {
 			draft = styles.HelpDesc.Render("[Draft] ")
 		}
+		if mr.UnresolvedThreads > 0 {
+			draft += styles.StatusManual.Render(fmt.Sprintf("●%d ", mr.UnresolvedThreads))
+		}
 
 		line := fmt.Sprintf("%s%-16s !%-6d %-20s %s %s %-10s @%-12s %s",
 			cursor, proj, mr.IID, mr.SourceBranch, symbol, draft, stateStr, mr.Author, title)
@@ -221,7 +249,14 @@ func (v MergeRequestsView) View() string {
 	}
 
 	if total > 0 {
-		s += "\n" + styles.HelpDesc.Render(fmt.Sprintf("  %d/%d", v.Cursor+1, total)) + "\n"
+		more := ""
+		switch {
+		case v.loadingMore:
+			more = "  loading more..."
+		case v.HasMore:
+			more = "+  ↓ more"
+		}
+		s += "\n" + styles.HelpDesc.Render(fmt.Sprintf("  %d/%d%s", v.Cursor+1, total, more)) + "\n"
 	}
 
 	return s
@@ -0,0 +1,76 @@
+package views
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/presentation/tui/styles"
+)
+
+// MRCommentMsg asks the app to post a comment. Position is set for a
+// comment on a diff line and DiscussionID for a reply; neither means a
+// general comment on the MR.
+type MRCommentMsg struct {
+	MR           entity.MergeRequest
+	Body         string
+	Position     *entity.DiffPosition
+	DiscussionID string
+}
+
+// commentEditor collects the body of a new comment. It stays open while
+// the comment is sent so that nothing is lost if GitLab rejects it.
+type commentEditor struct {
+	title        string // what the comment is attached to
+	body         string
+	position     *entity.DiffPosition
+	discussionID string
+	sending      bool
+	err          string
+}
+
+// update handles a key and reports whether the comment should be sent or
+// the editor closed.
+func (e *commentEditor) update(msg tea.KeyMsg) (send, cancel bool) {
+	if e.sending {
+		return false, msg.String() == "esc"
+	}
+	switch msg.String() {
+	case "esc":
+		return false, true
+	case "enter":
+		return strings.TrimSpace(e.body) != "", false
+	case "ctrl+j", "alt+enter":
+		e.body += "\n"
+	case "backspace":
+		if e.body != "" {
+			r := []rune(e.body)
+			e.body = string(r[:len(r)-1])
+		}
+	case " ":
+		e.body += " "
+	default:
+		if msg.Type == tea.KeyRunes {
+			e.body += string(msg.Runes)
+		}
+	}
+	e.err = ""
+	return false, false
+}
+
+func (e commentEditor) view() string {
+	var b strings.Builder
+	b.WriteString(styles.HelpKey.Render("  "+e.title) + "\n")
+	for _, line := range strings.Split(e.body+"█", "\n") {
+		b.WriteString("  │ " + line + "\n")
+	}
+	switch {
+	case e.sending:
+		b.WriteString(styles.StatusRunning.Render("  Sending...") + "\n")
+	case e.err != "":
+		b.WriteString(styles.StatusFailed.Render("  "+e.err) + "\n")
+	default:
+		b.WriteString(styles.HelpDesc.Render("  Enter send  Ctrl+J newline  Esc cancel") + "\n")
+	}
+	return b.String()
+}
@@ -2,10 +2,12 @@ package views
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/valueobject"
 	"github.com/bearlogin/gitlab-awesome-cli/internal/presentation/tui/styles"
@@ -19,21 +21,105 @@ const (
 )
 
 type MRDetailView struct {
-	viewport    viewport.Model
-	mr          *entity.MergeRequest
-	diffs       []entity.MRDiff
-	notes       []entity.MRNote
-	tab         mrDetailTab
-	ready       bool
-	diffsLoaded bool
-	notesLoaded bool
+	viewport      viewport.Model
+	mr            *entity.MergeRequest
+	diffs         []entity.MRDiff
+	threads       []entity.Discussion
+	tab           mrDetailTab
+	ready         bool
+	diffsLoaded   bool
+	threadsLoaded bool
+
+	rows       []diffRow
+	diffCursor int  // index into rows, always on a code line
+	split      bool // side-by-side instead of unified
+	rightSide  bool // side the cursor prefers in split mode
+	splitRows  []splitRow
+	splitIndex []int    // split row showing each row
+	lines      []string // rendered diff lines without the cursor, nil when stale
+	width      int
+
+	fileRows      [][]diffRow // rows of each file, shown unless it is folded
+	fileStart     []int       // row of each file's header
+	stats         [][2]int    // lines added and removed per file
+	tree          []*fileNode
+	treeCursor    int  // index into the visible tree entries
+	treeHidden    bool // closed by the user; otherwise shown for multi-file diffs
+	treeFocus     bool
+	collapse      func(path string) bool // generated/vendored files, from config
+	viewed        map[string]bool        // files marked as viewed in viewedVersion
+	viewedVersion string
+	opened        map[int]bool // folded files the user opened anyway
+
+	threadCursor int   // index into threads
+	threadLines  []int // content line of each thread
+	bodyStart    int   // content line where the tab body starts
+	editor       *commentEditor
+}
+
+func NewMRDetailView() MRDetailView { return MRDetailView{opened: make(map[int]bool)} }
+
+func (v MRDetailView) IsInputMode() bool { return v.editor != nil }
+
+// SetCollapse sets which files are folded as generated or vendored.
+func (v *MRDetailView) SetCollapse(collapsed func(path string) bool) { v.collapse = collapsed }
+
+// SetViewed restores the files marked as viewed. They count only while
+// version is the version of the MR on screen.
+func (v *MRDetailView) SetViewed(version string, files []string) {
+	v.viewedVersion = version
+	v.viewed = make(map[string]bool, len(files))
+	for _, f := range files {
+		v.viewed[f] = true
+	}
+	if v.diffsLoaded {
+		v.layoutRows()
+		v.rebuildContent()
+	}
 }
 
-func NewMRDetailView() MRDetailView { return MRDetailView{} }
+// CommentsTab reports whether the Comments tab is showing.
+func (v MRDetailView) CommentsTab() bool { return v.tab == mrTabComments }
+
+// SplitDiff reports whether diffs are shown side by side.
+func (v MRDetailView) SplitDiff() bool { return v.split }
+
+// TreeFocused reports whether keys go to the file tree.
+func (v MRDetailView) TreeFocused() bool { return v.treeFocus && v.treeVisible() }
+
+// CommentDone closes the editor after the comment was posted, or shows
+// why it wasn't and keeps the text for another try.
+func (v *MRDetailView) CommentDone(err error) {
+	if v.editor == nil {
+		return
+	}
+	if err != nil {
+		v.editor.sending = false
+		v.editor.err = err.Error()
+		return
+	}
+	v.editor = nil
+}
 
 type MRApproveMsg struct{ MR entity.MergeRequest }
 type MRMergeMsg struct{ MR entity.MergeRequest }
 type MRRefreshMsg struct{ MR entity.MergeRequest }
+
+// MRViewedMsg asks the app to remember the files marked as viewed in a
+// version of an MR.
+type MRViewedMsg struct {
+	ProjectID int
+	MRIID     int
+	Version   string
+	Files     []string
+}
+
+// MRResolveMsg asks the app to resolve a thread, or reopen it.
+type MRResolveMsg struct {
+	MR           entity.MergeRequest
+	DiscussionID string
+	Resolved     bool
+}
 type MRApprovedMsg struct{ Err error }
 type MRMergedMsg struct {
 	MR  *entity.MergeRequest
@@ -42,36 +128,174 @@ type MRMergedMsg struct {
 
 func (v *MRDetailView) SetMR(mr *entity.MergeRequest) {
 	isNewMR := v.mr == nil || v.mr.IID != mr.IID || v.mr.ProjectID != mr.ProjectID
+	version := v.version()
 	v.mr = mr
 	if isNewMR {
-		v.diffs = nil
-		v.notes = nil
-		v.diffsLoaded = false
-		v.notesLoaded = false
+		v.ForceReset()
+		v.tree, v.opened = nil, make(map[int]bool)
 		v.tab = mrTabDiffs
+		v.diffCursor, v.threadCursor, v.treeCursor = 0, 0, 0
+		v.treeFocus = false
+		v.viewed, v.viewedVersion = nil, ""
+		v.editor = nil
+	} else if v.diffsLoaded && v.version() != version {
+		// a push invalidates the viewed marks
+		v.layoutRows()
 	}
 	v.rebuildContent()
 }
 
 func (v *MRDetailView) ForceReset() {
 	v.diffs = nil
-	v.notes = nil
+	v.threads = nil
+	v.rows, v.splitRows, v.lines = nil, nil, nil
+	v.fileRows = nil
 	v.diffsLoaded = false
-	v.notesLoaded = false
+	v.threadsLoaded = false
 	v.rebuildContent()
 }
 
+// SetDiffs keeps the line cursor where it was, so that a refresh doesn't
+// throw the reader back to the top.
 func (v *MRDetailView) SetDiffs(diffs []entity.MRDiff) {
 	v.diffs = diffs
+	v.fileRows = make([][]diffRow, len(diffs))
+	v.stats = make([][2]int, len(diffs))
+	for i, d := range diffs {
+		v.fileRows[i] = fileDiffRows(i, d)
+		for _, row := range v.fileRows[i] {
+			switch row.kind {
+			case '+':
+				v.stats[i][0]++
+			case '-':
+				v.stats[i][1]++
+			}
+		}
+	}
+	folded := make(map[string]bool)
+	for _, e := range treeEntries(v.tree, 0, nil) {
+		if e.node.dir() && e.node.folded {
+			folded[e.node.name] = true
+		}
+	}
+	v.tree = buildFileTree(diffs)
+	for _, e := range treeEntries(v.tree, 0, nil) {
+		e.node.folded = e.node.dir() && folded[e.node.name]
+	}
 	v.diffsLoaded = true
+	v.layoutRows()
 	if v.tab == mrTabDiffs {
 		v.rebuildContent()
 	}
 }
 
-func (v *MRDetailView) SetNotes(notes []entity.MRNote) {
-	v.notes = notes
-	v.notesLoaded = true
+// layoutRows lays out the files, folding the viewed and collapsed ones,
+// and keeps the cursor on the line it was on if that is still shown.
+func (v *MRDetailView) layoutRows() {
+	var at *diffRow
+	if v.diffCursor < len(v.rows) {
+		row := v.rows[v.diffCursor]
+		at = &row
+	}
+	v.rows = nil
+	v.fileStart = make([]int, len(v.fileRows))
+	for i, rows := range v.fileRows {
+		v.fileStart[i] = len(v.rows)
+		if reason := v.folded(i); reason != "" {
+			head := rows[0]
+			head.text += " · " + reason
+			v.rows = append(v.rows, head, diffRow{file: i})
+			continue
+		}
+		v.rows = append(v.rows, rows...)
+	}
+	v.splitRows, v.splitIndex = splitLayout(v.rows)
+	v.lines = nil
+
+	switch {
+	case at == nil:
+		v.diffCursor = v.nextCodeRow(0, 1)
+	case at.file < len(v.fileStart):
+		v.diffCursor = v.nextCodeRow(v.fileStart[at.file], 1)
+		for i := v.fileStart[at.file]; i < len(v.rows) && v.rows[i].file == at.file; i++ {
+			if r := v.rows[i]; r.commentable() && r.kind == at.kind && r.oldLine == at.oldLine && r.newLine == at.newLine {
+				v.diffCursor = i
+				break
+			}
+		}
+	default:
+		v.diffCursor = v.nextCodeRow(min(v.diffCursor, len(v.rows)-1), 1)
+	}
+}
+
+// version identifies the version of the MR the viewed marks belong to:
+// its head commit.
+func (v MRDetailView) version() string {
+	if v.mr == nil {
+		return ""
+	}
+	if v.mr.SHA != "" {
+		return v.mr.SHA
+	}
+	return v.mr.DiffRefs.HeadSHA
+}
+
+func (v MRDetailView) isViewed(path string) bool {
+	return v.viewed[path] && v.viewedVersion == v.version()
+}
+
+// foldReason says why file i is folded by default, or is empty.
+func (v MRDetailView) foldReason(i int) string {
+	path := v.diffs[i].NewPath
+	switch {
+	case v.isViewed(path):
+		return "viewed"
+	case v.collapse != nil && v.collapse(path):
+		return "collapsed"
+	}
+	return ""
+}
+
+// folded says why file i is folded, or is empty when it is shown.
+func (v MRDetailView) folded(i int) string {
+	if v.opened[i] {
+		return ""
+	}
+	return v.foldReason(i)
+}
+
+// toggleViewed marks file i as viewed, folding it, or unmarks it.
+func (v *MRDetailView) toggleViewed(i int) tea.Cmd {
+	if v.mr == nil || i < 0 || i >= len(v.diffs) {
+		return nil
+	}
+	version := v.version()
+	if v.viewed == nil || v.viewedVersion != version {
+		v.viewed, v.viewedVersion = make(map[string]bool), version
+	}
+	path := v.diffs[i].NewPath
+	if v.viewed[path] {
+		delete(v.viewed, path)
+	} else {
+		v.viewed[path] = true
+		delete(v.opened, i)
+	}
+	v.layoutRows()
+	v.rebuildContent()
+	v.follow(v.bodyStart + v.cursorLine())
+
+	msg := MRViewedMsg{ProjectID: v.mr.ProjectID, MRIID: v.mr.IID, Version: version}
+	for f := range v.viewed {
+		msg.Files = append(msg.Files, f)
+	}
+	sort.Strings(msg.Files)
+	return func() tea.Msg { return msg }
+}
+
+func (v *MRDetailView) SetDiscussions(threads []entity.Discussion) {
+	v.threads = threads
+	v.threadCursor = max(0, min(v.threadCursor, len(threads)-1))
+	v.threadsLoaded = true
 	if v.tab == mrTabComments {
 		v.rebuildContent()
 	}
@@ -81,6 +305,10 @@ func (v *MRDetailView) rebuildContent() {
 	if v.mr == nil {
 		return
 	}
+	if width := v.bodyWidth(); v.ready && v.viewport.Width != width {
+		v.viewport.Width = width
+		v.lines = nil
+	}
 	var b strings.Builder
 
 	state := valueobject.MRState(v.mr.State)
@@ -91,6 +319,9 @@ func (v *MRDetailView) rebuildContent() {
 	fmt.Fprintf(&b, "%s !%d: %s%s\n", state.Symbol(), v.mr.IID, v.mr.Title, draft)
 	fmt.Fprintf(&b, "Author: @%s  |  %s → %s  |  %s  |  %s\n",
 		v.mr.Author, v.mr.SourceBranch, v.mr.TargetBranch, v.mr.State, v.mr.MergeStatus)
+	if v.mr.AutoMerge && v.mr.State == "opened" {
+		b.WriteString(styles.StatusRunning.Render("Merges when the pipeline succeeds") + "\n")
+	}
 	if v.mr.Description != "" {
 		fmt.Fprintf(&b, "\n%s\n", v.mr.Description)
 	}
@@ -98,48 +329,48 @@ func (v *MRDetailView) rebuildContent() {
 
 	// Tab indicator
 	diffTab := "  Diffs  "
-	commentsTab := "  Comments  "
+	comments := "Comments"
+	if n := entity.CountUnresolved(v.threads); n > 0 {
+		comments = fmt.Sprintf("Comments (%d unresolved)", n)
+	}
+	commentsTab := "  " + comments + "  "
 	if v.tab == mrTabDiffs {
 		diffTab = " [Diffs] "
 	} else {
-		commentsTab = " [Comments] "
+		commentsTab = " [" + comments + "] "
 	}
 	fmt.Fprintf(&b, "%s | %s\n", diffTab, commentsTab)
 	b.WriteString(strings.Repeat("─", 60) + "\n\n")
 
+	v.bodyStart = strings.Count(b.String(), "\n")
 	if v.tab == mrTabDiffs {
 		if !v.diffsLoaded {
 			b.WriteString("Loading diffs...\n")
 		} else if len(v.diffs) == 0 {
 			b.WriteString("No changes.\n")
 		} else {
-			for _, d := range v.diffs {
-				label := d.NewPath
-				if d.NewFile {
-					label += " (new)"
-				} else if d.DeletedFile {
-					label += " (deleted)"
-				} else if d.RenamedFile {
-					label = fmt.Sprintf("%s → %s (renamed)", d.OldPath, d.NewPath)
+			cursor := v.cursorLine()
+			for i, line := range v.diffLines() {
+				if i == cursor {
+					line = v.renderLine(i, v.diffCursor)
 				}
-				b.WriteString(styles.DiffFilePath.Render(label) + "\n")
-				b.WriteString(renderDiffLines(d.Diff))
+				b.WriteString(line)
 				b.WriteByte('\n')
 			}
 		}
 	} else {
-		if !v.notesLoaded {
+		v.threadLines = v.threadLines[:0]
+		if !v.threadsLoaded {
 			b.WriteString("Loading comments...\n")
-		} else if len(v.notes) == 0 {
+		} else if len(v.threads) == 0 {
 			b.WriteString("No comments.\n")
 		} else {
-			for _, n := range v.notes {
-				prefix := ""
-				if n.System {
-					prefix = "[system] "
-				}
-				fmt.Fprintf(&b, "%s@%s (%s):\n%s\n\n",
-					prefix, n.Author, timeAgo(n.CreatedAt), n.Body)
+			line := v.bodyStart
+			for i, d := range v.threads {
+				v.threadLines = append(v.threadLines, line)
+				thread := renderThread(d, i == v.threadCursor)
+				b.WriteString(thread)
+				line += strings.Count(thread, "\n")
 			}
 		}
 	}
@@ -152,17 +383,85 @@ func (v *MRDetailView) rebuildContent() {
 func (v MRDetailView) Update(msg tea.Msg) (MRDetailView, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		v.viewport = viewport.New(msg.Width, msg.Height-4)
+		// Leave room for the app's header, footer and padding and our title
+		v.viewport = viewport.New(msg.Width, msg.Height-7)
 		v.ready = true
+		v.width, v.lines = msg.Width, nil
 		v.rebuildContent()
 	case tea.KeyMsg:
+		if v.editor != nil {
+			send, cancel := v.editor.update(msg)
+			switch {
+			case cancel:
+				v.editor = nil
+			case send:
+				v.editor.sending = true
+				e := *v.editor
+				mr := *v.mr
+				return v, func() tea.Msg {
+					return MRCommentMsg{MR: mr, Body: e.body, Position: e.position, DiscussionID: e.discussionID}
+				}
+			}
+			return v, nil
+		}
+		if v.TreeFocused() && v.moveTree(msg.String()) {
+			return v, nil
+		}
 		switch msg.String() {
+		case "up", "k", "down", "j", "pgup", "pgdown", "home", "g", "end", "G", "n", "left", "h", "right", "l":
+			if v.moveCursor(msg.String()) {
+				return v, nil
+			}
+		case "s":
+			if v.tab == mrTabDiffs {
+				v.split = !v.split
+				v.lines = nil
+				v.rebuildContent()
+				v.follow(v.bodyStart + v.cursorLine())
+				return v, nil
+			}
+		case "t":
+			if v.tab == mrTabDiffs {
+				v.toggleTree()
+				return v, nil
+			}
+		case "v":
+			if v.tab == mrTabDiffs {
+				file := v.currentFile()
+				if v.TreeFocused() {
+					entries := treeEntries(v.tree, 0, nil)
+					if v.treeCursor >= len(entries) || entries[v.treeCursor].node.dir() {
+						return v, nil
+					}
+					file = entries[v.treeCursor].node.file
+				}
+				return v, v.toggleViewed(file)
+			}
+		case "c":
+			if v.mr != nil {
+				v.openEditor(false)
+				return v, nil
+			}
+		case "enter":
+			if v.mr != nil && v.tab == mrTabComments {
+				v.openEditor(true)
+				return v, nil
+			}
+		case "x":
+			if v.mr != nil && v.tab == mrTabComments && v.threadCursor < len(v.threads) {
+				d := v.threads[v.threadCursor]
+				if d.Resolvable {
+					mr := *v.mr
+					return v, func() tea.Msg { return MRResolveMsg{MR: mr, DiscussionID: d.ID, Resolved: !d.Resolved} }
+				}
+			}
 		case "tab":
 			if v.tab == mrTabDiffs {
 				v.tab = mrTabComments
 			} else {
 				v.tab = mrTabDiffs
 			}
+			v.treeFocus = false
 			v.rebuildContent()
 			v.viewport.GotoTop()
 			return v, nil
@@ -199,28 +498,306 @@ func (v MRDetailView) View() string {
 	if v.mr != nil {
 		title = styles.Title.Render(fmt.Sprintf("MR !%d: %s", v.mr.IID, v.mr.Title))
 	}
-	return strings.Join([]string{title, "", v.viewport.View()}, "\n")
+	vp := v.viewport
+	top := ""
+	if v.editor != nil {
+		top = v.editor.view()
+		vp.Height = max(1, vp.Height-strings.Count(top, "\n"))
+	}
+	body := vp.View()
+	if v.treeVisible() {
+		body = lipgloss.JoinHorizontal(lipgloss.Top, v.treeView(vp.Height), body)
+	}
+	return strings.Join([]string{title, top, body}, "\n")
 }
 
-func renderDiffLines(diff string) string {
-	lines := strings.Split(diff, "\n")
-	var b strings.Builder
-	for _, line := range lines {
-		if len(line) == 0 {
-			b.WriteByte('\n')
-			continue
+// toggleTree moves the focus to the file tree, showing it if needed, or
+// closes the tree when it already has the focus.
+func (v *MRDetailView) toggleTree() {
+	switch {
+	case v.TreeFocused():
+		v.treeHidden, v.treeFocus = true, false
+	default:
+		v.treeHidden = false
+		v.treeFocus = v.treeVisible()
+		if k := fileEntryIndex(treeEntries(v.tree, 0, nil), v.currentFile()); k >= 0 {
+			v.treeCursor = k
 		}
-		switch {
-		case strings.HasPrefix(line, "@@"):
-			b.WriteString(styles.DiffHunk.Render(line))
-		case line[0] == '+':
-			b.WriteString(styles.DiffAdd.Render(line))
-		case line[0] == '-':
-			b.WriteString(styles.DiffDel.Render(line))
+	}
+	v.rebuildContent()
+}
+
+// bodyWidth is the width left for the diff next to the file tree.
+func (v MRDetailView) bodyWidth() int {
+	if v.treeVisible() {
+		return v.width - v.treeWidth()
+	}
+	return v.width
+}
+
+// moveCursor moves the diff line or thread cursor and scrolls to keep it in
+// view. It reports false when there is nothing to move over, so the key
+// scrolls the viewport instead.
+func (v *MRDetailView) moveCursor(key string) bool {
+	page := max(1, v.viewport.Height-1)
+	if v.tab == mrTabComments {
+		if len(v.threads) == 0 {
+			return false
+		}
+		switch key {
+		case "up", "k":
+			v.threadCursor = max(0, v.threadCursor-1)
+		case "down", "j":
+			v.threadCursor = min(len(v.threads)-1, v.threadCursor+1)
+		case "home", "g":
+			v.threadCursor = 0
+		case "end", "G":
+			v.threadCursor = len(v.threads) - 1
+		case "n":
+			v.threadCursor = v.nextUnresolved()
 		default:
-			b.WriteString(line)
+			return false
+		}
+		v.rebuildContent()
+		if v.threadCursor < len(v.threadLines) {
+			v.follow(v.threadLines[v.threadCursor])
+		}
+		return true
+	}
+	if len(v.rows) == 0 {
+		return false
+	}
+	if v.split {
+		return v.moveSplitCursor(key, page)
+	}
+	switch key {
+	case "up", "k":
+		v.diffCursor = v.nextCodeRow(v.diffCursor-1, -1)
+	case "down", "j":
+		v.diffCursor = v.nextCodeRow(v.diffCursor+1, 1)
+	case "pgup":
+		v.diffCursor = v.nextCodeRow(max(0, v.diffCursor-page), -1)
+	case "pgdown":
+		v.diffCursor = v.nextCodeRow(min(len(v.rows)-1, v.diffCursor+page), 1)
+	case "home", "g":
+		v.diffCursor = v.nextCodeRow(0, 1)
+	case "end", "G":
+		v.diffCursor = v.nextCodeRow(len(v.rows)-1, -1)
+	default:
+		return false
+	}
+	v.rebuildContent()
+	v.follow(v.bodyStart + v.diffCursor)
+	return true
+}
+
+// moveSplitCursor moves over the lines of the side-by-side view. ←→ pick
+// the side, which sticks while moving up and down where that side has code.
+func (v *MRDetailView) moveSplitCursor(key string, page int) bool {
+	cur := v.cursorLine()
+	last := len(v.splitRows) - 1
+	row := -1
+	switch key {
+	case "up", "k":
+		row = v.nextSplitRow(cur-1, -1)
+	case "down", "j":
+		row = v.nextSplitRow(cur+1, 1)
+	case "pgup":
+		row = v.nextSplitRow(max(0, cur-page), -1)
+	case "pgdown":
+		row = v.nextSplitRow(min(last, cur+page), 1)
+	case "home", "g":
+		row = v.nextSplitRow(0, 1)
+	case "end", "G":
+		row = v.nextSplitRow(last, -1)
+	case "left", "h", "right", "l":
+		v.rightSide = key == "right" || key == "l"
+		row = v.pickSide(v.splitRows[cur])
+	default:
+		return false
+	}
+	if row >= 0 {
+		v.diffCursor = row
+	}
+	v.rebuildContent()
+	v.follow(v.bodyStart + v.cursorLine())
+	return true
+}
+
+// nextSplitRow returns the code line on the first split row from i in
+// direction dir that has one, or -1.
+func (v *MRDetailView) nextSplitRow(i, dir int) int {
+	for j := i; j >= 0 && j < len(v.splitRows); j += dir {
+		if row := v.pickSide(v.splitRows[j]); row >= 0 {
+			return row
+		}
+	}
+	return -1
+}
+
+// pickSide returns the code line on the preferred side of sr, the other
+// side if that one is empty, or -1.
+func (v *MRDetailView) pickSide(sr splitRow) int {
+	sides := []int{sr.left, sr.right}
+	if v.rightSide {
+		sides = []int{sr.right, sr.left}
+	}
+	for _, i := range sides {
+		if i >= 0 && v.rows[i].commentable() {
+			return i
+		}
+	}
+	return -1
+}
+
+// cursorLine is the line of the Diffs tab body the cursor is on.
+func (v *MRDetailView) cursorLine() int { return v.rowLine(v.diffCursor) }
+
+// rowLine is the line of the Diffs tab body showing row i.
+func (v *MRDetailView) rowLine(i int) int {
+	if v.split && i < len(v.splitIndex) {
+		return v.splitIndex[i]
+	}
+	return i
+}
+
+// diffLines renders the Diffs tab body without the cursor. Highlighting
+// every line again on each key press is slow on big MRs, so the lines are
+// kept until the diffs, the width or the layout change.
+func (v *MRDetailView) diffLines() []string {
+	if v.lines != nil {
+		return v.lines
+	}
+	n := len(v.rows)
+	if v.split {
+		n = len(v.splitRows)
+	}
+	v.lines = make([]string, n)
+	for i := range v.lines {
+		v.lines[i] = v.renderLine(i, -1)
+	}
+	return v.lines
+}
+
+// renderLine renders line i of the Diffs tab body with the cursor on the
+// given row.
+func (v *MRDetailView) renderLine(i, cursor int) string {
+	if !v.split {
+		return renderDiffRow(v.rows[i], i == cursor)
+	}
+	width := v.bodyWidth()
+	if width == 0 {
+		width = 120
+	}
+	return renderSplitRow(v.rows, v.splitRows[i], max(20, (width-1)/2), cursor)
+}
+
+// nextCodeRow returns the first code line from i in direction dir, falling
+// back to the other direction and then to the current cursor.
+func (v *MRDetailView) nextCodeRow(i, dir int) int {
+	for _, d := range []int{dir, -dir} {
+		for j := i; j >= 0 && j < len(v.rows); j += d {
+			if v.rows[j].commentable() {
+				return j
+			}
+		}
+	}
+	return max(0, min(v.diffCursor, len(v.rows)-1))
+}
+
+func (v *MRDetailView) follow(line int) {
+	if !v.ready {
+		return
+	}
+	switch {
+	case line < v.viewport.YOffset:
+		v.viewport.SetYOffset(line)
+	case line >= v.viewport.YOffset+v.viewport.Height:
+		v.viewport.SetYOffset(line - v.viewport.Height + 1)
+	}
+}
+
+// openEditor starts a comment on the diff line under the cursor, a reply to
+// the selected thread, or a general comment.
+func (v *MRDetailView) openEditor(reply bool) {
+	e := &commentEditor{title: fmt.Sprintf("Comment on !%d", v.mr.IID)}
+	switch {
+	case reply:
+		if v.threadCursor >= len(v.threads) {
+			return
+		}
+		d := v.threads[v.threadCursor]
+		if len(d.Notes) == 0 || d.Notes[0].System {
+			return
+		}
+		e.title = fmt.Sprintf("Reply to @%s", d.Notes[0].Author)
+		e.discussionID = d.ID
+	case v.tab == mrTabDiffs:
+		if v.diffCursor >= len(v.rows) || !v.rows[v.diffCursor].commentable() {
+			return
+		}
+		row := v.rows[v.diffCursor]
+		d := v.diffs[row.file]
+		e.position = &entity.DiffPosition{
+			DiffRefs: v.mr.DiffRefs,
+			OldPath:  d.OldPath,
+			NewPath:  d.NewPath,
+			OldLine:  row.oldLine,
+			NewLine:  row.newLine,
+		}
+		e.title = fmt.Sprintf("Comment on %s:%d", d.NewPath, max(row.newLine, row.oldLine))
+	}
+	v.editor = e
+}
+
+// nextUnresolved returns the first unresolved thread after the cursor,
+// wrapping around, or the cursor if there is none.
+func (v *MRDetailView) nextUnresolved() int {
+	for i := 1; i <= len(v.threads); i++ {
+		j := (v.threadCursor + i) % len(v.threads)
+		if v.threads[j].Unresolved() {
+			return j
+		}
+	}
+	return v.threadCursor
+}
+
+func renderThread(d entity.Discussion, selected bool) string {
+	var b strings.Builder
+	cursor := "  "
+	if selected {
+		cursor = "▸ "
+	}
+	var status []string
+	switch {
+	case d.Unresolved():
+		status = append(status, styles.StatusManual.Render("● unresolved"))
+	case d.Resolved:
+		status = append(status, styles.StatusSuccess.Render("✓ resolved"))
+	}
+	if p := d.Position; p != nil {
+		status = append(status, fmt.Sprintf("%s:%d", p.NewPath, max(p.NewLine, p.OldLine)))
+	}
+	if len(status) > 0 {
+		b.WriteString(cursor + strings.Join(status, "  ") + "\n")
+		cursor = "  "
+	}
+	for i, n := range d.Notes {
+		indent := ""
+		if i > 0 {
+			indent = "  ↳ "
+		}
+		prefix := ""
+		if n.System {
+			prefix = "[system] "
+		}
+		fmt.Fprintf(&b, "%s%s%s@%s (%s):\n", cursor, indent, prefix, n.Author, timeAgo(n.CreatedAt))
+		cursor = "  "
+		pad := "  " + strings.Repeat(" ", len([]rune(indent)))
+		for _, line := range strings.Split(n.Body, "\n") {
+			b.WriteString(pad + line + "\n")
 		}
-		b.WriteByte('\n')
 	}
+	b.WriteByte('\n')
 	return b.String()
 }
@@ -0,0 +1,331 @@
+package views
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/domain/entity"
+	"github.com/bearlogin/gitlab-awesome-cli/internal/presentation/tui/styles"
+)
+
+const (
+	plFieldProject = iota
+	plFieldRef
+	plFieldVars // first variable key; each variable takes a key and a value field
+)
+
+// variableKeyRe is what GitLab accepts as a CI/CD variable name.
+var variableKeyRe = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+type PipelineCreateSubmitMsg struct {
+	ProjectPath string
+	Ref         string
+	Variables   []entity.PipelineVariable
+}
+
+type PipelineCreateCancelMsg struct{}
+
+// PipelineRefSearchMsg is sent by the view to request branch search.
+type PipelineRefSearchMsg struct {
+	ProjectPath string
+	Query       string
+}
+
+// PipelineRefSearchResultMsg is returned by the app with branch results.
+type PipelineRefSearchResultMsg struct {
+	Branches []string
+	Query    string
+}
+
+type PipelineCreateView struct {
+	project   string
+	ref       string
+	vars      []entity.PipelineVariable
+	cursor    int
+	active    bool
+	projects  []string // configured project paths
+	projSugs  []string // filtered project suggestions
+	branches  []string // suggestions for the ref field
+	sugCursor int
+	errMsg    string
+}
+
+func NewPipelineCreateView() PipelineCreateView {
+	return PipelineCreateView{}
+}
+
+func (v PipelineCreateView) IsInputMode() bool { return v.active }
+
+// Activate opens the form. project and ref prefill the fields, typically
+// from the highlighted pipeline; either may be empty.
+func (v *PipelineCreateView) Activate(projects []string, project, ref string) {
+	v.active = true
+	v.project = project
+	v.ref = ref
+	v.vars = []entity.PipelineVariable{{}}
+	v.projects = projects
+	v.branches = nil
+	v.sugCursor = 0
+	v.errMsg = ""
+	if project == "" {
+		v.cursor = plFieldProject
+		v.projSugs = projects // show all initially
+	} else {
+		v.cursor = plFieldRef
+		v.projSugs = nil
+	}
+}
+
+func (v PipelineCreateView) Update(msg tea.Msg) (PipelineCreateView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case PipelineRefSearchResultMsg:
+		if v.cursor == plFieldRef && msg.Query == v.ref {
+			v.branches = msg.Branches
+			v.sugCursor = 0
+		}
+		return v, nil
+	case tea.KeyMsg:
+		return v.handleKey(msg)
+	}
+	return v, nil
+}
+
+func (v PipelineCreateView) fieldCount() int { return plFieldVars + 2*len(v.vars) }
+
+// field returns the text of the field under index i.
+func (v *PipelineCreateView) field(i int) *string {
+	switch i {
+	case plFieldProject:
+		return &v.project
+	case plFieldRef:
+		return &v.ref
+	}
+	row := (i - plFieldVars) / 2
+	if (i-plFieldVars)%2 == 0 {
+		return &v.vars[row].Key
+	}
+	return &v.vars[row].Value
+}
+
+func (v *PipelineCreateView) isValueField() bool {
+	return v.cursor >= plFieldVars && (v.cursor-plFieldVars)%2 == 1
+}
+
+func (v PipelineCreateView) handleKey(msg tea.KeyMsg) (PipelineCreateView, tea.Cmd) {
+	key := msg.String()
+
+	// Suggestion list active (project or ref)
+	if sugs := v.currentSuggestions(); len(sugs) > 0 {
+		switch key {
+		case "esc":
+			v.clearSuggestions()
+			return v, nil
+		case "tab", "down":
+			if v.sugCursor < len(sugs)-1 {
+				v.sugCursor++
+			}
+			return v, nil
+		case "shift+tab", "up":
+			if v.sugCursor > 0 {
+				v.sugCursor--
+			}
+			return v, nil
+		case "enter":
+			if v.sugCursor < len(sugs) {
+				*v.field(v.cursor) = sugs[v.sugCursor]
+				v.clearSuggestions()
+				v.cursor++
+			}
+			return v, nil
+		}
+		// fall through for typing
+	}
+
+	switch key {
+	case "esc":
+		v.active = false
+		return v, func() tea.Msg { return PipelineCreateCancelMsg{} }
+	case "tab", "down":
+		v.clearSuggestions()
+		if v.cursor < v.fieldCount()-1 {
+			v.cursor++
+		}
+	case "shift+tab", "up":
+		v.clearSuggestions()
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "enter":
+		if v.cursor == v.fieldCount()-1 {
+			return v.submit()
+		}
+		v.clearSuggestions()
+		v.cursor++
+	case "ctrl+s":
+		return v.submit()
+	case "ctrl+n":
+		v.clearSuggestions()
+		v.vars = append(v.vars, entity.PipelineVariable{})
+		v.cursor = v.fieldCount() - 2
+	case "ctrl+x":
+		if v.cursor >= plFieldVars {
+			row := (v.cursor - plFieldVars) / 2
+			v.vars = append(v.vars[:row], v.vars[row+1:]...)
+			if len(v.vars) == 0 {
+				v.vars = []entity.PipelineVariable{{}}
+			}
+			v.cursor = min(v.cursor, v.fieldCount()-1)
+			v.cursor -= (v.cursor - plFieldVars) % 2 // land on the key
+		}
+	case " ":
+		if !v.isValueField() {
+			return v, nil // no spaces in project, ref or variable names
+		}
+		*v.field(v.cursor) += " "
+	case "backspace":
+		if f := v.field(v.cursor); len(*f) > 0 {
+			*f = (*f)[:len(*f)-1]
+			v.clearSuggestions()
+			return v, v.onFieldChanged()
+		}
+	default:
+		if len(key) == 1 {
+			*v.field(v.cursor) += key
+			v.clearSuggestions()
+			return v, v.onFieldChanged()
+		}
+	}
+	return v, nil
+}
+
+func (v *PipelineCreateView) currentSuggestions() []string {
+	switch v.cursor {
+	case plFieldProject:
+		return v.projSugs
+	case plFieldRef:
+		return v.branches
+	}
+	return nil
+}
+
+func (v *PipelineCreateView) clearSuggestions() {
+	v.branches = nil
+	v.projSugs = nil
+	v.sugCursor = 0
+}
+
+func (v *PipelineCreateView) onFieldChanged() tea.Cmd {
+	switch v.cursor {
+	case plFieldProject:
+		v.filterProjects()
+	case plFieldRef:
+		if v.ref == "" || v.project == "" {
+			return nil
+		}
+		proj, q := v.project, v.ref
+		return func() tea.Msg {
+			return PipelineRefSearchMsg{ProjectPath: proj, Query: q}
+		}
+	}
+	return nil
+}
+
+func (v *PipelineCreateView) filterProjects() {
+	q := strings.ToLower(v.project)
+	v.sugCursor = 0
+	if q == "" {
+		v.projSugs = v.projects
+		return
+	}
+	v.projSugs = nil
+	for _, p := range v.projects {
+		if strings.Contains(strings.ToLower(p), q) {
+			v.projSugs = append(v.projSugs, p)
+		}
+	}
+}
+
+func (v PipelineCreateView) submit() (PipelineCreateView, tea.Cmd) {
+	project := strings.TrimSpace(v.project)
+	ref := strings.TrimSpace(v.ref)
+	if project == "" || ref == "" {
+		v.errMsg = "Project and ref are required"
+		return v, nil
+	}
+
+	var vars []entity.PipelineVariable
+	for _, pv := range v.vars {
+		if pv.Key == "" {
+			if pv.Value != "" {
+				v.errMsg = fmt.Sprintf("Variable with value %q has no name", pv.Value)
+				return v, nil
+			}
+			continue
+		}
+		if !variableKeyRe.MatchString(pv.Key) {
+			v.errMsg = fmt.Sprintf("Invalid variable name %q: use letters, digits and _", pv.Key)
+			return v, nil
+		}
+		vars = append(vars, pv)
+	}
+
+	v.errMsg = ""
+	v.active = false
+	return v, func() tea.Msg {
+		return PipelineCreateSubmitMsg{ProjectPath: project, Ref: ref, Variables: vars}
+	}
+}
+
+func (v PipelineCreateView) View() string {
+	s := "\n"
+	s += styles.HelpKey.Render("  Run Pipeline") + "\n\n"
+
+	row := func(i int, label string) string {
+		cursor := "  "
+		value := *v.field(i)
+		if i == v.cursor {
+			cursor = "▸ "
+			value += "█"
+		}
+		return fmt.Sprintf("%s%s %s", cursor, styles.HelpKey.Render(fmt.Sprintf("%-16s", label)), value) + "\n"
+	}
+
+	s += row(plFieldProject, "Project")
+	s += v.suggestionsView(plFieldProject)
+	s += row(plFieldRef, "Ref")
+	s += v.suggestionsView(plFieldRef)
+
+	s += "\n" + styles.HelpDesc.Render("  Variables") + "\n"
+	for r := range v.vars {
+		key := plFieldVars + 2*r
+		s += row(key, "  Key")
+		s += row(key+1, "  Value")
+	}
+
+	if v.errMsg != "" {
+		s += "\n" + styles.StatusFailed.Render("  "+v.errMsg) + "\n"
+	}
+
+	s += "\n" + styles.HelpDesc.Render("  Tab/↑↓ navigate  Enter select/next  Ctrl+N add variable  Ctrl+X remove variable  Ctrl+S run  Esc cancel") + "\n"
+	return s
+}
+
+// suggestionsView lists suggestions below the active field.
+func (v PipelineCreateView) suggestionsView(field int) string {
+	if field != v.cursor {
+		return ""
+	}
+	var s string
+	for j, sg := range v.currentSuggestions() {
+		sc := "   "
+		style := styles.HelpDesc
+		if j == v.sugCursor {
+			sc = " ▸ "
+			style = styles.Selected
+		}
+		s += style.Render(fmt.Sprintf("  %s%s", sc, sg)) + "\n"
+	}
+	return s
+}
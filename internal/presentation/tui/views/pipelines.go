@@ -22,6 +22,7 @@ type PipelinesView struct {
 	Filter        string
 	filtering     bool
 	LoadingStatus string
+	marked        map[int]bool // pipeline IDs selected for a bulk action
 }
 
 func NewPipelinesView() PipelinesView { return PipelinesView{height: 20} }
@@ -30,6 +31,53 @@ func (v PipelinesView) IsInputMode() bool { return v.filtering }
 
 type PipelineSelectedMsg struct{ Pipeline entity.Pipeline }
 type PipelineLimitCycleMsg struct{}
+type PipelineActionMsg struct {
+	Action   string // "retry" or "cancel"
+	Pipeline entity.Pipeline
+}
+
+// PipelineBulkActionMsg applies "retry" or "cancel" to all marked pipelines.
+type PipelineBulkActionMsg struct {
+	Action    string
+	Pipelines []entity.Pipeline
+}
+
+// Marked returns the marked pipelines that are still in the list.
+func (v PipelinesView) Marked() []entity.Pipeline {
+	var pls []entity.Pipeline
+	for _, pl := range v.Pipelines {
+		if v.marked[pl.ID] {
+			pls = append(pls, pl)
+		}
+	}
+	return pls
+}
+
+func (v *PipelinesView) ClearMarks() { v.marked = nil }
+
+// markWhere marks every visible pipeline matching fn, or unmarks them all
+// if they already are.
+func (v *PipelinesView) markWhere(fn func(entity.Pipeline) bool) {
+	all := true
+	for _, pl := range v.filtered {
+		if fn(pl) && !v.marked[pl.ID] {
+			all = false
+			break
+		}
+	}
+	if v.marked == nil {
+		v.marked = make(map[int]bool)
+	}
+	for _, pl := range v.filtered {
+		if fn(pl) {
+			if all {
+				delete(v.marked, pl.ID)
+			} else {
+				v.marked[pl.ID] = true
+			}
+		}
+	}
+}
 
 func (v *PipelinesView) SetHeight(h int) {
 	// subtract header lines (filter + padding + statusbar)
@@ -127,16 +175,64 @@ func (v PipelinesView) Update(msg tea.Msg) (PipelinesView, tea.Cmd) {
 			v.applyFilter()
 		case "l":
 			return v, func() tea.Msg { return PipelineLimitCycleMsg{} }
+		case " ":
+			if sel, ok := v.Selected(); ok {
+				v.markWhere(func(pl entity.Pipeline) bool { return pl.ID == sel.ID })
+				if v.Cursor < len(v.filtered)-1 {
+					v.Cursor++
+					v.ensureVisible()
+				}
+			}
+		case "s":
+			if sel, ok := v.Selected(); ok {
+				v.markWhere(func(pl entity.Pipeline) bool {
+					return pl.ProjectID == sel.ProjectID && pl.Ref == sel.Ref
+				})
+			}
+		case "a":
+			v.markWhere(func(entity.Pipeline) bool { return true })
+		case "u":
+			v.ClearMarks()
+		case "r":
+			if marked := v.Marked(); len(marked) > 0 {
+				return v, func() tea.Msg { return PipelineBulkActionMsg{Action: "retry", Pipelines: marked} }
+			}
+			if pl, ok := v.Selected(); ok && pl.Status.CanRetry() {
+				return v, func() tea.Msg { return PipelineActionMsg{Action: "retry", Pipeline: pl} }
+			}
+		case "x":
+			if marked := v.Marked(); len(marked) > 0 {
+				return v, func() tea.Msg { return PipelineBulkActionMsg{Action: "cancel", Pipelines: marked} }
+			}
+			if pl, ok := v.Selected(); ok && pl.Status.CanCancel() {
+				return v, func() tea.Msg { return PipelineActionMsg{Action: "cancel", Pipeline: pl} }
+			}
 		}
 	}
 	return v, nil
 }
 
+// Selected returns the pipeline under the cursor, honouring the filter.
+func (v PipelinesView) Selected() (entity.Pipeline, bool) {
+	if v.Cursor < len(v.filtered) {
+		return v.filtered[v.Cursor], true
+	}
+	return entity.Pipeline{}, false
+}
+
 func (v *PipelinesView) SetPipelines(pls []entity.Pipeline) {
 	v.Pipelines = pls
 	v.applyFilter()
 }
 
+// markGlyph is the column showing whether a row is marked for a bulk action.
+func markGlyph(marked bool) string {
+	if marked {
+		return styles.Selected.Render("◆") + " "
+	}
+	return "  "
+}
+
 func statusStyle(status valueobject.PipelineStatus) lipgloss.Style {
 	switch status {
 	case valueobject.PipelineSuccess:
@@ -191,6 +287,7 @@ func (v PipelinesView) View() string {
 		if idx == v.Cursor {
 			cursor = "▸ "
 		}
+		cursor += markGlyph(v.marked[pl.ID])
 		st := statusStyle(pl.Status)
 		symbol := st.Render(pl.Status.Symbol())
 		status := st.Render(string(pl.Status))
@@ -222,7 +319,12 @@ func (v PipelinesView) View() string {
 		if v.Limit > 0 {
 			info += fmt.Sprintf("  limit:%d", v.Limit)
 		}
-		s += "\n" + styles.HelpDesc.Render(info) + "\n"
+		s += "\n" + styles.HelpDesc.Render(info)
+		if n := len(v.Marked()); n > 0 {
+			s += styles.Selected.Render(fmt.Sprintf("  ◆ %d marked", n)) +
+				styles.HelpDesc.Render("  r retry · x cancel · u clear")
+		}
+		s += "\n"
 	}
 
 	return s